@@ -0,0 +1,39 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultCommonName     = "common"
+	defaultCommonPkgAlias = "common"
+	defaultTelemetryName  = "telemetry"
+	defaultSlowLogName    = "slowlog"
+	defaultMemoryName     = "memory"
+	defaultFixtureName    = "fixture"
+)
+
+type common struct {
+	opts          *options
+	daoPkgPath    string
+	daoPkgName    string
+	daoRootPath   string
+	daoOutputDir  string
+	daoOutputFile string
+}
+
+func newCommon(opts *options) *common {
+	c := &common{}
+	c.opts = opts
+	c.daoOutputDir = strings.TrimSuffix(opts.daoDir, "/")
+	c.daoOutputFile = defaultCommonName + ".go"
+
+	return c
+}
+
+func (c *common) setDaoPkgPath(path string) {
+	c.daoRootPath = path
+	c.daoPkgPath = path + "/internal"
+	c.daoPkgName = toPackageName(filepath.Base(path))
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -17,6 +18,7 @@ type counter struct {
 	daoOutputFile   string
 	daoPrefixName   string
 	collectionName  string
+	commonPkgPath   string // the shared internal package imported by the counter of the sub package
 }
 
 func newCounter(opts *options) *counter {
@@ -43,9 +45,39 @@ func newCounter(opts *options) *counter {
 func (c *counter) setDaoPkgPath(path string) {
 	if c.opts.subPkgEnable {
 		c.daoPkgPath = path + "/" + toPackagePath(c.modelName, c.opts.subPkgStyle)
+		c.commonPkgPath = path + "/internal"
 	} else {
 		c.daoPkgPath = path
 	}
 
 	c.daoPkgName = toPackageName(filepath.Base(c.daoPkgPath))
 }
+
+func (c *counter) commonPrefix() string {
+	if c.commonPkgPath == "" {
+		return ""
+	}
+
+	return defaultCommonPkgAlias + "."
+}
+
+func (c *counter) packages() (str string) {
+	packages := []string{pkg2, pkg6, pkg7, pkg4, pkg5}
+	if c.commonPkgPath != "" {
+		packages = append(packages, c.commonPkgPath)
+	}
+
+	sort.Strings(packages)
+
+	for _, pkg := range packages {
+		if pkg == c.commonPkgPath {
+			str += fmt.Sprintf("\t%s \"%s\"\n", defaultCommonPkgAlias, pkg)
+		} else {
+			str += fmt.Sprintf("\t\"%s\"\n", pkg)
+		}
+	}
+
+	str = strings.TrimPrefix(str, "\t")
+	str = strings.TrimSuffix(str, "\n")
+	return
+}
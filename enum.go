@@ -0,0 +1,309 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	directiveEnum   = "enum"
+	enumCodecString = "string"
+	enumOptOut      = "-"
+	pkgBSONType     = "go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+type enumConst struct {
+	name    string
+	value   constant.Value
+	comment string
+	pos     token.Pos
+}
+
+// a named type of the model package with the constants declared for it
+type enum struct {
+	obj     *types.TypeName
+	consts  []*enumConst
+	codec   string
+	pkgName string
+	file    string
+	fset    *token.FileSet
+	models  []string // the models whose fields reference the enum
+}
+
+type enums map[*types.TypeName]*enum
+
+// collect the named types of the model package with the constants declared for them in the order of declaration
+// The types marked by the //gen:enum - directive are not enums, even when constants are declared for them.
+func parseEnums(pkg *packages.Package, opts *options) enums {
+	items := make(enums)
+	codecs := make(map[string]string)
+
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+
+			switch gen.Tok {
+			case token.TYPE:
+				for _, s := range gen.Specs {
+					spec := s.(*ast.TypeSpec)
+					if codec, ok := parseDirectives(gen.Doc, spec.Doc)[directiveEnum]; ok {
+						codecs[spec.Name.Name] = codec
+					}
+				}
+			case token.CONST:
+				for _, s := range gen.Specs {
+					spec := s.(*ast.ValueSpec)
+					for _, name := range spec.Names {
+						c, ok := pkg.TypesInfo.Defs[name].(*types.Const)
+						if !ok {
+							continue
+						}
+
+						named, ok := c.Type().(*types.Named)
+						if !ok || named.Obj().Pkg() != pkg.Types {
+							continue
+						}
+
+						item, ok := items[named.Obj()]
+						if !ok {
+							item = &enum{
+								obj:     named.Obj(),
+								pkgName: pkg.Types.Name(),
+								fset:    pkg.Fset,
+								file:    filepath.Join(opts.modelDir, toFileName(named.Obj().Name()+"Enum", opts.fileNameStyle)+".go"),
+							}
+							items[named.Obj()] = item
+						}
+
+						item.add(c, spec.Comment)
+					}
+				}
+			}
+		}
+	}
+
+	for obj, item := range items {
+		if codecs[obj.Name()] == enumOptOut || len(item.consts) == 0 {
+			delete(items, obj)
+			continue
+		}
+
+		sort.SliceStable(item.consts, func(i, j int) bool {
+			return item.consts[i].pos < item.consts[j].pos
+		})
+
+		if codec := codecs[obj.Name()]; codec == enumCodecString && item.isInteger() {
+			item.codec = codec
+		}
+	}
+
+	return items
+}
+
+// record the model referencing the enum by its fields
+func (e *enum) use(modelName string) {
+	if !contains(e.models, modelName) {
+		e.models = append(e.models, modelName)
+	}
+}
+
+// add the constant unless another constant with the same value has been added
+func (e *enum) add(c *types.Const, comment *ast.CommentGroup) {
+	for _, item := range e.consts {
+		if constant.Compare(item.value, token.EQL, c.Val()) {
+			return
+		}
+	}
+
+	text := c.Name()
+	if comment != nil {
+		if s := strings.TrimSpace(comment.Text()); s != "" {
+			text = s
+		}
+	}
+
+	e.consts = append(e.consts, &enumConst{name: c.Name(), value: c.Val(), comment: text, pos: c.Pos()})
+}
+
+func (e *enum) isInteger() bool {
+	b, ok := e.obj.Type().Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsInteger != 0
+}
+
+// the name of the constant stored by the string codec, example: GenderMale => male
+func (e *enum) codecName(c *enumConst) string {
+	name := strings.TrimPrefix(c.name, e.obj.Name())
+	if name == "" {
+		name = c.name
+	}
+
+	return toUnderscoreCase(name)
+}
+
+// the literals of the values used in the json schema
+func (e *enum) schemaValues() []string {
+	values := make([]string, 0, len(e.consts))
+	for _, c := range e.consts {
+		if e.codec == enumCodecString {
+			values = append(values, fmt.Sprintf("%q", e.codecName(c)))
+		} else {
+			values = append(values, c.value.ExactString())
+		}
+	}
+
+	return values
+}
+
+// check whether the method is declared by the user instead of the generated enum file
+func (e *enum) declares(method string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(e.obj.Type()), false, e.obj.Pkg(), method)
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+
+	return filepath.Base(e.fset.Position(fn.Pos()).Filename) != filepath.Base(e.file)
+}
+
+// the expression of the values of the enum used by the dao package, example: modelpkg.Gender(0).Values()
+func (e *enum) valuesExpr(typeName string) string {
+	value := "0"
+	if isString(e.obj.Type()) {
+		value = `""`
+	}
+
+	return fmt.Sprintf("%s(%s).Values()", typeName, value)
+}
+
+// resolve the enum of the field type
+func (items enums) lookup(typ types.Type) *enum {
+	if named, ok := typ.(*types.Named); ok {
+		return items[named.Obj()]
+	}
+
+	return nil
+}
+
+// the body of the generated enum file
+func (e *enum) methods() (str string) {
+	var (
+		name     = e.obj.Name()
+		values   = make([]string, 0, len(e.consts))
+		receiver = strings.ToLower(name[:1])
+	)
+
+	for _, c := range e.consts {
+		values = append(values, c.name)
+	}
+
+	if !e.declares("Values") {
+		str += fmt.Sprintf("// Values returns the values of the constants declared for %s.\n", name)
+		str += fmt.Sprintf("func (%s) Values() []%s {\n", name, name)
+		str += fmt.Sprintf("\treturn []%s{%s}\n", name, strings.Join(values, ", "))
+		str += "}\n\n"
+	}
+
+	if !e.declares("IsValid") {
+		str += fmt.Sprintf("// IsValid reports whether the value is one of the constants declared for %s.\n", name)
+		str += fmt.Sprintf("func (%s %s) IsValid() bool {\n", receiver, name)
+		str += fmt.Sprintf("\tswitch %s {\n", receiver)
+		str += fmt.Sprintf("\tcase %s:\n", strings.Join(values, ", "))
+		str += "\t\treturn true\n"
+		str += "\tdefault:\n"
+		str += "\t\treturn false\n"
+		str += "\t}\n"
+		str += "}\n\n"
+	}
+
+	if !e.declares("String") {
+		str += "// String returns the description of the value from the comment of the constant.\n"
+		str += fmt.Sprintf("func (%s %s) String() string {\n", receiver, name)
+		str += fmt.Sprintf("\tswitch %s {\n", receiver)
+		for _, c := range e.consts {
+			str += fmt.Sprintf("\tcase %s:\n", c.name)
+			str += fmt.Sprintf("\t\treturn %q\n", c.comment)
+		}
+		str += "\tdefault:\n"
+		str += fmt.Sprintf("\t\treturn fmt.Sprintf(\"%s(%%v)\", %s(%s))\n", name, e.obj.Type().Underlying().String(), receiver)
+		str += "\t}\n"
+		str += "}\n\n"
+	}
+
+	if e.codec == enumCodecString {
+		if !e.declares("MarshalBSONValue") {
+			str += "// MarshalBSONValue stores the value as the name of the constant.\n"
+			str += fmt.Sprintf("func (%s %s) MarshalBSONValue() (bsontype.Type, []byte, error) {\n", receiver, name)
+			str += fmt.Sprintf("\tswitch %s {\n", receiver)
+			for _, c := range e.consts {
+				str += fmt.Sprintf("\tcase %s:\n", c.name)
+				str += fmt.Sprintf("\t\treturn bson.MarshalValue(%q)\n", e.codecName(c))
+			}
+			str += "\tdefault:\n"
+			str += fmt.Sprintf("\t\treturn 0, nil, fmt.Errorf(\"invalid %s value %%v\", %s(%s))\n", name, e.obj.Type().Underlying().String(), receiver)
+			str += "\t}\n"
+			str += "}\n\n"
+		}
+
+		if !e.declares("UnmarshalBSONValue") {
+			str += "// UnmarshalBSONValue restores the value from the name of the constant.\n"
+			str += fmt.Sprintf("func (%s *%s) UnmarshalBSONValue(typ bsontype.Type, data []byte) error {\n", receiver, name)
+			str += "\tname, ok := bson.RawValue{Type: typ, Value: data}.StringValueOK()\n"
+			str += "\tif !ok {\n"
+			str += fmt.Sprintf("\t\treturn fmt.Errorf(\"invalid %s bson type %%s\", typ)\n", name)
+			str += "\t}\n\n"
+			str += "\tswitch name {\n"
+			for _, c := range e.consts {
+				str += fmt.Sprintf("\tcase %q:\n", e.codecName(c))
+				str += fmt.Sprintf("\t\t*%s = %s\n", receiver, c.name)
+			}
+			str += "\tdefault:\n"
+			str += fmt.Sprintf("\t\treturn fmt.Errorf(\"invalid %s name %%q\", name)\n", name)
+			str += "\t}\n\n"
+			str += "\treturn nil\n"
+			str += "}\n\n"
+		}
+	}
+
+	str = strings.TrimSuffix(str, "\n\n")
+	return
+}
+
+// the packages imported by the generated enum file, empty if there is nothing to import
+func (e *enum) packages(methods string) string {
+	imports := make([]string, 0, 3)
+
+	if strings.Contains(methods, "fmt.") {
+		imports = append(imports, pkg8)
+	}
+
+	if strings.Contains(methods, "bson.") {
+		imports = append(imports, pkg7)
+	}
+
+	if strings.Contains(methods, "bsontype.") {
+		imports = append(imports, pkgBSONType)
+	}
+
+	if len(imports) == 0 {
+		return ""
+	}
+
+	str := "import (\n"
+	for _, pkg := range imports {
+		str += fmt.Sprintf("\t%q\n", pkg)
+	}
+	str += ")\n"
+
+	return str
+}
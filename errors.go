@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// map the columns to the field names of the model, used to report the keys of the duplicate key errors
+func (m *model) modelFieldNames() (str string) {
+	for i, f := range m.fields {
+		str += fmt.Sprintf("\t%q:%s%q,", f.column, strings.Repeat(" ", m.fieldColumnMaxLen()-len(f.column)+1), f.name)
+		if i != len(m.fields)-1 {
+			str += "\n"
+		}
+	}
+
+	str = strings.TrimPrefix(str, "\t")
+	return
+}
+
+func (m *model) fieldColumnMaxLen() (n int) {
+	for _, f := range m.fields {
+		if len(f.column) > n {
+			n = len(f.column)
+		}
+	}
+
+	return
+}
+
+// the error returned by the find one methods when no document matches
+func (m *model) notFoundCode() string {
+	if !m.opts.notFoundError {
+		return "return nil"
+	}
+
+	return fmt.Sprintf("return %sErrNotFound", m.commonPrefix)
+}
+
+func (m *model) findOneNotFoundDoc() string {
+	if !m.opts.notFoundError {
+		return "// A nil model and a nil error are returned when no document matches."
+	}
+
+	return "// ErrNotFound is returned when no document matches."
+}
@@ -0,0 +1,80 @@
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+package dao
+
+import (
+	"context"
+	"github.com/dobyte/mongo-dao-generator/example/dao/internal"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrEmptyUpdate       = internal.ErrEmptyUpdate
+	ErrVersionConflict   = internal.ErrVersionConflict
+	ErrVersionUpsert     = internal.ErrVersionUpsert
+	ErrNotFound          = internal.ErrNotFound
+	ErrDuplicateKey      = internal.ErrDuplicateKey
+	ErrInvalidID         = internal.ErrInvalidID
+	ErrValidation        = internal.ErrValidation
+	ErrMemoryUnsupported = internal.ErrMemoryUnsupported
+)
+
+// WithTx runs the function in a transaction, the txCtx must be passed to the dao methods to join the transaction.
+func WithTx(ctx context.Context, client *mongo.Client, fn func(txCtx context.Context) error, opts ...*options.TransactionOptions) error {
+	return internal.WithTx(ctx, client, fn, opts...)
+}
+
+// Use appends the interceptors to the chain shared by all daos, the first interceptor is the outermost one.
+func Use(interceptors ...Interceptor) {
+	internal.Use(interceptors...)
+}
+
+type (
+	FieldError        = internal.FieldError
+	ValidationError   = internal.ValidationError
+	DuplicateKeyError = internal.DuplicateKeyError
+	ValidationLevel   = internal.ValidationLevel
+	ValidationAction  = internal.ValidationAction
+	IndexSpec         = internal.IndexSpec
+	IndexView         = internal.IndexView
+	IndexChange       = internal.IndexChange
+	IndexDiff         = internal.IndexDiff
+	Sort              = internal.Sort
+	ResumeTokenStore  = internal.ResumeTokenStore
+	UpdateDescription = internal.UpdateDescription
+	OpKind            = internal.OpKind
+	OpInfo            = internal.OpInfo
+	Handler           = internal.Handler
+	Interceptor       = internal.Interceptor
+	SlowQueryOptions  = internal.SlowQueryOptions
+)
+
+const (
+	ValidationLevelOff      = internal.ValidationLevelOff
+	ValidationLevelStrict   = internal.ValidationLevelStrict
+	ValidationLevelModerate = internal.ValidationLevelModerate
+	ValidationActionError   = internal.ValidationActionError
+	ValidationActionWarn    = internal.ValidationActionWarn
+	OpCount                 = internal.OpCount
+	OpEstimatedCount        = internal.OpEstimatedCount
+	OpExists                = internal.OpExists
+	OpDistinct              = internal.OpDistinct
+	OpAggregate             = internal.OpAggregate
+	OpWatch                 = internal.OpWatch
+	OpInsertOne             = internal.OpInsertOne
+	OpInsertMany            = internal.OpInsertMany
+	OpUpdateOne             = internal.OpUpdateOne
+	OpUpdateMany            = internal.OpUpdateMany
+	OpReplaceOne            = internal.OpReplaceOne
+	OpFindOne               = internal.OpFindOne
+	OpFindMany              = internal.OpFindMany
+	OpDeleteOne             = internal.OpDeleteOne
+	OpDeleteMany            = internal.OpDeleteMany
+	OpRestore               = internal.OpRestore
+	OpIncr                  = internal.OpIncr
+)
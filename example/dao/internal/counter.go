@@ -59,18 +59,30 @@ func (dao *Counter) Incr(ctx context.Context, key string, incr ...int) (int64, e
 		value = incr[0]
 	}
 
-	rst := dao.Collection.FindOneAndUpdate(ctx, bson.M{
-		dao.Columns.ID: key,
-	}, bson.M{"$inc": bson.M{
-		dao.Columns.Value: value,
-	}}, &options.FindOneAndUpdateOptions{
-		Upsert:         &upsert,
-		ReturnDocument: &returnDocument,
-	})
+	// the counter is excluded from the transaction of the ctx, the counter document is shared by all writers
+	// and would serialize the concurrent transactions, so the values consumed by aborted transactions are skipped
+	if mongo.SessionFromContext(ctx) != nil {
+		ctx = mongo.NewSessionContext(ctx, nil)
+	}
 
-	if err := rst.Decode(counter); err != nil {
-		return 0, err
+	op := OpInfo{
+		Database:   dao.Database.Name(),
+		Collection: dao.Collection.Name(),
+		Operation:  OpIncr,
+		Filter:     bson.M{dao.Columns.ID: key},
+		Update:     bson.M{"$inc": bson.M{dao.Columns.Value: value}},
 	}
 
-	return counter.Value, nil
+	return Invoke(ctx, op, func(ctx context.Context, op OpInfo) (int64, error) {
+		rst := dao.Collection.FindOneAndUpdate(ctx, op.Filter, op.Update, &options.FindOneAndUpdateOptions{
+			Upsert:         &upsert,
+			ReturnDocument: &returnDocument,
+		})
+
+		if err := rst.Decode(counter); err != nil {
+			return 0, err
+		}
+
+		return counter.Value, nil
+	})
 }
@@ -9,86 +9,469 @@ package internal
 import (
 	"context"
 	"errors"
+	"fmt"
 	modelpkg "github.com/dobyte/mongo-dao-generator/example/model"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"time"
+	"unicode/utf8"
 )
 
 type MailFilterFunc func(cols *MailColumns) interface{}
 type MailUpdateFunc func(cols *MailColumns) interface{}
 type MailPipelineFunc func(cols *MailColumns) interface{}
+type MailColumnFunc func(cols *MailColumns) string
 type MailCountOptionsFunc func(cols *MailColumns) *options.CountOptions
+type MailEstimatedCountOptionsFunc func(cols *MailColumns) *options.EstimatedDocumentCountOptions
+type MailDistinctOptionsFunc func(cols *MailColumns) *options.DistinctOptions
 type MailAggregateOptionsFunc func(cols *MailColumns) *options.AggregateOptions
 type MailFindOneOptionsFunc func(cols *MailColumns) *options.FindOneOptions
 type MailFindManyOptionsFunc func(cols *MailColumns) *options.FindOptions
 type MailUpdateOptionsFunc func(cols *MailColumns) *options.UpdateOptions
+type MailReplaceOptionsFunc func(cols *MailColumns) *options.ReplaceOptions
 type MailDeleteOptionsFunc func(cols *MailColumns) *options.DeleteOptions
 type MailInsertOneOptionsFunc func(cols *MailColumns) *options.InsertOneOptions
 type MailInsertManyOptionsFunc func(cols *MailColumns) *options.InsertManyOptions
+type MailWatchOptionsFunc func(cols *MailColumns) *options.ChangeStreamOptions
+
+// MailChangeEvent is a change event of the collection with the full document decoded into the model.
+type MailChangeEvent = ChangeEvent[modelpkg.Mail]
+
+// MailChangeStream iterates over the change events of the collection.
+type MailChangeStream = ChangeStream[modelpkg.Mail]
 
 type Mail struct {
 	Columns    *MailColumns
+	Sort       *MailSort
 	Database   *mongo.Database
 	Collection *mongo.Collection
+	IndexView  IndexView // nil means the index view of the collection
+	scope      Scope
+}
+
+// MailRepository lists the methods of the dao, the services depending on it can be tested without a database.
+// The methods returning a scoped copy of the dao and the generic find functions are not listed.
+type MailRepository interface {
+	Where(filterFunc func(f *MailFilter) bson.D) MailFilterFunc
+	Update(updateFunc func(u *MailUpdate)) MailUpdateFunc
+	Validate(model *modelpkg.Mail) error
+	Indexes() []IndexSpec
+	EnsureIndexes(ctx context.Context) ([]string, error)
+	DiffIndexes(ctx context.Context) (*IndexDiff, error)
+	SyncIndexes(ctx context.Context, dropExtra bool) (*IndexDiff, error)
+	Schema() bson.D
+	ApplyValidator(ctx context.Context, level ValidationLevel, action ValidationAction) error
+	Count(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailCountOptionsFunc) (int64, error)
+	EstimatedCount(ctx context.Context, optionsFunc ...MailEstimatedCountOptionsFunc) (int64, error)
+	Exists(ctx context.Context, filterFunc MailFilterFunc) (bool, error)
+	Aggregate(ctx context.Context, pipelineFunc MailPipelineFunc, optionsFunc ...MailAggregateOptionsFunc) (*mongo.Cursor, error)
+	Watch(ctx context.Context, pipelineFunc MailPipelineFunc, optionsFunc ...MailWatchOptionsFunc) (*MailChangeStream, error)
+	ResumeWatch(ctx context.Context, store ResumeTokenStore, pipelineFunc MailPipelineFunc, optionsFunc ...MailWatchOptionsFunc) (*MailChangeStream, error)
+	Distinct(ctx context.Context, columnFunc MailColumnFunc, filterFunc MailFilterFunc, optionsFunc ...MailDistinctOptionsFunc) ([]interface{}, error)
+	DistinctStrings(ctx context.Context, columnFunc MailColumnFunc, filterFunc MailFilterFunc, optionsFunc ...MailDistinctOptionsFunc) ([]string, error)
+	DistinctInt64s(ctx context.Context, columnFunc MailColumnFunc, filterFunc MailFilterFunc, optionsFunc ...MailDistinctOptionsFunc) ([]int64, error)
+	InsertOne(ctx context.Context, model *modelpkg.Mail, optionsFunc ...MailInsertOneOptionsFunc) (*mongo.InsertOneResult, error)
+	InsertMany(ctx context.Context, models []*modelpkg.Mail, optionsFunc ...MailInsertManyOptionsFunc) (*mongo.InsertManyResult, error)
+	UpdateOne(ctx context.Context, filterFunc MailFilterFunc, updateFunc MailUpdateFunc, optionsFunc ...MailUpdateOptionsFunc) (*mongo.UpdateResult, error)
+	UpdateOneByID(ctx context.Context, id string, updateFunc MailUpdateFunc, optionsFunc ...MailUpdateOptionsFunc) (*mongo.UpdateResult, error)
+	UpdateMany(ctx context.Context, filterFunc MailFilterFunc, updateFunc MailUpdateFunc, optionsFunc ...MailUpdateOptionsFunc) (*mongo.UpdateResult, error)
+	ReplaceOne(ctx context.Context, filterFunc MailFilterFunc, model *modelpkg.Mail, optionsFunc ...MailReplaceOptionsFunc) (*mongo.UpdateResult, error)
+	FindOne(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailFindOneOptionsFunc) (*modelpkg.Mail, error)
+	FindOneByID(ctx context.Context, id string, optionsFunc ...MailFindOneOptionsFunc) (*modelpkg.Mail, error)
+	FindMany(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailFindManyOptionsFunc) ([]*modelpkg.Mail, error)
+	FindManyByIDs(ctx context.Context, ids []string, optionsFunc ...MailFindManyOptionsFunc) ([]*modelpkg.Mail, error)
+	DeleteOne(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailDeleteOptionsFunc) (*mongo.DeleteResult, error)
+	DeleteOneByID(ctx context.Context, id string, optionsFunc ...MailDeleteOptionsFunc) (*mongo.DeleteResult, error)
+	DeleteMany(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailDeleteOptionsFunc) (*mongo.DeleteResult, error)
+	Restore(ctx context.Context, filterFunc MailFilterFunc) (*mongo.UpdateResult, error)
+	ForceDelete(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailDeleteOptionsFunc) (*mongo.DeleteResult, error)
 }
 
+var _ MailRepository = (*Mail)(nil)
+
 type MailColumns struct {
-	ID       string // 邮件ID
-	Title    string // 邮件标题
-	Content  string // 邮件内容
-	Sender   string // 邮件发送者
-	Receiver string // 邮件接受者
-	Status   string // 邮件状态
-	SendTime string // 发送时间
+	ID        string // 邮件ID
+	Title     string // 邮件标题
+	Content   string // 邮件内容
+	Sender    string // 邮件发送者
+	Receiver  string // 邮件接受者
+	Status    string // 邮件状态
+	SendTime  string // 发送时间
+	DeletedAt string // 删除时间
 }
 
 var mailColumns = &MailColumns{
-	ID:       "_id",       // 邮件ID
-	Title:    "title",     // 邮件标题
-	Content:  "content",   // 邮件内容
-	Sender:   "sender",    // 邮件发送者
-	Receiver: "receiver",  // 邮件接受者
-	Status:   "status",    // 邮件状态
-	SendTime: "send_time", // 发送时间
+	ID:        "_id",        // 邮件ID
+	Title:     "title",      // 邮件标题
+	Content:   "content",    // 邮件内容
+	Sender:    "sender",     // 邮件发送者
+	Receiver:  "receiver",   // 邮件接受者
+	Status:    "status",     // 邮件状态
+	SendTime:  "send_time",  // 发送时间
+	DeletedAt: "deleted_at", // 删除时间
+}
+
+// MailFilter provides the typed filter conditions for each column of the collection.
+type MailFilter struct {
+	FilterBuilder
+	ID        OrderedField[primitive.ObjectID]
+	Title     StringField[string]
+	Content   StringField[string]
+	Sender    OrderedField[int64]
+	Receiver  OrderedField[int64]
+	Status    OrderedField[int]
+	SendTime  OrderedField[primitive.DateTime]
+	DeletedAt OrderedField[primitive.DateTime]
+}
+
+var mailFilter = &MailFilter{
+	ID:        NewOrderedField[primitive.ObjectID]("_id"),
+	Title:     NewStringField[string]("title"),
+	Content:   NewStringField[string]("content"),
+	Sender:    NewOrderedField[int64]("sender"),
+	Receiver:  NewOrderedField[int64]("receiver"),
+	Status:    NewOrderedField[int]("status"),
+	SendTime:  NewOrderedField[primitive.DateTime]("send_time"),
+	DeletedAt: NewOrderedField[primitive.DateTime]("deleted_at"),
+}
+
+// MailSort provides the typed sort keys for each column of the collection.
+type MailSort struct {
+	ID        SortField
+	Title     SortField
+	Content   SortField
+	Sender    SortField
+	Receiver  SortField
+	Status    SortField
+	SendTime  SortField
+	DeletedAt SortField
+}
+
+var mailSort = &MailSort{
+	ID:        NewSortField("_id"),
+	Title:     NewSortField("title"),
+	Content:   NewSortField("content"),
+	Sender:    NewSortField("sender"),
+	Receiver:  NewSortField("receiver"),
+	Status:    NewSortField("status"),
+	SendTime:  NewSortField("send_time"),
+	DeletedAt: NewSortField("deleted_at"),
+}
+
+// mailDefaultSort is applied by FindMany when the options do not specify a sort.
+var mailDefaultSort = Sort{{Key: "send_time", Value: -1}}
+
+// mailIndexes are declared by the gen tags of the model.
+var mailIndexes = []IndexSpec{
+	{
+		Name: "idx_recv_status",
+		Keys: bson.D{{Key: "receiver", Value: 1}, {Key: "status", Value: 1}},
+	},
+}
+
+// mailFieldNames maps the columns to the field names of the model to report the keys of the duplicate key errors.
+var mailFieldNames = map[string]string{
+	"_id":        "ID",
+	"title":      "Title",
+	"content":    "Content",
+	"sender":     "Sender",
+	"receiver":   "Receiver",
+	"status":     "Status",
+	"send_time":  "SendTime",
+	"deleted_at": "DeletedAt",
+}
+
+// mailSoftDelete is the column marking the soft deleted documents, nil means the documents are removed on delete.
+var mailSoftDelete = &SoftDelete{Column: "deleted_at", Zero: primitive.DateTime(0)}
+
+// mailSchema is the json schema derived from the fields of the model.
+var mailSchema = bson.D{
+	{Key: "bsonType", Value: "object"},
+	{Key: "required", Value: bson.A{"title"}},
+	{Key: "properties", Value: bson.D{
+		{Key: "_id", Value: bson.D{
+			{Key: "bsonType", Value: "objectId"},
+		}},
+		{Key: "title", Value: bson.D{
+			{Key: "bsonType", Value: "string"},
+			{Key: "minLength", Value: 1},
+			{Key: "maxLength", Value: 64},
+		}},
+		{Key: "content", Value: bson.D{
+			{Key: "bsonType", Value: "string"},
+		}},
+		{Key: "sender", Value: bson.D{
+			{Key: "bsonType", Value: "long"},
+		}},
+		{Key: "receiver", Value: bson.D{
+			{Key: "bsonType", Value: "long"},
+		}},
+		{Key: "status", Value: bson.D{
+			{Key: "bsonType", Value: bson.A{"int", "long"}},
+		}},
+		{Key: "send_time", Value: bson.D{
+			{Key: "bsonType", Value: "date"},
+		}},
+		{Key: "deleted_at", Value: bson.D{
+			{Key: "bsonType", Value: "date"},
+		}},
+	}},
+}
+
+// MailUpdate provides the typed update operators for each column of the collection.
+type MailUpdate struct {
+	updater   *Updater
+	Title     OrderedUpdateField[string]
+	Content   OrderedUpdateField[string]
+	Sender    NumberUpdateField[int64]
+	Receiver  NumberUpdateField[int64]
+	Status    NumberUpdateField[int]
+	SendTime  OrderedUpdateField[primitive.DateTime]
+	DeletedAt OrderedUpdateField[primitive.DateTime]
+}
+
+func newMailUpdate() *MailUpdate {
+	updater := NewUpdater()
+
+	return &MailUpdate{
+		updater:   updater,
+		Title:     NewOrderedUpdateField[string](updater, "title", validateMailTitle),
+		Content:   NewOrderedUpdateField[string](updater, "content"),
+		Sender:    NewNumberUpdateField[int64](updater, "sender"),
+		Receiver:  NewNumberUpdateField[int64](updater, "receiver"),
+		Status:    NewNumberUpdateField[int](updater, "status"),
+		SendTime:  NewOrderedUpdateField[primitive.DateTime](updater, "send_time"),
+		DeletedAt: NewOrderedUpdateField[primitive.DateTime](updater, "deleted_at"),
+	}
+}
+
+// validateMailTitle checks the value of the title column against the validation rules of the gen tag.
+func validateMailTitle(value string) *FieldError {
+	if value == "" {
+		return &FieldError{Field: "title", Rule: "required", Message: "is required"}
+	}
+
+	if n := utf8.RuneCountInString(value); n < 1 || n > 64 {
+		return &FieldError{Field: "title", Rule: "len", Message: "length must be between 1 and 64"}
+	}
+
+	return nil
 }
 
 func NewMail(db *mongo.Database) *Mail {
 	return &Mail{
 		Columns:    mailColumns,
+		Sort:       mailSort,
 		Database:   db,
 		Collection: db.Collection("mail"),
 	}
 }
 
+// Where returns a filter func that builds the filter with the typed filter conditions of the collection.
+func (dao *Mail) Where(filterFunc func(f *MailFilter) bson.D) MailFilterFunc {
+	return func(cols *MailColumns) interface{} {
+		return filterFunc(mailFilter)
+	}
+}
+
+// Update returns an update func that builds the update document with the typed update operators of the collection.
+// The update func returns the validation error instead of the document when a value violates the validation rules.
+func (dao *Mail) Update(updateFunc func(u *MailUpdate)) MailUpdateFunc {
+	return func(cols *MailColumns) interface{} {
+		u := newMailUpdate()
+		updateFunc(u)
+
+		if err := u.updater.Err(); err != nil {
+			return err
+		}
+
+		return u.updater.Document()
+	}
+}
+
+// Validate checks the model against the validation rules declared by the gen tags of the model.
+func (dao *Mail) Validate(model *modelpkg.Mail) error {
+	if model == nil {
+		return errors.New("model is nil")
+	}
+
+	return Validate(
+		validateMailTitle(model.Title),
+	)
+}
+
+// Indexes returns the specs of the indexes declared by the gen tags of the model.
+func (dao *Mail) Indexes() []IndexSpec {
+	return mailIndexes
+}
+
+// EnsureIndexes creates the indexes declared by the gen tags of the model and returns the names of the indexes.
+// Creating an index that already exists with the same specification has no effect.
+func (dao *Mail) EnsureIndexes(ctx context.Context) ([]string, error) {
+	indexes := dao.Indexes()
+	if len(indexes) == 0 {
+		return nil, nil
+	}
+
+	models := make([]mongo.IndexModel, 0, len(indexes))
+	for _, index := range indexes {
+		models = append(models, index.Model())
+	}
+
+	return dao.Collection.Indexes().CreateMany(ctx, models)
+}
+
+// DiffIndexes compares the indexes declared by the gen tags of the model with the indexes of the collection.
+func (dao *Mail) DiffIndexes(ctx context.Context) (*IndexDiff, error) {
+	return DiffIndexes(ctx, dao.indexView(), dao.Indexes())
+}
+
+// SyncIndexes makes the indexes of the collection match the indexes declared by the gen tags of the model.
+// The indexes that are not declared are only dropped when dropExtra is true.
+func (dao *Mail) SyncIndexes(ctx context.Context, dropExtra bool) (*IndexDiff, error) {
+	return SyncIndexes(ctx, dao.indexView(), dao.Indexes(), dropExtra)
+}
+
+// Schema returns the json schema derived from the fields and the gen tags of the model.
+func (dao *Mail) Schema() bson.D {
+	return mailSchema
+}
+
+// ApplyValidator sets the json schema of the model as the validator of the collection.
+// The collection is created with the validator if it does not exist.
+func (dao *Mail) ApplyValidator(ctx context.Context, level ValidationLevel, action ValidationAction) error {
+	return ApplyValidator(ctx, dao.Database, dao.Collection.Name(), dao.Schema(), level, action)
+}
+
 // Count returns the number of documents in the collection.
 func (dao *Mail) Count(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailCountOptionsFunc) (int64, error) {
 	var (
 		opts   *options.CountOptions
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 	)
 
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.CountDocuments(ctx, filter, opts)
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpCount, Filter: filter}, func(ctx context.Context, op OpInfo) (int64, error) {
+		return dao.Collection.CountDocuments(ctx, op.Filter, opts)
+	})
+}
+
+// EstimatedCount returns an estimate of the number of documents in the collection using collection metadata.
+func (dao *Mail) EstimatedCount(ctx context.Context, optionsFunc ...MailEstimatedCountOptionsFunc) (int64, error) {
+	var opts *options.EstimatedDocumentCountOptions
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpEstimatedCount}, func(ctx context.Context, op OpInfo) (int64, error) {
+		return dao.Collection.EstimatedDocumentCount(ctx, opts)
+	})
+}
+
+// Exists reports whether at least one document in the collection matches the filter.
+func (dao *Mail) Exists(ctx context.Context, filterFunc MailFilterFunc) (bool, error) {
+	var (
+		opts   = options.FindOne().SetProjection(bson.M{"_id": 1})
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpExists, Filter: filter}, func(ctx context.Context, op OpInfo) (bool, error) {
+		err := dao.Collection.FindOne(ctx, op.Filter, opts).Err()
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return false, nil
+			}
+			return false, err
+		}
+
+		return true, nil
+	})
 }
 
 // Aggregate executes an aggregate command against the collection and returns a cursor over the resulting documents.
 func (dao *Mail) Aggregate(ctx context.Context, pipelineFunc MailPipelineFunc, optionsFunc ...MailAggregateOptionsFunc) (*mongo.Cursor, error) {
 	var (
 		opts     *options.AggregateOptions
+		pipeline = dao.scopedPipeline(pipelineFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpAggregate, Pipeline: pipeline}, func(ctx context.Context, op OpInfo) (*mongo.Cursor, error) {
+		return dao.Collection.Aggregate(ctx, op.Pipeline, opts)
+	})
+}
+
+// Watch opens a change stream on the collection and returns a stream of the events decoded into the model.
+// A nil pipelineFunc watches all the changes of the collection.
+func (dao *Mail) Watch(ctx context.Context, pipelineFunc MailPipelineFunc, optionsFunc ...MailWatchOptionsFunc) (*MailChangeStream, error) {
+	return dao.ResumeWatch(ctx, nil, pipelineFunc, optionsFunc...)
+}
+
+// ResumeWatch opens a change stream on the collection which resumes after the resume token saved in the store,
+// and saves the resume token of each event into the store once the next event is requested.
+func (dao *Mail) ResumeWatch(ctx context.Context, store ResumeTokenStore, pipelineFunc MailPipelineFunc, optionsFunc ...MailWatchOptionsFunc) (*MailChangeStream, error) {
+	var (
+		opts     *options.ChangeStreamOptions
+		pipeline interface{}
+	)
+
+	if pipelineFunc != nil {
 		pipeline = pipelineFunc(dao.Columns)
+	}
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpWatch, Pipeline: pipeline}, func(ctx context.Context, op OpInfo) (*MailChangeStream, error) {
+		return Watch[modelpkg.Mail](ctx, dao.Collection, op.Pipeline, opts, store, dao.Columns)
+	})
+}
+
+// Distinct executes a distinct command to find the unique values for a specified column in the collection.
+func (dao *Mail) Distinct(ctx context.Context, columnFunc MailColumnFunc, filterFunc MailFilterFunc, optionsFunc ...MailDistinctOptionsFunc) ([]interface{}, error) {
+	var (
+		opts   *options.DistinctOptions
+		column = columnFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 	)
 
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.Aggregate(ctx, pipeline, opts)
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpDistinct, Filter: filter}, func(ctx context.Context, op OpInfo) ([]interface{}, error) {
+		return dao.Collection.Distinct(ctx, column, op.Filter, opts)
+	})
+}
+
+// DistinctStrings executes a distinct command and returns the unique values of a string column.
+func (dao *Mail) DistinctStrings(ctx context.Context, columnFunc MailColumnFunc, filterFunc MailFilterFunc, optionsFunc ...MailDistinctOptionsFunc) ([]string, error) {
+	values, err := dao.Distinct(ctx, columnFunc, filterFunc, optionsFunc...)
+	if err != nil {
+		return nil, err
+	}
+
+	return StringValues(values)
+}
+
+// DistinctInt64s executes a distinct command and returns the unique values of an integer column.
+func (dao *Mail) DistinctInt64s(ctx context.Context, columnFunc MailColumnFunc, filterFunc MailFilterFunc, optionsFunc ...MailDistinctOptionsFunc) ([]int64, error) {
+	values, err := dao.Distinct(ctx, columnFunc, filterFunc, optionsFunc...)
+	if err != nil {
+		return nil, err
+	}
+
+	return Int64Values(values)
 }
 
 // InsertOne executes an insert command to insert a single document into the collection.
@@ -97,7 +480,7 @@ func (dao *Mail) InsertOne(ctx context.Context, model *modelpkg.Mail, optionsFun
 		return nil, errors.New("model is nil")
 	}
 
-	if err := dao.autofill(ctx, model); err != nil {
+	if err := dao.beforeInsert(ctx, model); err != nil {
 		return nil, err
 	}
 
@@ -107,7 +490,28 @@ func (dao *Mail) InsertOne(ctx context.Context, model *modelpkg.Mail, optionsFun
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.InsertOne(ctx, model, opts)
+	result, err := Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpInsertOne, Document: model}, func(ctx context.Context, op OpInfo) (*mongo.InsertOneResult, error) {
+		if err := dao.autofill(ctx, model); err != nil {
+			return nil, err
+		}
+
+		if err := dao.Validate(model); err != nil {
+			return nil, err
+		}
+
+		result, err := dao.Collection.InsertOne(ctx, op.Document, opts)
+
+		return result, dao.mapError(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = dao.afterInsert(ctx, model); err != nil {
+		return result, err
+	}
+
+	return result, nil
 }
 
 // InsertMany executes an insert command to insert multiple documents into the collection.
@@ -117,11 +521,11 @@ func (dao *Mail) InsertMany(ctx context.Context, models []*modelpkg.Mail, option
 	}
 
 	documents := make([]interface{}, 0, len(models))
-	for i := range models {
-		model := models[i]
-		if err := dao.autofill(ctx, model); err != nil {
-			return nil, err
+	for i, model := range models {
+		if err := dao.beforeInsert(ctx, model); err != nil {
+			return nil, fmt.Errorf("models[%d]: %w", i, err)
 		}
+
 		documents = append(documents, model)
 	}
 
@@ -131,27 +535,61 @@ func (dao *Mail) InsertMany(ctx context.Context, models []*modelpkg.Mail, option
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.InsertMany(ctx, documents, opts)
+	result, err := Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpInsertMany, Documents: documents}, func(ctx context.Context, op OpInfo) (*mongo.InsertManyResult, error) {
+		for i, model := range models {
+			if err := dao.autofill(ctx, model); err != nil {
+				return nil, err
+			}
+
+			if err := dao.Validate(model); err != nil {
+				return nil, fmt.Errorf("models[%d]: %w", i, err)
+			}
+		}
+
+		result, err := dao.Collection.InsertMany(ctx, op.Documents, opts)
+
+		return result, dao.mapError(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, model := range models {
+		if err = dao.afterInsert(ctx, model); err != nil {
+			return result, fmt.Errorf("models[%d]: %w", i, err)
+		}
+	}
+
+	return result, nil
 }
 
 // UpdateOne executes an update command to update at most one document in the collection.
 func (dao *Mail) UpdateOne(ctx context.Context, filterFunc MailFilterFunc, updateFunc MailUpdateFunc, optionsFunc ...MailUpdateOptionsFunc) (*mongo.UpdateResult, error) {
 	var (
 		opts   *options.UpdateOptions
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 		update = updateFunc(dao.Columns)
 	)
 
+	update, err := dao.prepareUpdate(ctx, update)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.UpdateOne(ctx, filter, update, opts)
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpUpdateOne, Filter: filter, Update: update}, func(ctx context.Context, op OpInfo) (*mongo.UpdateResult, error) {
+		result, err := dao.Collection.UpdateOne(ctx, op.Filter, op.Update, opts)
+
+		return result, dao.mapError(err)
+	})
 }
 
 // UpdateOneByID executes an update command to update at most one document in the collection.
 func (dao *Mail) UpdateOneByID(ctx context.Context, id string, updateFunc MailUpdateFunc, optionsFunc ...MailUpdateOptionsFunc) (*mongo.UpdateResult, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+	objectID, err := ParseObjectID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -165,34 +603,95 @@ func (dao *Mail) UpdateOneByID(ctx context.Context, id string, updateFunc MailUp
 func (dao *Mail) UpdateMany(ctx context.Context, filterFunc MailFilterFunc, updateFunc MailUpdateFunc, optionsFunc ...MailUpdateOptionsFunc) (*mongo.UpdateResult, error) {
 	var (
 		opts   *options.UpdateOptions
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 		update = updateFunc(dao.Columns)
 	)
 
+	update, err := dao.prepareUpdate(ctx, update)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpUpdateMany, Filter: filter, Update: update}, func(ctx context.Context, op OpInfo) (*mongo.UpdateResult, error) {
+		result, err := dao.Collection.UpdateMany(ctx, op.Filter, op.Update, opts)
+
+		return result, dao.mapError(err)
+	})
+}
+
+// ReplaceOne executes an update command to replace at most one document in the collection.
+func (dao *Mail) ReplaceOne(ctx context.Context, filterFunc MailFilterFunc, model *modelpkg.Mail, optionsFunc ...MailReplaceOptionsFunc) (*mongo.UpdateResult, error) {
+	if model == nil {
+		return nil, errors.New("model is nil")
+	}
+
+	if err := dao.beforeUpdate(ctx, model); err != nil {
+		return nil, err
+	}
+
+	if err := dao.Validate(model); err != nil {
+		return nil, err
+	}
+
+	var (
+		opts   *options.ReplaceOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.UpdateMany(ctx, filter, update, opts)
+	return dao.replaceOne(ctx, filter, model, opts)
+}
+
+// replaceOne replaces the document through the interceptors
+func (dao *Mail) replaceOne(ctx context.Context, filter interface{}, model *modelpkg.Mail, opts *options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpReplaceOne, Filter: filter, Document: model}, func(ctx context.Context, op OpInfo) (*mongo.UpdateResult, error) {
+		result, err := dao.Collection.ReplaceOne(ctx, op.Filter, op.Document, opts)
+
+		return result, dao.mapError(err)
+	})
 }
 
 // FindOne executes a find command and returns a model for one document in the collection.
+// A nil model and a nil error are returned when no document matches.
 func (dao *Mail) FindOne(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailFindOneOptionsFunc) (*modelpkg.Mail, error) {
 	var (
 		opts   *options.FindOneOptions
-		model  = &modelpkg.Mail{}
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 	)
 
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	err := dao.Collection.FindOne(ctx, filter, opts).Decode(model)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, nil
+	model, err := Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpFindOne, Filter: filter}, func(ctx context.Context, op OpInfo) (*modelpkg.Mail, error) {
+		model := &modelpkg.Mail{}
+
+		err := dao.Collection.FindOne(ctx, op.Filter, opts).Decode(model)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, nil
+			}
+			return nil, err
 		}
+
+		return model, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if model == nil {
+		return nil, dao.notFound()
+	}
+
+	if err = dao.afterFind(ctx, model); err != nil {
 		return nil, err
 	}
 
@@ -201,7 +700,7 @@ func (dao *Mail) FindOne(ctx context.Context, filterFunc MailFilterFunc, options
 
 // FindOneByID executes a find command and returns a model for one document in the collection.
 func (dao *Mail) FindOneByID(ctx context.Context, id string, optionsFunc ...MailFindOneOptionsFunc) (*modelpkg.Mail, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+	objectID, err := ParseObjectID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -215,21 +714,139 @@ func (dao *Mail) FindOneByID(ctx context.Context, id string, optionsFunc ...Mail
 func (dao *Mail) FindMany(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailFindManyOptionsFunc) ([]*modelpkg.Mail, error) {
 	var (
 		opts   *options.FindOptions
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	models, err := Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpFindMany, Filter: filter}, func(ctx context.Context, op OpInfo) ([]*modelpkg.Mail, error) {
+		cur, err := dao.Collection.Find(ctx, op.Filter, dao.withDefaultSort(opts))
+		if err != nil {
+			return nil, err
+		}
+
+		models := make([]*modelpkg.Mail, 0)
+
+		if err = cur.All(ctx, &models); err != nil {
+			return nil, err
+		}
+
+		return models, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = dao.afterFind(ctx, models...); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+// MailFindOneAs executes a find command and decodes one document into the partial struct P.
+// Only the columns declared by the bson tags of P are fetched unless the options specify a projection.
+func MailFindOneAs[P any](ctx context.Context, dao *Mail, filterFunc MailFilterFunc, optionsFunc ...MailFindOneOptionsFunc) (*P, error) {
+	var (
+		opts   *options.FindOneOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	model, err := Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpFindOne, Filter: filter}, func(ctx context.Context, op OpInfo) (*P, error) {
+		return FindOneAs[P](ctx, dao.Collection, op.Filter, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if model == nil {
+		return nil, dao.notFound()
+	}
+
+	return model, nil
+}
+
+// MailFindManyAs executes a find command and decodes the matching documents into the partial struct P.
+// Only the columns declared by the bson tags of P are fetched unless the options specify a projection.
+func MailFindManyAs[P any](ctx context.Context, dao *Mail, filterFunc MailFilterFunc, optionsFunc ...MailFindManyOptionsFunc) ([]*P, error) {
+	var (
+		opts   *options.FindOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
 	)
 
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	cur, err := dao.Collection.Find(ctx, filter, opts)
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpFindMany, Filter: filter}, func(ctx context.Context, op OpInfo) ([]*P, error) {
+		return FindManyAs[P](ctx, dao.Collection, op.Filter, dao.withDefaultSort(opts))
+	})
+}
+
+// FindManyByIDs executes a find command and returns the models matching the ids in the same order as the ids.
+// Ids that do not match any document are skipped and repeated ids are returned only once.
+func (dao *Mail) FindManyByIDs(ctx context.Context, ids []string, optionsFunc ...MailFindManyOptionsFunc) ([]*modelpkg.Mail, error) {
+	objectIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := ParseObjectID(id)
+		if err != nil {
+			return nil, err
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+
+	if len(objectIDs) == 0 {
+		return make([]*modelpkg.Mail, 0), nil
+	}
+
+	var opts *options.FindOptions
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	found, err := Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpFindMany, Filter: dao.scoped(bson.M{"_id": bson.M{"$in": objectIDs}})}, func(ctx context.Context, op OpInfo) (map[primitive.ObjectID]*modelpkg.Mail, error) {
+		cur, err := dao.Collection.Find(ctx, op.Filter, opts)
+		if err != nil {
+			return nil, err
+		}
+		defer cur.Close(ctx)
+
+		found := make(map[primitive.ObjectID]*modelpkg.Mail, len(objectIDs))
+		for cur.Next(ctx) {
+			objectID, ok := cur.Current.Lookup("_id").ObjectIDOK()
+			if !ok {
+				continue
+			}
+
+			model := &modelpkg.Mail{}
+			if err = cur.Decode(model); err != nil {
+				return nil, err
+			}
+			found[objectID] = model
+		}
+
+		return found, cur.Err()
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	models := make([]*modelpkg.Mail, 0)
+	models := make([]*modelpkg.Mail, 0, len(found))
+	for _, objectID := range objectIDs {
+		if model, ok := found[objectID]; ok {
+			models = append(models, model)
+			delete(found, objectID)
+		}
+	}
 
-	if err = cur.All(ctx, &models); err != nil {
+	if err = dao.afterFind(ctx, models...); err != nil {
 		return nil, err
 	}
 
@@ -237,22 +854,25 @@ func (dao *Mail) FindMany(ctx context.Context, filterFunc MailFilterFunc, option
 }
 
 // DeleteOne executes a delete command to delete at most one document from the collection.
+// The document is marked as deleted instead of being removed when the model is soft deleted.
 func (dao *Mail) DeleteOne(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailDeleteOptionsFunc) (*mongo.DeleteResult, error) {
 	var (
 		opts   *options.DeleteOptions
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 	)
 
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.DeleteOne(ctx, filter, opts)
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpDeleteOne, Filter: filter}, func(ctx context.Context, op OpInfo) (*mongo.DeleteResult, error) {
+		return mailSoftDelete.DeleteOne(ctx, dao.Collection, op.Filter, opts)
+	})
 }
 
 // DeleteOneByID executes a delete command to delete at most one document from the collection.
 func (dao *Mail) DeleteOneByID(ctx context.Context, id string, optionsFunc ...MailDeleteOptionsFunc) (*mongo.DeleteResult, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+	objectID, err := ParseObjectID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -263,20 +883,124 @@ func (dao *Mail) DeleteOneByID(ctx context.Context, id string, optionsFunc ...Ma
 }
 
 // DeleteMany executes a delete command to delete documents from the collection.
+// The documents are marked as deleted instead of being removed when the model is soft deleted.
 func (dao *Mail) DeleteMany(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailDeleteOptionsFunc) (*mongo.DeleteResult, error) {
 	var (
 		opts   *options.DeleteOptions
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 	)
 
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.DeleteMany(ctx, filter, opts)
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpDeleteMany, Filter: filter}, func(ctx context.Context, op OpInfo) (*mongo.DeleteResult, error) {
+		return mailSoftDelete.DeleteMany(ctx, dao.Collection, op.Filter, opts)
+	})
 }
 
-// autofill when inserting data
+// WithTrashed returns a copy of the dao whose operations include the soft deleted documents.
+func (dao *Mail) WithTrashed() *Mail {
+	d := *dao
+	d.scope = ScopeWithTrashed
+	return &d
+}
+
+// OnlyTrashed returns a copy of the dao whose operations only include the soft deleted documents.
+func (dao *Mail) OnlyTrashed() *Mail {
+	d := *dao
+	d.scope = ScopeOnlyTrashed
+	return &d
+}
+
+// Restore clears the deletion mark of the soft deleted documents matching the filter.
+func (dao *Mail) Restore(ctx context.Context, filterFunc MailFilterFunc) (*mongo.UpdateResult, error) {
+	filter := mailSoftDelete.Apply(filterFunc(dao.Columns), ScopeOnlyTrashed)
+
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpRestore, Filter: filter}, func(ctx context.Context, op OpInfo) (*mongo.UpdateResult, error) {
+		return mailSoftDelete.Restore(ctx, dao.Collection, op.Filter)
+	})
+}
+
+// ForceDelete removes the documents matching the filter from the collection whether they are soft deleted or not.
+// Only the soft deleted documents are removed when the dao is scoped by OnlyTrashed.
+func (dao *Mail) ForceDelete(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailDeleteOptionsFunc) (*mongo.DeleteResult, error) {
+	var (
+		opts   *options.DeleteOptions
+		filter = filterFunc(dao.Columns)
+	)
+
+	if dao.scope == ScopeOnlyTrashed {
+		filter = dao.scoped(filter)
+	}
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpDeleteMany, Filter: filter}, func(ctx context.Context, op OpInfo) (*mongo.DeleteResult, error) {
+		return dao.Collection.DeleteMany(ctx, op.Filter, opts)
+	})
+}
+
+// convert the duplicate key error of the driver into a DuplicateKeyError with the field names of the model
+func (dao *Mail) mapError(err error) error {
+	return MapError(err, mailIndexes, mailFieldNames)
+}
+
+// the error returned by the find one methods when no document matches
+func (dao *Mail) notFound() error {
+	return nil
+}
+
+// apply the soft delete scope of the dao to the filter
+func (dao *Mail) scoped(filter interface{}) interface{} {
+	return mailSoftDelete.Apply(filter, dao.scope)
+}
+
+// prepend the soft delete scope of the dao to the pipeline as a match stage
+func (dao *Mail) scopedPipeline(pipeline interface{}) interface{} {
+	return mailSoftDelete.ApplyPipeline(pipeline, dao.scope)
+}
+
+// resolve the index view used to inspect and change the indexes of the collection
+func (dao *Mail) indexView() IndexView {
+	if dao.IndexView != nil {
+		return dao.IndexView
+	}
+
+	return NewIndexView(dao.Collection.Indexes())
+}
+
+// prepare the update document, which fails with the validation error of the update func, is passed to the BeforeUpdateDocument hook,
+// must not be empty and increments the version of the versioned model
+func (dao *Mail) prepareUpdate(ctx context.Context, update interface{}) (interface{}, error) {
+	if err, ok := update.(error); ok {
+		return nil, err
+	}
+
+	update, err := dao.beforeUpdateDocument(ctx, update)
+	if err != nil {
+		return nil, err
+	}
+
+	if IsEmptyUpdate(update) {
+		return nil, ErrEmptyUpdate
+	}
+
+	return update, nil
+}
+
+// apply the default sort when the options do not specify a sort
+func (dao *Mail) withDefaultSort(opts *options.FindOptions) *options.FindOptions {
+	if mailDefaultSort == nil || (opts != nil && opts.Sort != nil) {
+		return opts
+	}
+
+	return options.MergeFindOptions(opts).SetSort(mailDefaultSort)
+}
+
+// autofill when inserting data
 func (dao *Mail) autofill(ctx context.Context, model *modelpkg.Mail) error {
 	if model.ID.IsZero() {
 		model.ID = primitive.NewObjectID()
@@ -288,3 +1012,587 @@ func (dao *Mail) autofill(ctx context.Context, model *modelpkg.Mail) error {
 
 	return nil
 }
+
+// beforeInsert calls the BeforeInsert hook of the model
+func (dao *Mail) beforeInsert(ctx context.Context, model *modelpkg.Mail) error {
+	return nil
+}
+
+// afterInsert calls the AfterInsert hook of the model
+func (dao *Mail) afterInsert(ctx context.Context, model *modelpkg.Mail) error {
+	return nil
+}
+
+// beforeUpdate calls the BeforeUpdate hook of the model
+func (dao *Mail) beforeUpdate(ctx context.Context, model *modelpkg.Mail) error {
+	return nil
+}
+
+// beforeUpdateDocument calls the BeforeUpdateDocument hook of the model with the update document
+func (dao *Mail) beforeUpdateDocument(ctx context.Context, update interface{}) (interface{}, error) {
+	return update, nil
+}
+
+// afterFind calls the AfterFind hook of the found models
+func (dao *Mail) afterFind(ctx context.Context, models ...*modelpkg.Mail) error {
+	return nil
+}
+
+// MailMemory is an in-memory implementation of MailRepository for the unit tests without a database.
+// The filters, the updates and the options are evaluated by a MemoryCollection which supports a subset of the query language,
+// Aggregate, Watch and ResumeWatch fail with ErrMemoryUnsupported. The operations pass through the interceptors like the dao.
+type MailMemory struct {
+	Columns    *MailColumns
+	Sort       *MailSort
+	Collection *MemoryCollection
+	dao        *Mail
+	scope      Scope
+}
+
+var _ MailRepository = (*MailMemory)(nil)
+
+// NewMailMemory creates an in-memory dao with an empty collection.
+func NewMailMemory() *MailMemory {
+	return &MailMemory{
+		Columns:    mailColumns,
+		Sort:       mailSort,
+		Collection: NewMemoryCollection("mail", mailIndexes, mailFieldNames),
+		dao:        &Mail{Columns: mailColumns, Sort: mailSort},
+	}
+}
+
+// Where returns a filter func that builds the filter with the typed filter conditions of the collection.
+func (dao *MailMemory) Where(filterFunc func(f *MailFilter) bson.D) MailFilterFunc {
+	return dao.dao.Where(filterFunc)
+}
+
+// Update returns an update func that builds the update document with the typed update operators of the collection.
+func (dao *MailMemory) Update(updateFunc func(u *MailUpdate)) MailUpdateFunc {
+	return dao.dao.Update(updateFunc)
+}
+
+// Validate checks the model against the validation rules declared by the gen tags of the model.
+func (dao *MailMemory) Validate(model *modelpkg.Mail) error {
+	return dao.dao.Validate(model)
+}
+
+// Indexes returns the specs of the indexes declared by the gen tags of the model.
+func (dao *MailMemory) Indexes() []IndexSpec {
+	return dao.dao.Indexes()
+}
+
+// EnsureIndexes returns the names of the declared indexes, the unique indexes are always enforced by the collection.
+func (dao *MailMemory) EnsureIndexes(ctx context.Context) ([]string, error) {
+	names := make([]string, 0, len(mailIndexes))
+	for _, index := range mailIndexes {
+		names = append(names, index.Name)
+	}
+
+	return names, nil
+}
+
+// DiffIndexes returns an empty diff because the collection always has the declared indexes.
+func (dao *MailMemory) DiffIndexes(ctx context.Context) (*IndexDiff, error) {
+	return &IndexDiff{}, nil
+}
+
+// SyncIndexes returns an empty diff because the collection always has the declared indexes.
+func (dao *MailMemory) SyncIndexes(ctx context.Context, dropExtra bool) (*IndexDiff, error) {
+	return &IndexDiff{}, nil
+}
+
+// Schema returns the json schema derived from the fields and the gen tags of the model.
+func (dao *MailMemory) Schema() bson.D {
+	return dao.dao.Schema()
+}
+
+// ApplyValidator does nothing, the models are checked by Validate before they are written.
+func (dao *MailMemory) ApplyValidator(ctx context.Context, level ValidationLevel, action ValidationAction) error {
+	return nil
+}
+
+// Count returns the number of documents in the collection.
+func (dao *MailMemory) Count(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailCountOptionsFunc) (int64, error) {
+	var (
+		opts   *options.CountOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpCount, Filter: filter}, func(ctx context.Context, op OpInfo) (int64, error) {
+		return dao.Collection.Count(op.Filter, opts)
+	})
+}
+
+// EstimatedCount returns the number of documents in the collection.
+func (dao *MailMemory) EstimatedCount(ctx context.Context, optionsFunc ...MailEstimatedCountOptionsFunc) (int64, error) {
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpEstimatedCount}, func(ctx context.Context, op OpInfo) (int64, error) {
+		return dao.Collection.EstimatedCount()
+	})
+}
+
+// Exists reports whether at least one document in the collection matches the filter.
+func (dao *MailMemory) Exists(ctx context.Context, filterFunc MailFilterFunc) (bool, error) {
+	filter := dao.scoped(filterFunc(dao.Columns))
+
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpExists, Filter: filter}, func(ctx context.Context, op OpInfo) (bool, error) {
+		document, err := dao.Collection.FindOne(op.Filter, nil)
+		return document != nil, err
+	})
+}
+
+// Aggregate is not supported by the in-memory dao.
+func (dao *MailMemory) Aggregate(ctx context.Context, pipelineFunc MailPipelineFunc, optionsFunc ...MailAggregateOptionsFunc) (*mongo.Cursor, error) {
+	return nil, ErrMemoryUnsupported
+}
+
+// Watch is not supported by the in-memory dao.
+func (dao *MailMemory) Watch(ctx context.Context, pipelineFunc MailPipelineFunc, optionsFunc ...MailWatchOptionsFunc) (*MailChangeStream, error) {
+	return nil, ErrMemoryUnsupported
+}
+
+// ResumeWatch is not supported by the in-memory dao.
+func (dao *MailMemory) ResumeWatch(ctx context.Context, store ResumeTokenStore, pipelineFunc MailPipelineFunc, optionsFunc ...MailWatchOptionsFunc) (*MailChangeStream, error) {
+	return nil, ErrMemoryUnsupported
+}
+
+// Distinct returns the unique values of the column in the documents matching the filter.
+func (dao *MailMemory) Distinct(ctx context.Context, columnFunc MailColumnFunc, filterFunc MailFilterFunc, optionsFunc ...MailDistinctOptionsFunc) ([]interface{}, error) {
+	var (
+		column = columnFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpDistinct, Filter: filter}, func(ctx context.Context, op OpInfo) ([]interface{}, error) {
+		return dao.Collection.Distinct(column, op.Filter)
+	})
+}
+
+// DistinctStrings returns the unique values of a string column.
+func (dao *MailMemory) DistinctStrings(ctx context.Context, columnFunc MailColumnFunc, filterFunc MailFilterFunc, optionsFunc ...MailDistinctOptionsFunc) ([]string, error) {
+	values, err := dao.Distinct(ctx, columnFunc, filterFunc, optionsFunc...)
+	if err != nil {
+		return nil, err
+	}
+
+	return StringValues(values)
+}
+
+// DistinctInt64s returns the unique values of an integer column.
+func (dao *MailMemory) DistinctInt64s(ctx context.Context, columnFunc MailColumnFunc, filterFunc MailFilterFunc, optionsFunc ...MailDistinctOptionsFunc) ([]int64, error) {
+	values, err := dao.Distinct(ctx, columnFunc, filterFunc, optionsFunc...)
+	if err != nil {
+		return nil, err
+	}
+
+	return Int64Values(values)
+}
+
+// InsertOne inserts a single document into the collection.
+func (dao *MailMemory) InsertOne(ctx context.Context, model *modelpkg.Mail, optionsFunc ...MailInsertOneOptionsFunc) (*mongo.InsertOneResult, error) {
+	if model == nil {
+		return nil, errors.New("model is nil")
+	}
+
+	if err := dao.dao.beforeInsert(ctx, model); err != nil {
+		return nil, err
+	}
+
+	result, err := Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpInsertOne, Document: model}, func(ctx context.Context, op OpInfo) (*mongo.InsertOneResult, error) {
+		if err := dao.autofill(ctx, model); err != nil {
+			return nil, err
+		}
+
+		if err := dao.Validate(model); err != nil {
+			return nil, err
+		}
+
+		return dao.Collection.InsertOne(op.Document)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = dao.dao.afterInsert(ctx, model); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// InsertMany inserts multiple documents into the collection.
+func (dao *MailMemory) InsertMany(ctx context.Context, models []*modelpkg.Mail, optionsFunc ...MailInsertManyOptionsFunc) (*mongo.InsertManyResult, error) {
+	if len(models) == 0 {
+		return nil, errors.New("models is empty")
+	}
+
+	documents := make([]interface{}, 0, len(models))
+	for i, model := range models {
+		if err := dao.dao.beforeInsert(ctx, model); err != nil {
+			return nil, fmt.Errorf("models[%d]: %w", i, err)
+		}
+
+		documents = append(documents, model)
+	}
+
+	result, err := Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpInsertMany, Documents: documents}, func(ctx context.Context, op OpInfo) (*mongo.InsertManyResult, error) {
+		for i, model := range models {
+			if err := dao.autofill(ctx, model); err != nil {
+				return nil, err
+			}
+
+			if err := dao.Validate(model); err != nil {
+				return nil, fmt.Errorf("models[%d]: %w", i, err)
+			}
+		}
+
+		return dao.Collection.InsertMany(op.Documents)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, model := range models {
+		if err = dao.dao.afterInsert(ctx, model); err != nil {
+			return result, fmt.Errorf("models[%d]: %w", i, err)
+		}
+	}
+
+	return result, nil
+}
+
+// UpdateOne updates at most one document in the collection.
+func (dao *MailMemory) UpdateOne(ctx context.Context, filterFunc MailFilterFunc, updateFunc MailUpdateFunc, optionsFunc ...MailUpdateOptionsFunc) (*mongo.UpdateResult, error) {
+	return dao.update(ctx, OpUpdateOne, filterFunc, updateFunc, optionsFunc...)
+}
+
+// UpdateOneByID updates at most one document in the collection.
+func (dao *MailMemory) UpdateOneByID(ctx context.Context, id string, updateFunc MailUpdateFunc, optionsFunc ...MailUpdateOptionsFunc) (*mongo.UpdateResult, error) {
+	objectID, err := ParseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.UpdateOne(ctx, func(cols *MailColumns) interface{} {
+		return bson.M{"_id": objectID}
+	}, updateFunc, optionsFunc...)
+}
+
+// UpdateMany updates the documents in the collection.
+func (dao *MailMemory) UpdateMany(ctx context.Context, filterFunc MailFilterFunc, updateFunc MailUpdateFunc, optionsFunc ...MailUpdateOptionsFunc) (*mongo.UpdateResult, error) {
+	return dao.update(ctx, OpUpdateMany, filterFunc, updateFunc, optionsFunc...)
+}
+
+// ReplaceOne replaces at most one document in the collection.
+func (dao *MailMemory) ReplaceOne(ctx context.Context, filterFunc MailFilterFunc, model *modelpkg.Mail, optionsFunc ...MailReplaceOptionsFunc) (*mongo.UpdateResult, error) {
+	if model == nil {
+		return nil, errors.New("model is nil")
+	}
+
+	if err := dao.dao.beforeUpdate(ctx, model); err != nil {
+		return nil, err
+	}
+
+	if err := dao.Validate(model); err != nil {
+		return nil, err
+	}
+
+	var (
+		opts   *options.ReplaceOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return dao.replaceOne(ctx, filter, model, opts)
+}
+
+// replaceOne replaces the document through the interceptors
+func (dao *MailMemory) replaceOne(ctx context.Context, filter interface{}, model *modelpkg.Mail, opts *options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	upsert := opts != nil && opts.Upsert != nil && *opts.Upsert
+
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpReplaceOne, Filter: filter, Document: model}, func(ctx context.Context, op OpInfo) (*mongo.UpdateResult, error) {
+		return dao.Collection.ReplaceOne(op.Filter, op.Document, upsert)
+	})
+}
+
+// FindOne returns a model for one document in the collection.
+// A nil model and a nil error are returned when no document matches.
+func (dao *MailMemory) FindOne(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailFindOneOptionsFunc) (*modelpkg.Mail, error) {
+	var (
+		opts   *options.FindOneOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	model, err := Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpFindOne, Filter: filter}, func(ctx context.Context, op OpInfo) (*modelpkg.Mail, error) {
+		document, err := dao.Collection.FindOne(op.Filter, opts)
+		if err != nil || document == nil {
+			return nil, err
+		}
+
+		model := &modelpkg.Mail{}
+		if err = bson.Unmarshal(document, model); err != nil {
+			return nil, err
+		}
+
+		return model, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if model == nil {
+		return nil, dao.dao.notFound()
+	}
+
+	if err = dao.dao.afterFind(ctx, model); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// FindOneByID returns a model for one document in the collection.
+func (dao *MailMemory) FindOneByID(ctx context.Context, id string, optionsFunc ...MailFindOneOptionsFunc) (*modelpkg.Mail, error) {
+	objectID, err := ParseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.FindOne(ctx, func(cols *MailColumns) interface{} {
+		return bson.M{"_id": objectID}
+	}, optionsFunc...)
+}
+
+// FindMany returns the models of the matching documents in the collection.
+func (dao *MailMemory) FindMany(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailFindManyOptionsFunc) ([]*modelpkg.Mail, error) {
+	var (
+		opts   *options.FindOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	models, err := Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpFindMany, Filter: filter}, func(ctx context.Context, op OpInfo) ([]*modelpkg.Mail, error) {
+		return dao.find(op.Filter, dao.dao.withDefaultSort(opts))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = dao.dao.afterFind(ctx, models...); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+// FindManyByIDs returns the models matching the ids in the same order as the ids.
+// Ids that do not match any document are skipped and repeated ids are returned only once.
+func (dao *MailMemory) FindManyByIDs(ctx context.Context, ids []string, optionsFunc ...MailFindManyOptionsFunc) ([]*modelpkg.Mail, error) {
+	objectIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := ParseObjectID(id)
+		if err != nil {
+			return nil, err
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+
+	if len(objectIDs) == 0 {
+		return make([]*modelpkg.Mail, 0), nil
+	}
+
+	var opts *options.FindOptions
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	found, err := Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpFindMany, Filter: dao.scoped(bson.M{"_id": bson.M{"$in": objectIDs}})}, func(ctx context.Context, op OpInfo) (map[primitive.ObjectID]*modelpkg.Mail, error) {
+		documents, err := dao.Collection.Find(op.Filter, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		found := make(map[primitive.ObjectID]*modelpkg.Mail, len(objectIDs))
+		for _, document := range documents {
+			objectID, ok := document.Lookup("_id").ObjectIDOK()
+			if !ok {
+				continue
+			}
+
+			model := &modelpkg.Mail{}
+			if err = bson.Unmarshal(document, model); err != nil {
+				return nil, err
+			}
+			found[objectID] = model
+		}
+
+		return found, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]*modelpkg.Mail, 0, len(found))
+	for _, objectID := range objectIDs {
+		if model, ok := found[objectID]; ok {
+			models = append(models, model)
+			delete(found, objectID)
+		}
+	}
+
+	if err = dao.dao.afterFind(ctx, models...); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+// DeleteOne deletes at most one document from the collection.
+// The document is marked as deleted instead of being removed when the model is soft deleted.
+func (dao *MailMemory) DeleteOne(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailDeleteOptionsFunc) (*mongo.DeleteResult, error) {
+	filter := dao.scoped(filterFunc(dao.Columns))
+
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpDeleteOne, Filter: filter}, func(ctx context.Context, op OpInfo) (*mongo.DeleteResult, error) {
+		return dao.Collection.SoftDelete(mailSoftDelete, op.Filter, false)
+	})
+}
+
+// DeleteOneByID deletes at most one document from the collection.
+func (dao *MailMemory) DeleteOneByID(ctx context.Context, id string, optionsFunc ...MailDeleteOptionsFunc) (*mongo.DeleteResult, error) {
+	objectID, err := ParseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.DeleteOne(ctx, func(cols *MailColumns) interface{} {
+		return bson.M{"_id": objectID}
+	}, optionsFunc...)
+}
+
+// DeleteMany deletes documents from the collection.
+// The documents are marked as deleted instead of being removed when the model is soft deleted.
+func (dao *MailMemory) DeleteMany(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailDeleteOptionsFunc) (*mongo.DeleteResult, error) {
+	filter := dao.scoped(filterFunc(dao.Columns))
+
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpDeleteMany, Filter: filter}, func(ctx context.Context, op OpInfo) (*mongo.DeleteResult, error) {
+		return dao.Collection.SoftDelete(mailSoftDelete, op.Filter, true)
+	})
+}
+
+// WithTrashed returns a copy of the dao whose operations include the soft deleted documents.
+func (dao *MailMemory) WithTrashed() *MailMemory {
+	d := *dao
+	d.scope = ScopeWithTrashed
+	return &d
+}
+
+// OnlyTrashed returns a copy of the dao whose operations only include the soft deleted documents.
+func (dao *MailMemory) OnlyTrashed() *MailMemory {
+	d := *dao
+	d.scope = ScopeOnlyTrashed
+	return &d
+}
+
+// Restore clears the deletion mark of the soft deleted documents matching the filter.
+func (dao *MailMemory) Restore(ctx context.Context, filterFunc MailFilterFunc) (*mongo.UpdateResult, error) {
+	filter := mailSoftDelete.Apply(filterFunc(dao.Columns), ScopeOnlyTrashed)
+
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpRestore, Filter: filter}, func(ctx context.Context, op OpInfo) (*mongo.UpdateResult, error) {
+		return dao.Collection.Restore(mailSoftDelete, op.Filter)
+	})
+}
+
+// ForceDelete removes the documents matching the filter from the collection whether they are soft deleted or not.
+// Only the soft deleted documents are removed when the dao is scoped by OnlyTrashed.
+func (dao *MailMemory) ForceDelete(ctx context.Context, filterFunc MailFilterFunc, optionsFunc ...MailDeleteOptionsFunc) (*mongo.DeleteResult, error) {
+	filter := filterFunc(dao.Columns)
+
+	if dao.scope == ScopeOnlyTrashed {
+		filter = dao.scoped(filter)
+	}
+
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpDeleteMany, Filter: filter}, func(ctx context.Context, op OpInfo) (*mongo.DeleteResult, error) {
+		return dao.Collection.DeleteMany(op.Filter)
+	})
+}
+
+// update the documents through the interceptors
+func (dao *MailMemory) update(ctx context.Context, kind OpKind, filterFunc MailFilterFunc, updateFunc MailUpdateFunc, optionsFunc ...MailUpdateOptionsFunc) (*mongo.UpdateResult, error) {
+	var (
+		opts   *options.UpdateOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	update, err := dao.dao.prepareUpdate(ctx, updateFunc(dao.Columns))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	upsert := opts != nil && opts.Upsert != nil && *opts.Upsert
+
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: kind, Filter: filter, Update: update}, func(ctx context.Context, op OpInfo) (*mongo.UpdateResult, error) {
+		apply := dao.Collection.UpdateMany
+		if kind == OpUpdateOne {
+			apply = dao.Collection.UpdateOne
+		}
+
+		result, err := apply(op.Filter, op.Update, upsert)
+
+		return result, err
+	})
+}
+
+// find the documents and decode them into the models
+func (dao *MailMemory) find(filter interface{}, opts *options.FindOptions) ([]*modelpkg.Mail, error) {
+	documents, err := dao.Collection.Find(filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]*modelpkg.Mail, 0, len(documents))
+	for _, document := range documents {
+		model := &modelpkg.Mail{}
+		if err = bson.Unmarshal(document, model); err != nil {
+			return nil, err
+		}
+		models = append(models, model)
+	}
+
+	return models, nil
+}
+
+// apply the soft delete scope of the dao to the filter
+func (dao *MailMemory) scoped(filter interface{}) interface{} {
+	return mailSoftDelete.Apply(filter, dao.scope)
+}
+
+// autofill when inserting data, the autoIncr fields are filled by the local counters of the collection
+func (dao *MailMemory) autofill(ctx context.Context, model *modelpkg.Mail) error {
+	if model.ID.IsZero() {
+		model.ID = primitive.NewObjectID()
+	}
+
+	if model.SendTime == 0 {
+		model.SendTime = primitive.NewDateTimeFromTime(time.Now())
+	}
+
+	return nil
+}
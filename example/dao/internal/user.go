@@ -9,32 +9,86 @@ package internal
 import (
 	"context"
 	"errors"
+	"fmt"
 	modelpkg "github.com/dobyte/mongo-dao-generator/example/model"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"time"
+	"unicode/utf8"
 )
 
 type UserFilterFunc func(cols *UserColumns) interface{}
 type UserUpdateFunc func(cols *UserColumns) interface{}
 type UserPipelineFunc func(cols *UserColumns) interface{}
+type UserColumnFunc func(cols *UserColumns) string
 type UserCountOptionsFunc func(cols *UserColumns) *options.CountOptions
+type UserEstimatedCountOptionsFunc func(cols *UserColumns) *options.EstimatedDocumentCountOptions
+type UserDistinctOptionsFunc func(cols *UserColumns) *options.DistinctOptions
 type UserAggregateOptionsFunc func(cols *UserColumns) *options.AggregateOptions
 type UserFindOneOptionsFunc func(cols *UserColumns) *options.FindOneOptions
 type UserFindManyOptionsFunc func(cols *UserColumns) *options.FindOptions
 type UserUpdateOptionsFunc func(cols *UserColumns) *options.UpdateOptions
+type UserReplaceOptionsFunc func(cols *UserColumns) *options.ReplaceOptions
 type UserDeleteOptionsFunc func(cols *UserColumns) *options.DeleteOptions
 type UserInsertOneOptionsFunc func(cols *UserColumns) *options.InsertOneOptions
 type UserInsertManyOptionsFunc func(cols *UserColumns) *options.InsertManyOptions
+type UserWatchOptionsFunc func(cols *UserColumns) *options.ChangeStreamOptions
+
+// UserChangeEvent is a change event of the collection with the full document decoded into the model.
+type UserChangeEvent = ChangeEvent[modelpkg.User]
+
+// UserChangeStream iterates over the change events of the collection.
+type UserChangeStream = ChangeStream[modelpkg.User]
 
 type User struct {
 	Columns    *UserColumns
+	Sort       *UserSort
 	Database   *mongo.Database
 	Collection *mongo.Collection
+	IndexView  IndexView // nil means the index view of the collection
+	scope      Scope
+}
+
+// UserRepository lists the methods of the dao, the services depending on it can be tested without a database.
+// The methods returning a scoped copy of the dao and the generic find functions are not listed.
+type UserRepository interface {
+	Where(filterFunc func(f *UserFilter) bson.D) UserFilterFunc
+	Update(updateFunc func(u *UserUpdate)) UserUpdateFunc
+	Validate(model *modelpkg.User) error
+	Indexes() []IndexSpec
+	EnsureIndexes(ctx context.Context) ([]string, error)
+	DiffIndexes(ctx context.Context) (*IndexDiff, error)
+	SyncIndexes(ctx context.Context, dropExtra bool) (*IndexDiff, error)
+	Schema() bson.D
+	ApplyValidator(ctx context.Context, level ValidationLevel, action ValidationAction) error
+	Count(ctx context.Context, filterFunc UserFilterFunc, optionsFunc ...UserCountOptionsFunc) (int64, error)
+	EstimatedCount(ctx context.Context, optionsFunc ...UserEstimatedCountOptionsFunc) (int64, error)
+	Exists(ctx context.Context, filterFunc UserFilterFunc) (bool, error)
+	Aggregate(ctx context.Context, pipelineFunc UserPipelineFunc, optionsFunc ...UserAggregateOptionsFunc) (*mongo.Cursor, error)
+	Watch(ctx context.Context, pipelineFunc UserPipelineFunc, optionsFunc ...UserWatchOptionsFunc) (*UserChangeStream, error)
+	ResumeWatch(ctx context.Context, store ResumeTokenStore, pipelineFunc UserPipelineFunc, optionsFunc ...UserWatchOptionsFunc) (*UserChangeStream, error)
+	Distinct(ctx context.Context, columnFunc UserColumnFunc, filterFunc UserFilterFunc, optionsFunc ...UserDistinctOptionsFunc) ([]interface{}, error)
+	DistinctStrings(ctx context.Context, columnFunc UserColumnFunc, filterFunc UserFilterFunc, optionsFunc ...UserDistinctOptionsFunc) ([]string, error)
+	DistinctInt64s(ctx context.Context, columnFunc UserColumnFunc, filterFunc UserFilterFunc, optionsFunc ...UserDistinctOptionsFunc) ([]int64, error)
+	InsertOne(ctx context.Context, model *modelpkg.User, optionsFunc ...UserInsertOneOptionsFunc) (*mongo.InsertOneResult, error)
+	InsertMany(ctx context.Context, models []*modelpkg.User, optionsFunc ...UserInsertManyOptionsFunc) (*mongo.InsertManyResult, error)
+	UpdateOne(ctx context.Context, filterFunc UserFilterFunc, version int64, updateFunc UserUpdateFunc, optionsFunc ...UserUpdateOptionsFunc) (*mongo.UpdateResult, error)
+	UpdateOneByID(ctx context.Context, id string, version int64, updateFunc UserUpdateFunc, optionsFunc ...UserUpdateOptionsFunc) (*mongo.UpdateResult, error)
+	UpdateMany(ctx context.Context, filterFunc UserFilterFunc, version int64, updateFunc UserUpdateFunc, optionsFunc ...UserUpdateOptionsFunc) (*mongo.UpdateResult, error)
+	ReplaceOne(ctx context.Context, filterFunc UserFilterFunc, model *modelpkg.User, optionsFunc ...UserReplaceOptionsFunc) (*mongo.UpdateResult, error)
+	FindOne(ctx context.Context, filterFunc UserFilterFunc, optionsFunc ...UserFindOneOptionsFunc) (*modelpkg.User, error)
+	FindOneByID(ctx context.Context, id string, optionsFunc ...UserFindOneOptionsFunc) (*modelpkg.User, error)
+	FindMany(ctx context.Context, filterFunc UserFilterFunc, optionsFunc ...UserFindManyOptionsFunc) ([]*modelpkg.User, error)
+	FindManyByIDs(ctx context.Context, ids []string, optionsFunc ...UserFindManyOptionsFunc) ([]*modelpkg.User, error)
+	DeleteOne(ctx context.Context, filterFunc UserFilterFunc, optionsFunc ...UserDeleteOptionsFunc) (*mongo.DeleteResult, error)
+	DeleteOneByID(ctx context.Context, id string, optionsFunc ...UserDeleteOptionsFunc) (*mongo.DeleteResult, error)
+	DeleteMany(ctx context.Context, filterFunc UserFilterFunc, optionsFunc ...UserDeleteOptionsFunc) (*mongo.DeleteResult, error)
 }
 
+var _ UserRepository = (*User)(nil)
+
 type UserColumns struct {
 	ID             string
 	UID            string // 用户ID
@@ -57,6 +111,7 @@ type UserColumns struct {
 	RegisterTime   string // 注册时间
 	LastLoginIP    string // 最近登录IP
 	LastLoginTime  string // 最近登录时间
+	Version        string // 数据版本
 }
 
 var userColumns = &UserColumns{
@@ -81,42 +136,583 @@ var userColumns = &UserColumns{
 	RegisterTime:   "register_time",   // 注册时间
 	LastLoginIP:    "last_login_ip",   // 最近登录IP
 	LastLoginTime:  "last_login_time", // 最近登录时间
+	Version:        "version",         // 数据版本
+}
+
+// UserFilter provides the typed filter conditions for each column of the collection.
+type UserFilter struct {
+	FilterBuilder
+	ID             OrderedField[primitive.ObjectID]
+	UID            OrderedField[int32]
+	Account        StringField[string]
+	Password       StringField[string]
+	Salt           StringField[string]
+	Mobile         StringField[string]
+	Email          StringField[string]
+	Nickname       StringField[string]
+	Signature      StringField[string]
+	Gender         EnumField[modelpkg.Gender]
+	Level          OrderedField[int]
+	Experience     OrderedField[int]
+	Coin           OrderedField[int]
+	Type           EnumField[modelpkg.Type]
+	Status         EnumField[modelpkg.Status]
+	DeviceID       StringField[string]
+	ThirdPlatforms Field[modelpkg.ThirdPlatforms]
+	RegisterIP     StringField[string]
+	RegisterTime   OrderedField[primitive.DateTime]
+	LastLoginIP    StringField[string]
+	LastLoginTime  OrderedField[primitive.DateTime]
+	Version        OrderedField[int64]
+}
+
+var userFilter = &UserFilter{
+	ID:             NewOrderedField[primitive.ObjectID]("_id"),
+	UID:            NewOrderedField[int32]("uid"),
+	Account:        NewStringField[string]("account"),
+	Password:       NewStringField[string]("password"),
+	Salt:           NewStringField[string]("salt"),
+	Mobile:         NewStringField[string]("mobile"),
+	Email:          NewStringField[string]("email"),
+	Nickname:       NewStringField[string]("nickname"),
+	Signature:      NewStringField[string]("signature"),
+	Gender:         NewEnumField[modelpkg.Gender]("gender", modelpkg.Gender(0).Values()),
+	Level:          NewOrderedField[int]("level"),
+	Experience:     NewOrderedField[int]("experience"),
+	Coin:           NewOrderedField[int]("coin"),
+	Type:           NewEnumField[modelpkg.Type]("type", modelpkg.Type(0).Values()),
+	Status:         NewEnumField[modelpkg.Status]("status", modelpkg.Status(0).Values()),
+	DeviceID:       NewStringField[string]("device_id"),
+	ThirdPlatforms: NewField[modelpkg.ThirdPlatforms]("third_platforms"),
+	RegisterIP:     NewStringField[string]("register_ip"),
+	RegisterTime:   NewOrderedField[primitive.DateTime]("register_time"),
+	LastLoginIP:    NewStringField[string]("last_login_ip"),
+	LastLoginTime:  NewOrderedField[primitive.DateTime]("last_login_time"),
+	Version:        NewOrderedField[int64]("version"),
+}
+
+// UserSort provides the typed sort keys for each column of the collection.
+type UserSort struct {
+	ID             SortField
+	UID            SortField
+	Account        SortField
+	Password       SortField
+	Salt           SortField
+	Mobile         SortField
+	Email          SortField
+	Nickname       SortField
+	Signature      SortField
+	Gender         SortField
+	Level          SortField
+	Experience     SortField
+	Coin           SortField
+	Type           SortField
+	Status         SortField
+	DeviceID       SortField
+	ThirdPlatforms SortField
+	RegisterIP     SortField
+	RegisterTime   SortField
+	LastLoginIP    SortField
+	LastLoginTime  SortField
+	Version        SortField
+}
+
+var userSort = &UserSort{
+	ID:             NewSortField("_id"),
+	UID:            NewSortField("uid"),
+	Account:        NewSortField("account"),
+	Password:       NewSortField("password"),
+	Salt:           NewSortField("salt"),
+	Mobile:         NewSortField("mobile"),
+	Email:          NewSortField("email"),
+	Nickname:       NewSortField("nickname"),
+	Signature:      NewSortField("signature"),
+	Gender:         NewSortField("gender"),
+	Level:          NewSortField("level"),
+	Experience:     NewSortField("experience"),
+	Coin:           NewSortField("coin"),
+	Type:           NewSortField("type"),
+	Status:         NewSortField("status"),
+	DeviceID:       NewSortField("device_id"),
+	ThirdPlatforms: NewSortField("third_platforms"),
+	RegisterIP:     NewSortField("register_ip"),
+	RegisterTime:   NewSortField("register_time"),
+	LastLoginIP:    NewSortField("last_login_ip"),
+	LastLoginTime:  NewSortField("last_login_time"),
+	Version:        NewSortField("version"),
+}
+
+// userDefaultSort is applied by FindMany when the options do not specify a sort.
+var userDefaultSort Sort
+
+// userIndexes are declared by the gen tags of the model.
+var userIndexes = []IndexSpec{
+	{
+		Name:   "uid_1",
+		Keys:   bson.D{{Key: "uid", Value: 1}},
+		Unique: true,
+	},
+	{
+		Name:   "account_1",
+		Keys:   bson.D{{Key: "account", Value: 1}},
+		Unique: true,
+	},
+	{
+		Name:          "mobile_1",
+		Keys:          bson.D{{Key: "mobile", Value: 1}},
+		Unique:        true,
+		PartialFilter: bson.D{{Key: "mobile", Value: bson.D{{Key: "$exists", Value: true}}}},
+	},
+}
+
+// userFieldNames maps the columns to the field names of the model to report the keys of the duplicate key errors.
+var userFieldNames = map[string]string{
+	"_id":             "ID",
+	"uid":             "UID",
+	"account":         "Account",
+	"password":        "Password",
+	"salt":            "Salt",
+	"mobile":          "Mobile",
+	"email":           "Email",
+	"nickname":        "Nickname",
+	"signature":       "Signature",
+	"gender":          "Gender",
+	"level":           "Level",
+	"experience":      "Experience",
+	"coin":            "Coin",
+	"type":            "Type",
+	"status":          "Status",
+	"device_id":       "DeviceID",
+	"third_platforms": "ThirdPlatforms",
+	"register_ip":     "RegisterIP",
+	"register_time":   "RegisterTime",
+	"last_login_ip":   "LastLoginIP",
+	"last_login_time": "LastLoginTime",
+	"version":         "Version",
+}
+
+// userSoftDelete is the column marking the soft deleted documents, nil means the documents are removed on delete.
+var userSoftDelete *SoftDelete
+
+// userSchema is the json schema derived from the fields of the model.
+var userSchema = bson.D{
+	{Key: "bsonType", Value: "object"},
+	{Key: "required", Value: bson.A{"account"}},
+	{Key: "properties", Value: bson.D{
+		{Key: "_id", Value: bson.D{
+			{Key: "bsonType", Value: "objectId"},
+		}},
+		{Key: "uid", Value: bson.D{
+			{Key: "bsonType", Value: "int"},
+		}},
+		{Key: "account", Value: bson.D{
+			{Key: "bsonType", Value: "string"},
+			{Key: "minLength", Value: 4},
+			{Key: "maxLength", Value: 32},
+		}},
+		{Key: "password", Value: bson.D{
+			{Key: "bsonType", Value: "string"},
+		}},
+		{Key: "salt", Value: bson.D{
+			{Key: "bsonType", Value: "string"},
+		}},
+		{Key: "mobile", Value: bson.D{
+			{Key: "bsonType", Value: "string"},
+		}},
+		{Key: "email", Value: bson.D{
+			{Key: "bsonType", Value: "string"},
+		}},
+		{Key: "nickname", Value: bson.D{
+			{Key: "bsonType", Value: "string"},
+		}},
+		{Key: "signature", Value: bson.D{
+			{Key: "bsonType", Value: "string"},
+		}},
+		{Key: "gender", Value: bson.D{
+			{Key: "bsonType", Value: bson.A{"int", "long"}},
+			{Key: "enum", Value: bson.A{0, 1, 2}},
+		}},
+		{Key: "level", Value: bson.D{
+			{Key: "bsonType", Value: bson.A{"int", "long"}},
+			{Key: "minimum", Value: 0},
+		}},
+		{Key: "experience", Value: bson.D{
+			{Key: "bsonType", Value: bson.A{"int", "long"}},
+		}},
+		{Key: "coin", Value: bson.D{
+			{Key: "bsonType", Value: bson.A{"int", "long"}},
+		}},
+		{Key: "type", Value: bson.D{
+			{Key: "bsonType", Value: bson.A{"int", "long"}},
+			{Key: "enum", Value: bson.A{0, 1, 2, 3}},
+		}},
+		{Key: "status", Value: bson.D{
+			{Key: "bsonType", Value: bson.A{"int", "long"}},
+			{Key: "enum", Value: bson.A{0, 1}},
+		}},
+		{Key: "device_id", Value: bson.D{
+			{Key: "bsonType", Value: "string"},
+		}},
+		{Key: "third_platforms", Value: bson.D{
+			{Key: "bsonType", Value: "object"},
+			{Key: "properties", Value: bson.D{
+				{Key: "wechat", Value: bson.D{
+					{Key: "bsonType", Value: "string"},
+				}},
+				{Key: "google", Value: bson.D{
+					{Key: "bsonType", Value: "string"},
+				}},
+				{Key: "facebook", Value: bson.D{
+					{Key: "bsonType", Value: "string"},
+				}},
+			}},
+		}},
+		{Key: "register_ip", Value: bson.D{
+			{Key: "bsonType", Value: "string"},
+		}},
+		{Key: "register_time", Value: bson.D{
+			{Key: "bsonType", Value: "date"},
+		}},
+		{Key: "last_login_ip", Value: bson.D{
+			{Key: "bsonType", Value: "string"},
+		}},
+		{Key: "last_login_time", Value: bson.D{
+			{Key: "bsonType", Value: "date"},
+		}},
+		{Key: "version", Value: bson.D{
+			{Key: "bsonType", Value: "long"},
+		}},
+	}},
+}
+
+// UserUpdate provides the typed update operators for each column of the collection.
+type UserUpdate struct {
+	updater        *Updater
+	UID            NumberUpdateField[int32]
+	Account        OrderedUpdateField[string]
+	Password       OrderedUpdateField[string]
+	Salt           OrderedUpdateField[string]
+	Mobile         OrderedUpdateField[string]
+	Email          OrderedUpdateField[string]
+	Nickname       OrderedUpdateField[string]
+	Signature      OrderedUpdateField[string]
+	Gender         OrderedUpdateField[modelpkg.Gender]
+	Level          NumberUpdateField[int]
+	Experience     NumberUpdateField[int]
+	Coin           NumberUpdateField[int]
+	Type           OrderedUpdateField[modelpkg.Type]
+	Status         OrderedUpdateField[modelpkg.Status]
+	DeviceID       OrderedUpdateField[string]
+	ThirdPlatforms UpdateField[modelpkg.ThirdPlatforms]
+	RegisterIP     OrderedUpdateField[string]
+	RegisterTime   OrderedUpdateField[primitive.DateTime]
+	LastLoginIP    OrderedUpdateField[string]
+	LastLoginTime  OrderedUpdateField[primitive.DateTime]
+}
+
+func newUserUpdate() *UserUpdate {
+	updater := NewUpdater()
+
+	return &UserUpdate{
+		updater:        updater,
+		UID:            NewNumberUpdateField[int32](updater, "uid"),
+		Account:        NewOrderedUpdateField[string](updater, "account", validateUserAccount),
+		Password:       NewOrderedUpdateField[string](updater, "password"),
+		Salt:           NewOrderedUpdateField[string](updater, "salt"),
+		Mobile:         NewOrderedUpdateField[string](updater, "mobile"),
+		Email:          NewOrderedUpdateField[string](updater, "email", validateUserEmail),
+		Nickname:       NewOrderedUpdateField[string](updater, "nickname"),
+		Signature:      NewOrderedUpdateField[string](updater, "signature"),
+		Gender:         NewOrderedUpdateField[modelpkg.Gender](updater, "gender", validateUserGender),
+		Level:          NewNumberUpdateField[int](updater, "level", validateUserLevel),
+		Experience:     NewNumberUpdateField[int](updater, "experience"),
+		Coin:           NewNumberUpdateField[int](updater, "coin"),
+		Type:           NewOrderedUpdateField[modelpkg.Type](updater, "type", validateUserType),
+		Status:         NewOrderedUpdateField[modelpkg.Status](updater, "status", validateUserStatus),
+		DeviceID:       NewOrderedUpdateField[string](updater, "device_id"),
+		ThirdPlatforms: NewUpdateField[modelpkg.ThirdPlatforms](updater, "third_platforms"),
+		RegisterIP:     NewOrderedUpdateField[string](updater, "register_ip"),
+		RegisterTime:   NewOrderedUpdateField[primitive.DateTime](updater, "register_time"),
+		LastLoginIP:    NewOrderedUpdateField[string](updater, "last_login_ip"),
+		LastLoginTime:  NewOrderedUpdateField[primitive.DateTime](updater, "last_login_time"),
+	}
+}
+
+// validateUserAccount checks the value of the account column against the validation rules of the gen tag.
+func validateUserAccount(value string) *FieldError {
+	if value == "" {
+		return &FieldError{Field: "account", Rule: "required", Message: "is required"}
+	}
+
+	if n := utf8.RuneCountInString(value); n < 4 || n > 32 {
+		return &FieldError{Field: "account", Rule: "len", Message: "length must be between 4 and 32"}
+	}
+
+	return nil
+}
+
+// validateUserEmail checks the value of the email column against the validation rules of the gen tag.
+func validateUserEmail(value string) *FieldError {
+	if value != "" && !IsEmail(value) {
+		return &FieldError{Field: "email", Rule: "email", Message: "must be a valid email address"}
+	}
+
+	return nil
+}
+
+// validateUserGender checks the value of the gender column against the validation rules of the gen tag.
+func validateUserGender(value modelpkg.Gender) *FieldError {
+	if !value.IsValid() {
+		return &FieldError{Field: "gender", Rule: "enum", Message: "must be one of the values declared for Gender"}
+	}
+
+	return nil
+}
+
+// validateUserLevel checks the value of the level column against the validation rules of the gen tag.
+func validateUserLevel(value int) *FieldError {
+	if value < 0 {
+		return &FieldError{Field: "level", Rule: "min", Message: "must be at least 0"}
+	}
+
+	return nil
+}
+
+// validateUserType checks the value of the type column against the validation rules of the gen tag.
+func validateUserType(value modelpkg.Type) *FieldError {
+	if !value.IsValid() {
+		return &FieldError{Field: "type", Rule: "enum", Message: "must be one of the values declared for Type"}
+	}
+
+	return nil
+}
+
+// validateUserStatus checks the value of the status column against the validation rules of the gen tag.
+func validateUserStatus(value modelpkg.Status) *FieldError {
+	if !value.IsValid() {
+		return &FieldError{Field: "status", Rule: "enum", Message: "must be one of the values declared for Status"}
+	}
+
+	return nil
 }
 
 func NewUser(db *mongo.Database) *User {
 	return &User{
 		Columns:    userColumns,
+		Sort:       userSort,
 		Database:   db,
 		Collection: db.Collection("user"),
 	}
 }
 
+// Where returns a filter func that builds the filter with the typed filter conditions of the collection.
+func (dao *User) Where(filterFunc func(f *UserFilter) bson.D) UserFilterFunc {
+	return func(cols *UserColumns) interface{} {
+		return filterFunc(userFilter)
+	}
+}
+
+// Update returns an update func that builds the update document with the typed update operators of the collection.
+// The update func returns the validation error instead of the document when a value violates the validation rules.
+func (dao *User) Update(updateFunc func(u *UserUpdate)) UserUpdateFunc {
+	return func(cols *UserColumns) interface{} {
+		u := newUserUpdate()
+		updateFunc(u)
+
+		if err := u.updater.Err(); err != nil {
+			return err
+		}
+
+		return u.updater.Document()
+	}
+}
+
+// Validate checks the model against the validation rules declared by the gen tags of the model.
+func (dao *User) Validate(model *modelpkg.User) error {
+	if model == nil {
+		return errors.New("model is nil")
+	}
+
+	return Validate(
+		validateUserAccount(model.Account),
+		validateUserEmail(model.Email),
+		validateUserGender(model.Gender),
+		validateUserLevel(model.Level),
+		validateUserType(model.Type),
+		validateUserStatus(model.Status),
+	)
+}
+
+// Indexes returns the specs of the indexes declared by the gen tags of the model.
+func (dao *User) Indexes() []IndexSpec {
+	return userIndexes
+}
+
+// EnsureIndexes creates the indexes declared by the gen tags of the model and returns the names of the indexes.
+// Creating an index that already exists with the same specification has no effect.
+func (dao *User) EnsureIndexes(ctx context.Context) ([]string, error) {
+	indexes := dao.Indexes()
+	if len(indexes) == 0 {
+		return nil, nil
+	}
+
+	models := make([]mongo.IndexModel, 0, len(indexes))
+	for _, index := range indexes {
+		models = append(models, index.Model())
+	}
+
+	return dao.Collection.Indexes().CreateMany(ctx, models)
+}
+
+// DiffIndexes compares the indexes declared by the gen tags of the model with the indexes of the collection.
+func (dao *User) DiffIndexes(ctx context.Context) (*IndexDiff, error) {
+	return DiffIndexes(ctx, dao.indexView(), dao.Indexes())
+}
+
+// SyncIndexes makes the indexes of the collection match the indexes declared by the gen tags of the model.
+// The indexes that are not declared are only dropped when dropExtra is true.
+func (dao *User) SyncIndexes(ctx context.Context, dropExtra bool) (*IndexDiff, error) {
+	return SyncIndexes(ctx, dao.indexView(), dao.Indexes(), dropExtra)
+}
+
+// Schema returns the json schema derived from the fields and the gen tags of the model.
+func (dao *User) Schema() bson.D {
+	return userSchema
+}
+
+// ApplyValidator sets the json schema of the model as the validator of the collection.
+// The collection is created with the validator if it does not exist.
+func (dao *User) ApplyValidator(ctx context.Context, level ValidationLevel, action ValidationAction) error {
+	return ApplyValidator(ctx, dao.Database, dao.Collection.Name(), dao.Schema(), level, action)
+}
+
 // Count returns the number of documents in the collection.
 func (dao *User) Count(ctx context.Context, filterFunc UserFilterFunc, optionsFunc ...UserCountOptionsFunc) (int64, error) {
 	var (
 		opts   *options.CountOptions
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 	)
 
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.CountDocuments(ctx, filter, opts)
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpCount, Filter: filter}, func(ctx context.Context, op OpInfo) (int64, error) {
+		return dao.Collection.CountDocuments(ctx, op.Filter, opts)
+	})
+}
+
+// EstimatedCount returns an estimate of the number of documents in the collection using collection metadata.
+func (dao *User) EstimatedCount(ctx context.Context, optionsFunc ...UserEstimatedCountOptionsFunc) (int64, error) {
+	var opts *options.EstimatedDocumentCountOptions
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpEstimatedCount}, func(ctx context.Context, op OpInfo) (int64, error) {
+		return dao.Collection.EstimatedDocumentCount(ctx, opts)
+	})
+}
+
+// Exists reports whether at least one document in the collection matches the filter.
+func (dao *User) Exists(ctx context.Context, filterFunc UserFilterFunc) (bool, error) {
+	var (
+		opts   = options.FindOne().SetProjection(bson.M{"_id": 1})
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpExists, Filter: filter}, func(ctx context.Context, op OpInfo) (bool, error) {
+		err := dao.Collection.FindOne(ctx, op.Filter, opts).Err()
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return false, nil
+			}
+			return false, err
+		}
+
+		return true, nil
+	})
 }
 
 // Aggregate executes an aggregate command against the collection and returns a cursor over the resulting documents.
 func (dao *User) Aggregate(ctx context.Context, pipelineFunc UserPipelineFunc, optionsFunc ...UserAggregateOptionsFunc) (*mongo.Cursor, error) {
 	var (
 		opts     *options.AggregateOptions
+		pipeline = dao.scopedPipeline(pipelineFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpAggregate, Pipeline: pipeline}, func(ctx context.Context, op OpInfo) (*mongo.Cursor, error) {
+		return dao.Collection.Aggregate(ctx, op.Pipeline, opts)
+	})
+}
+
+// Watch opens a change stream on the collection and returns a stream of the events decoded into the model.
+// A nil pipelineFunc watches all the changes of the collection.
+func (dao *User) Watch(ctx context.Context, pipelineFunc UserPipelineFunc, optionsFunc ...UserWatchOptionsFunc) (*UserChangeStream, error) {
+	return dao.ResumeWatch(ctx, nil, pipelineFunc, optionsFunc...)
+}
+
+// ResumeWatch opens a change stream on the collection which resumes after the resume token saved in the store,
+// and saves the resume token of each event into the store once the next event is requested.
+func (dao *User) ResumeWatch(ctx context.Context, store ResumeTokenStore, pipelineFunc UserPipelineFunc, optionsFunc ...UserWatchOptionsFunc) (*UserChangeStream, error) {
+	var (
+		opts     *options.ChangeStreamOptions
+		pipeline interface{}
+	)
+
+	if pipelineFunc != nil {
 		pipeline = pipelineFunc(dao.Columns)
+	}
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpWatch, Pipeline: pipeline}, func(ctx context.Context, op OpInfo) (*UserChangeStream, error) {
+		return Watch[modelpkg.User](ctx, dao.Collection, op.Pipeline, opts, store, dao.Columns)
+	})
+}
+
+// Distinct executes a distinct command to find the unique values for a specified column in the collection.
+func (dao *User) Distinct(ctx context.Context, columnFunc UserColumnFunc, filterFunc UserFilterFunc, optionsFunc ...UserDistinctOptionsFunc) ([]interface{}, error) {
+	var (
+		opts   *options.DistinctOptions
+		column = columnFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 	)
 
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.Aggregate(ctx, pipeline, opts)
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpDistinct, Filter: filter}, func(ctx context.Context, op OpInfo) ([]interface{}, error) {
+		return dao.Collection.Distinct(ctx, column, op.Filter, opts)
+	})
+}
+
+// DistinctStrings executes a distinct command and returns the unique values of a string column.
+func (dao *User) DistinctStrings(ctx context.Context, columnFunc UserColumnFunc, filterFunc UserFilterFunc, optionsFunc ...UserDistinctOptionsFunc) ([]string, error) {
+	values, err := dao.Distinct(ctx, columnFunc, filterFunc, optionsFunc...)
+	if err != nil {
+		return nil, err
+	}
+
+	return StringValues(values)
+}
+
+// DistinctInt64s executes a distinct command and returns the unique values of an integer column.
+func (dao *User) DistinctInt64s(ctx context.Context, columnFunc UserColumnFunc, filterFunc UserFilterFunc, optionsFunc ...UserDistinctOptionsFunc) ([]int64, error) {
+	values, err := dao.Distinct(ctx, columnFunc, filterFunc, optionsFunc...)
+	if err != nil {
+		return nil, err
+	}
+
+	return Int64Values(values)
 }
 
 // InsertOne executes an insert command to insert a single document into the collection.
@@ -125,7 +721,7 @@ func (dao *User) InsertOne(ctx context.Context, model *modelpkg.User, optionsFun
 		return nil, errors.New("model is nil")
 	}
 
-	if err := dao.autofill(ctx, model); err != nil {
+	if err := dao.beforeInsert(ctx, model); err != nil {
 		return nil, err
 	}
 
@@ -135,7 +731,28 @@ func (dao *User) InsertOne(ctx context.Context, model *modelpkg.User, optionsFun
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.InsertOne(ctx, model, opts)
+	result, err := Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpInsertOne, Document: model}, func(ctx context.Context, op OpInfo) (*mongo.InsertOneResult, error) {
+		if err := dao.autofill(ctx, model); err != nil {
+			return nil, err
+		}
+
+		if err := dao.Validate(model); err != nil {
+			return nil, err
+		}
+
+		result, err := dao.Collection.InsertOne(ctx, op.Document, opts)
+
+		return result, dao.mapError(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = dao.afterInsert(ctx, model); err != nil {
+		return result, err
+	}
+
+	return result, nil
 }
 
 // InsertMany executes an insert command to insert multiple documents into the collection.
@@ -145,11 +762,11 @@ func (dao *User) InsertMany(ctx context.Context, models []*modelpkg.User, option
 	}
 
 	documents := make([]interface{}, 0, len(models))
-	for i := range models {
-		model := models[i]
-		if err := dao.autofill(ctx, model); err != nil {
-			return nil, err
+	for i, model := range models {
+		if err := dao.beforeInsert(ctx, model); err != nil {
+			return nil, fmt.Errorf("models[%d]: %w", i, err)
 		}
+
 		documents = append(documents, model)
 	}
 
@@ -159,68 +776,201 @@ func (dao *User) InsertMany(ctx context.Context, models []*modelpkg.User, option
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.InsertMany(ctx, documents, opts)
+	result, err := Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpInsertMany, Documents: documents}, func(ctx context.Context, op OpInfo) (*mongo.InsertManyResult, error) {
+		for i, model := range models {
+			if err := dao.autofill(ctx, model); err != nil {
+				return nil, err
+			}
+
+			if err := dao.Validate(model); err != nil {
+				return nil, fmt.Errorf("models[%d]: %w", i, err)
+			}
+		}
+
+		result, err := dao.Collection.InsertMany(ctx, op.Documents, opts)
+
+		return result, dao.mapError(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, model := range models {
+		if err = dao.afterInsert(ctx, model); err != nil {
+			return result, fmt.Errorf("models[%d]: %w", i, err)
+		}
+	}
+
+	return result, nil
 }
 
 // UpdateOne executes an update command to update at most one document in the collection.
-func (dao *User) UpdateOne(ctx context.Context, filterFunc UserFilterFunc, updateFunc UserUpdateFunc, optionsFunc ...UserUpdateOptionsFunc) (*mongo.UpdateResult, error) {
+func (dao *User) UpdateOne(ctx context.Context, filterFunc UserFilterFunc, version int64, updateFunc UserUpdateFunc, optionsFunc ...UserUpdateOptionsFunc) (*mongo.UpdateResult, error) {
 	var (
 		opts   *options.UpdateOptions
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 		update = updateFunc(dao.Columns)
 	)
 
+	update, err := dao.prepareUpdate(ctx, update)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.UpdateOne(ctx, filter, update, opts)
+	if opts != nil && opts.Upsert != nil && *opts.Upsert {
+		return nil, ErrVersionUpsert
+	}
+
+	filter = AndFilter(filter, bson.D{{Key: "version", Value: version}})
+
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpUpdateOne, Filter: filter, Update: update}, func(ctx context.Context, op OpInfo) (*mongo.UpdateResult, error) {
+		result, err := dao.Collection.UpdateOne(ctx, op.Filter, op.Update, opts)
+
+		if err == nil && result.MatchedCount == 0 {
+			return nil, ErrVersionConflict
+		}
+
+		return result, dao.mapError(err)
+	})
 }
 
 // UpdateOneByID executes an update command to update at most one document in the collection.
-func (dao *User) UpdateOneByID(ctx context.Context, id string, updateFunc UserUpdateFunc, optionsFunc ...UserUpdateOptionsFunc) (*mongo.UpdateResult, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+func (dao *User) UpdateOneByID(ctx context.Context, id string, version int64, updateFunc UserUpdateFunc, optionsFunc ...UserUpdateOptionsFunc) (*mongo.UpdateResult, error) {
+	objectID, err := ParseObjectID(id)
 	if err != nil {
 		return nil, err
 	}
 
 	return dao.UpdateOne(ctx, func(cols *UserColumns) interface{} {
 		return bson.M{"_id": objectID}
-	}, updateFunc, optionsFunc...)
+	}, version, updateFunc, optionsFunc...)
 }
 
 // UpdateMany executes an update command to update documents in the collection.
-func (dao *User) UpdateMany(ctx context.Context, filterFunc UserFilterFunc, updateFunc UserUpdateFunc, optionsFunc ...UserUpdateOptionsFunc) (*mongo.UpdateResult, error) {
+func (dao *User) UpdateMany(ctx context.Context, filterFunc UserFilterFunc, version int64, updateFunc UserUpdateFunc, optionsFunc ...UserUpdateOptionsFunc) (*mongo.UpdateResult, error) {
 	var (
 		opts   *options.UpdateOptions
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 		update = updateFunc(dao.Columns)
 	)
 
+	update, err := dao.prepareUpdate(ctx, update)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	if opts != nil && opts.Upsert != nil && *opts.Upsert {
+		return nil, ErrVersionUpsert
+	}
+
+	filter = AndFilter(filter, bson.D{{Key: "version", Value: version}})
+
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpUpdateMany, Filter: filter, Update: update}, func(ctx context.Context, op OpInfo) (*mongo.UpdateResult, error) {
+		result, err := dao.Collection.UpdateMany(ctx, op.Filter, op.Update, opts)
+
+		if err == nil && result.MatchedCount == 0 {
+			return nil, ErrVersionConflict
+		}
+
+		return result, dao.mapError(err)
+	})
+}
+
+// ReplaceOne executes an update command to replace at most one document in the collection.
+func (dao *User) ReplaceOne(ctx context.Context, filterFunc UserFilterFunc, model *modelpkg.User, optionsFunc ...UserReplaceOptionsFunc) (*mongo.UpdateResult, error) {
+	if model == nil {
+		return nil, errors.New("model is nil")
+	}
+
+	if err := dao.beforeUpdate(ctx, model); err != nil {
+		return nil, err
+	}
+
+	if err := dao.Validate(model); err != nil {
+		return nil, err
+	}
+
+	var (
+		opts   *options.ReplaceOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.UpdateMany(ctx, filter, update, opts)
+	if opts != nil && opts.Upsert != nil && *opts.Upsert {
+		return nil, ErrVersionUpsert
+	}
+
+	version := model.Version
+	filter = AndFilter(filter, bson.D{{Key: "version", Value: version}})
+	model.Version++
+
+	result, err := dao.replaceOne(ctx, filter, model, opts)
+	if err == nil && result.MatchedCount == 0 && result.UpsertedCount == 0 {
+		err = ErrVersionConflict
+	}
+
+	if err != nil {
+		model.Version = version
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// replaceOne replaces the document through the interceptors
+func (dao *User) replaceOne(ctx context.Context, filter interface{}, model *modelpkg.User, opts *options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpReplaceOne, Filter: filter, Document: model}, func(ctx context.Context, op OpInfo) (*mongo.UpdateResult, error) {
+		result, err := dao.Collection.ReplaceOne(ctx, op.Filter, op.Document, opts)
+
+		return result, dao.mapError(err)
+	})
 }
 
 // FindOne executes a find command and returns a model for one document in the collection.
+// A nil model and a nil error are returned when no document matches.
 func (dao *User) FindOne(ctx context.Context, filterFunc UserFilterFunc, optionsFunc ...UserFindOneOptionsFunc) (*modelpkg.User, error) {
 	var (
 		opts   *options.FindOneOptions
-		model  = &modelpkg.User{}
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 	)
 
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	err := dao.Collection.FindOne(ctx, filter, opts).Decode(model)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, nil
+	model, err := Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpFindOne, Filter: filter}, func(ctx context.Context, op OpInfo) (*modelpkg.User, error) {
+		model := &modelpkg.User{}
+
+		err := dao.Collection.FindOne(ctx, op.Filter, opts).Decode(model)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, nil
+			}
+			return nil, err
 		}
+
+		return model, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if model == nil {
+		return nil, dao.notFound()
+	}
+
+	if err = dao.afterFind(ctx, model); err != nil {
 		return nil, err
 	}
 
@@ -229,7 +979,7 @@ func (dao *User) FindOne(ctx context.Context, filterFunc UserFilterFunc, options
 
 // FindOneByID executes a find command and returns a model for one document in the collection.
 func (dao *User) FindOneByID(ctx context.Context, id string, optionsFunc ...UserFindOneOptionsFunc) (*modelpkg.User, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+	objectID, err := ParseObjectID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -243,65 +993,250 @@ func (dao *User) FindOneByID(ctx context.Context, id string, optionsFunc ...User
 func (dao *User) FindMany(ctx context.Context, filterFunc UserFilterFunc, optionsFunc ...UserFindManyOptionsFunc) ([]*modelpkg.User, error) {
 	var (
 		opts   *options.FindOptions
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 	)
 
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	cur, err := dao.Collection.Find(ctx, filter, opts)
+	models, err := Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpFindMany, Filter: filter}, func(ctx context.Context, op OpInfo) ([]*modelpkg.User, error) {
+		cur, err := dao.Collection.Find(ctx, op.Filter, dao.withDefaultSort(opts))
+		if err != nil {
+			return nil, err
+		}
+
+		models := make([]*modelpkg.User, 0)
+
+		if err = cur.All(ctx, &models); err != nil {
+			return nil, err
+		}
+
+		return models, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	models := make([]*modelpkg.User, 0)
-
-	if err = cur.All(ctx, &models); err != nil {
+	if err = dao.afterFind(ctx, models...); err != nil {
 		return nil, err
 	}
 
 	return models, nil
 }
 
-// DeleteOne executes a delete command to delete at most one document from the collection.
-func (dao *User) DeleteOne(ctx context.Context, filterFunc UserFilterFunc, optionsFunc ...UserDeleteOptionsFunc) (*mongo.DeleteResult, error) {
+// UserFindOneAs executes a find command and decodes one document into the partial struct P.
+// Only the columns declared by the bson tags of P are fetched unless the options specify a projection.
+func UserFindOneAs[P any](ctx context.Context, dao *User, filterFunc UserFilterFunc, optionsFunc ...UserFindOneOptionsFunc) (*P, error) {
 	var (
-		opts   *options.DeleteOptions
-		filter = filterFunc(dao.Columns)
+		opts   *options.FindOneOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
 	)
 
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.DeleteOne(ctx, filter, opts)
-}
-
-// DeleteOneByID executes a delete command to delete at most one document from the collection.
-func (dao *User) DeleteOneByID(ctx context.Context, id string, optionsFunc ...UserDeleteOptionsFunc) (*mongo.DeleteResult, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+	model, err := Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpFindOne, Filter: filter}, func(ctx context.Context, op OpInfo) (*P, error) {
+		return FindOneAs[P](ctx, dao.Collection, op.Filter, opts)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return dao.DeleteOne(ctx, func(cols *UserColumns) interface{} {
-		return bson.M{"_id": objectID}
-	}, optionsFunc...)
+	if model == nil {
+		return nil, dao.notFound()
+	}
+
+	return model, nil
 }
 
-// DeleteMany executes a delete command to delete documents from the collection.
-func (dao *User) DeleteMany(ctx context.Context, filterFunc UserFilterFunc, optionsFunc ...UserDeleteOptionsFunc) (*mongo.DeleteResult, error) {
+// UserFindManyAs executes a find command and decodes the matching documents into the partial struct P.
+// Only the columns declared by the bson tags of P are fetched unless the options specify a projection.
+func UserFindManyAs[P any](ctx context.Context, dao *User, filterFunc UserFilterFunc, optionsFunc ...UserFindManyOptionsFunc) ([]*P, error) {
 	var (
-		opts   *options.DeleteOptions
-		filter = filterFunc(dao.Columns)
+		opts   *options.FindOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
 	)
 
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.DeleteMany(ctx, filter, opts)
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpFindMany, Filter: filter}, func(ctx context.Context, op OpInfo) ([]*P, error) {
+		return FindManyAs[P](ctx, dao.Collection, op.Filter, dao.withDefaultSort(opts))
+	})
+}
+
+// FindManyByIDs executes a find command and returns the models matching the ids in the same order as the ids.
+// Ids that do not match any document are skipped and repeated ids are returned only once.
+func (dao *User) FindManyByIDs(ctx context.Context, ids []string, optionsFunc ...UserFindManyOptionsFunc) ([]*modelpkg.User, error) {
+	objectIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := ParseObjectID(id)
+		if err != nil {
+			return nil, err
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+
+	if len(objectIDs) == 0 {
+		return make([]*modelpkg.User, 0), nil
+	}
+
+	var opts *options.FindOptions
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	found, err := Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpFindMany, Filter: dao.scoped(bson.M{"_id": bson.M{"$in": objectIDs}})}, func(ctx context.Context, op OpInfo) (map[primitive.ObjectID]*modelpkg.User, error) {
+		cur, err := dao.Collection.Find(ctx, op.Filter, opts)
+		if err != nil {
+			return nil, err
+		}
+		defer cur.Close(ctx)
+
+		found := make(map[primitive.ObjectID]*modelpkg.User, len(objectIDs))
+		for cur.Next(ctx) {
+			objectID, ok := cur.Current.Lookup("_id").ObjectIDOK()
+			if !ok {
+				continue
+			}
+
+			model := &modelpkg.User{}
+			if err = cur.Decode(model); err != nil {
+				return nil, err
+			}
+			found[objectID] = model
+		}
+
+		return found, cur.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]*modelpkg.User, 0, len(found))
+	for _, objectID := range objectIDs {
+		if model, ok := found[objectID]; ok {
+			models = append(models, model)
+			delete(found, objectID)
+		}
+	}
+
+	if err = dao.afterFind(ctx, models...); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+// DeleteOne executes a delete command to delete at most one document from the collection.
+// The document is marked as deleted instead of being removed when the model is soft deleted.
+func (dao *User) DeleteOne(ctx context.Context, filterFunc UserFilterFunc, optionsFunc ...UserDeleteOptionsFunc) (*mongo.DeleteResult, error) {
+	var (
+		opts   *options.DeleteOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpDeleteOne, Filter: filter}, func(ctx context.Context, op OpInfo) (*mongo.DeleteResult, error) {
+		return dao.Collection.DeleteOne(ctx, op.Filter, opts)
+	})
+}
+
+// DeleteOneByID executes a delete command to delete at most one document from the collection.
+func (dao *User) DeleteOneByID(ctx context.Context, id string, optionsFunc ...UserDeleteOptionsFunc) (*mongo.DeleteResult, error) {
+	objectID, err := ParseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.DeleteOne(ctx, func(cols *UserColumns) interface{} {
+		return bson.M{"_id": objectID}
+	}, optionsFunc...)
+}
+
+// DeleteMany executes a delete command to delete documents from the collection.
+// The documents are marked as deleted instead of being removed when the model is soft deleted.
+func (dao *User) DeleteMany(ctx context.Context, filterFunc UserFilterFunc, optionsFunc ...UserDeleteOptionsFunc) (*mongo.DeleteResult, error) {
+	var (
+		opts   *options.DeleteOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return Invoke(ctx, OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: OpDeleteMany, Filter: filter}, func(ctx context.Context, op OpInfo) (*mongo.DeleteResult, error) {
+		return dao.Collection.DeleteMany(ctx, op.Filter, opts)
+	})
+}
+
+func init() {
+	RegisterSensitiveColumns("user", "password", "salt")
+}
+
+// convert the duplicate key error of the driver into a DuplicateKeyError with the field names of the model
+func (dao *User) mapError(err error) error {
+	return MapError(err, userIndexes, userFieldNames)
+}
+
+// the error returned by the find one methods when no document matches
+func (dao *User) notFound() error {
+	return nil
+}
+
+// apply the soft delete scope of the dao to the filter
+func (dao *User) scoped(filter interface{}) interface{} {
+	return userSoftDelete.Apply(filter, dao.scope)
+}
+
+// prepend the soft delete scope of the dao to the pipeline as a match stage
+func (dao *User) scopedPipeline(pipeline interface{}) interface{} {
+	return userSoftDelete.ApplyPipeline(pipeline, dao.scope)
+}
+
+// resolve the index view used to inspect and change the indexes of the collection
+func (dao *User) indexView() IndexView {
+	if dao.IndexView != nil {
+		return dao.IndexView
+	}
+
+	return NewIndexView(dao.Collection.Indexes())
+}
+
+// prepare the update document, which fails with the validation error of the update func, is passed to the BeforeUpdateDocument hook,
+// must not be empty and increments the version of the versioned model
+func (dao *User) prepareUpdate(ctx context.Context, update interface{}) (interface{}, error) {
+	if err, ok := update.(error); ok {
+		return nil, err
+	}
+
+	update, err := dao.beforeUpdateDocument(ctx, update)
+	if err != nil {
+		return nil, err
+	}
+
+	if IsEmptyUpdate(update) {
+		return nil, ErrEmptyUpdate
+	}
+
+	return IncVersion(update, "version")
+}
+
+// apply the default sort when the options do not specify a sort
+func (dao *User) withDefaultSort(opts *options.FindOptions) *options.FindOptions {
+	if userDefaultSort == nil || (opts != nil && opts.Sort != nil) {
+		return opts
+	}
+
+	return options.MergeFindOptions(opts).SetSort(userDefaultSort)
 }
 
 // autofill when inserting data
@@ -328,3 +1263,590 @@ func (dao *User) autofill(ctx context.Context, model *modelpkg.User) error {
 
 	return nil
 }
+
+// beforeInsert calls the BeforeInsert hook of the model
+func (dao *User) beforeInsert(ctx context.Context, model *modelpkg.User) error {
+	return nil
+}
+
+// afterInsert calls the AfterInsert hook of the model
+func (dao *User) afterInsert(ctx context.Context, model *modelpkg.User) error {
+	return nil
+}
+
+// beforeUpdate calls the BeforeUpdate hook of the model
+func (dao *User) beforeUpdate(ctx context.Context, model *modelpkg.User) error {
+	return nil
+}
+
+// beforeUpdateDocument calls the BeforeUpdateDocument hook of the model with the update document
+func (dao *User) beforeUpdateDocument(ctx context.Context, update interface{}) (interface{}, error) {
+	return update, nil
+}
+
+// afterFind calls the AfterFind hook of the found models
+func (dao *User) afterFind(ctx context.Context, models ...*modelpkg.User) error {
+	return nil
+}
+
+// UserMemory is an in-memory implementation of UserRepository for the unit tests without a database.
+// The filters, the updates and the options are evaluated by a MemoryCollection which supports a subset of the query language,
+// Aggregate, Watch and ResumeWatch fail with ErrMemoryUnsupported. The operations pass through the interceptors like the dao.
+type UserMemory struct {
+	Columns    *UserColumns
+	Sort       *UserSort
+	Collection *MemoryCollection
+	dao        *User
+	scope      Scope
+}
+
+var _ UserRepository = (*UserMemory)(nil)
+
+// NewUserMemory creates an in-memory dao with an empty collection.
+func NewUserMemory() *UserMemory {
+	return &UserMemory{
+		Columns:    userColumns,
+		Sort:       userSort,
+		Collection: NewMemoryCollection("user", userIndexes, userFieldNames),
+		dao:        &User{Columns: userColumns, Sort: userSort},
+	}
+}
+
+// Where returns a filter func that builds the filter with the typed filter conditions of the collection.
+func (dao *UserMemory) Where(filterFunc func(f *UserFilter) bson.D) UserFilterFunc {
+	return dao.dao.Where(filterFunc)
+}
+
+// Update returns an update func that builds the update document with the typed update operators of the collection.
+func (dao *UserMemory) Update(updateFunc func(u *UserUpdate)) UserUpdateFunc {
+	return dao.dao.Update(updateFunc)
+}
+
+// Validate checks the model against the validation rules declared by the gen tags of the model.
+func (dao *UserMemory) Validate(model *modelpkg.User) error {
+	return dao.dao.Validate(model)
+}
+
+// Indexes returns the specs of the indexes declared by the gen tags of the model.
+func (dao *UserMemory) Indexes() []IndexSpec {
+	return dao.dao.Indexes()
+}
+
+// EnsureIndexes returns the names of the declared indexes, the unique indexes are always enforced by the collection.
+func (dao *UserMemory) EnsureIndexes(ctx context.Context) ([]string, error) {
+	names := make([]string, 0, len(userIndexes))
+	for _, index := range userIndexes {
+		names = append(names, index.Name)
+	}
+
+	return names, nil
+}
+
+// DiffIndexes returns an empty diff because the collection always has the declared indexes.
+func (dao *UserMemory) DiffIndexes(ctx context.Context) (*IndexDiff, error) {
+	return &IndexDiff{}, nil
+}
+
+// SyncIndexes returns an empty diff because the collection always has the declared indexes.
+func (dao *UserMemory) SyncIndexes(ctx context.Context, dropExtra bool) (*IndexDiff, error) {
+	return &IndexDiff{}, nil
+}
+
+// Schema returns the json schema derived from the fields and the gen tags of the model.
+func (dao *UserMemory) Schema() bson.D {
+	return dao.dao.Schema()
+}
+
+// ApplyValidator does nothing, the models are checked by Validate before they are written.
+func (dao *UserMemory) ApplyValidator(ctx context.Context, level ValidationLevel, action ValidationAction) error {
+	return nil
+}
+
+// Count returns the number of documents in the collection.
+func (dao *UserMemory) Count(ctx context.Context, filterFunc UserFilterFunc, optionsFunc ...UserCountOptionsFunc) (int64, error) {
+	var (
+		opts   *options.CountOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpCount, Filter: filter}, func(ctx context.Context, op OpInfo) (int64, error) {
+		return dao.Collection.Count(op.Filter, opts)
+	})
+}
+
+// EstimatedCount returns the number of documents in the collection.
+func (dao *UserMemory) EstimatedCount(ctx context.Context, optionsFunc ...UserEstimatedCountOptionsFunc) (int64, error) {
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpEstimatedCount}, func(ctx context.Context, op OpInfo) (int64, error) {
+		return dao.Collection.EstimatedCount()
+	})
+}
+
+// Exists reports whether at least one document in the collection matches the filter.
+func (dao *UserMemory) Exists(ctx context.Context, filterFunc UserFilterFunc) (bool, error) {
+	filter := dao.scoped(filterFunc(dao.Columns))
+
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpExists, Filter: filter}, func(ctx context.Context, op OpInfo) (bool, error) {
+		document, err := dao.Collection.FindOne(op.Filter, nil)
+		return document != nil, err
+	})
+}
+
+// Aggregate is not supported by the in-memory dao.
+func (dao *UserMemory) Aggregate(ctx context.Context, pipelineFunc UserPipelineFunc, optionsFunc ...UserAggregateOptionsFunc) (*mongo.Cursor, error) {
+	return nil, ErrMemoryUnsupported
+}
+
+// Watch is not supported by the in-memory dao.
+func (dao *UserMemory) Watch(ctx context.Context, pipelineFunc UserPipelineFunc, optionsFunc ...UserWatchOptionsFunc) (*UserChangeStream, error) {
+	return nil, ErrMemoryUnsupported
+}
+
+// ResumeWatch is not supported by the in-memory dao.
+func (dao *UserMemory) ResumeWatch(ctx context.Context, store ResumeTokenStore, pipelineFunc UserPipelineFunc, optionsFunc ...UserWatchOptionsFunc) (*UserChangeStream, error) {
+	return nil, ErrMemoryUnsupported
+}
+
+// Distinct returns the unique values of the column in the documents matching the filter.
+func (dao *UserMemory) Distinct(ctx context.Context, columnFunc UserColumnFunc, filterFunc UserFilterFunc, optionsFunc ...UserDistinctOptionsFunc) ([]interface{}, error) {
+	var (
+		column = columnFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpDistinct, Filter: filter}, func(ctx context.Context, op OpInfo) ([]interface{}, error) {
+		return dao.Collection.Distinct(column, op.Filter)
+	})
+}
+
+// DistinctStrings returns the unique values of a string column.
+func (dao *UserMemory) DistinctStrings(ctx context.Context, columnFunc UserColumnFunc, filterFunc UserFilterFunc, optionsFunc ...UserDistinctOptionsFunc) ([]string, error) {
+	values, err := dao.Distinct(ctx, columnFunc, filterFunc, optionsFunc...)
+	if err != nil {
+		return nil, err
+	}
+
+	return StringValues(values)
+}
+
+// DistinctInt64s returns the unique values of an integer column.
+func (dao *UserMemory) DistinctInt64s(ctx context.Context, columnFunc UserColumnFunc, filterFunc UserFilterFunc, optionsFunc ...UserDistinctOptionsFunc) ([]int64, error) {
+	values, err := dao.Distinct(ctx, columnFunc, filterFunc, optionsFunc...)
+	if err != nil {
+		return nil, err
+	}
+
+	return Int64Values(values)
+}
+
+// InsertOne inserts a single document into the collection.
+func (dao *UserMemory) InsertOne(ctx context.Context, model *modelpkg.User, optionsFunc ...UserInsertOneOptionsFunc) (*mongo.InsertOneResult, error) {
+	if model == nil {
+		return nil, errors.New("model is nil")
+	}
+
+	if err := dao.dao.beforeInsert(ctx, model); err != nil {
+		return nil, err
+	}
+
+	result, err := Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpInsertOne, Document: model}, func(ctx context.Context, op OpInfo) (*mongo.InsertOneResult, error) {
+		if err := dao.autofill(ctx, model); err != nil {
+			return nil, err
+		}
+
+		if err := dao.Validate(model); err != nil {
+			return nil, err
+		}
+
+		return dao.Collection.InsertOne(op.Document)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = dao.dao.afterInsert(ctx, model); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// InsertMany inserts multiple documents into the collection.
+func (dao *UserMemory) InsertMany(ctx context.Context, models []*modelpkg.User, optionsFunc ...UserInsertManyOptionsFunc) (*mongo.InsertManyResult, error) {
+	if len(models) == 0 {
+		return nil, errors.New("models is empty")
+	}
+
+	documents := make([]interface{}, 0, len(models))
+	for i, model := range models {
+		if err := dao.dao.beforeInsert(ctx, model); err != nil {
+			return nil, fmt.Errorf("models[%d]: %w", i, err)
+		}
+
+		documents = append(documents, model)
+	}
+
+	result, err := Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpInsertMany, Documents: documents}, func(ctx context.Context, op OpInfo) (*mongo.InsertManyResult, error) {
+		for i, model := range models {
+			if err := dao.autofill(ctx, model); err != nil {
+				return nil, err
+			}
+
+			if err := dao.Validate(model); err != nil {
+				return nil, fmt.Errorf("models[%d]: %w", i, err)
+			}
+		}
+
+		return dao.Collection.InsertMany(op.Documents)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, model := range models {
+		if err = dao.dao.afterInsert(ctx, model); err != nil {
+			return result, fmt.Errorf("models[%d]: %w", i, err)
+		}
+	}
+
+	return result, nil
+}
+
+// UpdateOne updates at most one document in the collection.
+func (dao *UserMemory) UpdateOne(ctx context.Context, filterFunc UserFilterFunc, version int64, updateFunc UserUpdateFunc, optionsFunc ...UserUpdateOptionsFunc) (*mongo.UpdateResult, error) {
+	return dao.update(ctx, OpUpdateOne, filterFunc, version, updateFunc, optionsFunc...)
+}
+
+// UpdateOneByID updates at most one document in the collection.
+func (dao *UserMemory) UpdateOneByID(ctx context.Context, id string, version int64, updateFunc UserUpdateFunc, optionsFunc ...UserUpdateOptionsFunc) (*mongo.UpdateResult, error) {
+	objectID, err := ParseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.UpdateOne(ctx, func(cols *UserColumns) interface{} {
+		return bson.M{"_id": objectID}
+	}, version, updateFunc, optionsFunc...)
+}
+
+// UpdateMany updates the documents in the collection.
+func (dao *UserMemory) UpdateMany(ctx context.Context, filterFunc UserFilterFunc, version int64, updateFunc UserUpdateFunc, optionsFunc ...UserUpdateOptionsFunc) (*mongo.UpdateResult, error) {
+	return dao.update(ctx, OpUpdateMany, filterFunc, version, updateFunc, optionsFunc...)
+}
+
+// ReplaceOne replaces at most one document in the collection.
+func (dao *UserMemory) ReplaceOne(ctx context.Context, filterFunc UserFilterFunc, model *modelpkg.User, optionsFunc ...UserReplaceOptionsFunc) (*mongo.UpdateResult, error) {
+	if model == nil {
+		return nil, errors.New("model is nil")
+	}
+
+	if err := dao.dao.beforeUpdate(ctx, model); err != nil {
+		return nil, err
+	}
+
+	if err := dao.Validate(model); err != nil {
+		return nil, err
+	}
+
+	var (
+		opts   *options.ReplaceOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	if opts != nil && opts.Upsert != nil && *opts.Upsert {
+		return nil, ErrVersionUpsert
+	}
+
+	version := model.Version
+	filter = AndFilter(filter, bson.D{{Key: "version", Value: version}})
+	model.Version++
+
+	result, err := dao.replaceOne(ctx, filter, model, opts)
+	if err == nil && result.MatchedCount == 0 && result.UpsertedCount == 0 {
+		err = ErrVersionConflict
+	}
+
+	if err != nil {
+		model.Version = version
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// replaceOne replaces the document through the interceptors
+func (dao *UserMemory) replaceOne(ctx context.Context, filter interface{}, model *modelpkg.User, opts *options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	upsert := opts != nil && opts.Upsert != nil && *opts.Upsert
+
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpReplaceOne, Filter: filter, Document: model}, func(ctx context.Context, op OpInfo) (*mongo.UpdateResult, error) {
+		return dao.Collection.ReplaceOne(op.Filter, op.Document, upsert)
+	})
+}
+
+// FindOne returns a model for one document in the collection.
+// A nil model and a nil error are returned when no document matches.
+func (dao *UserMemory) FindOne(ctx context.Context, filterFunc UserFilterFunc, optionsFunc ...UserFindOneOptionsFunc) (*modelpkg.User, error) {
+	var (
+		opts   *options.FindOneOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	model, err := Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpFindOne, Filter: filter}, func(ctx context.Context, op OpInfo) (*modelpkg.User, error) {
+		document, err := dao.Collection.FindOne(op.Filter, opts)
+		if err != nil || document == nil {
+			return nil, err
+		}
+
+		model := &modelpkg.User{}
+		if err = bson.Unmarshal(document, model); err != nil {
+			return nil, err
+		}
+
+		return model, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if model == nil {
+		return nil, dao.dao.notFound()
+	}
+
+	if err = dao.dao.afterFind(ctx, model); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// FindOneByID returns a model for one document in the collection.
+func (dao *UserMemory) FindOneByID(ctx context.Context, id string, optionsFunc ...UserFindOneOptionsFunc) (*modelpkg.User, error) {
+	objectID, err := ParseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.FindOne(ctx, func(cols *UserColumns) interface{} {
+		return bson.M{"_id": objectID}
+	}, optionsFunc...)
+}
+
+// FindMany returns the models of the matching documents in the collection.
+func (dao *UserMemory) FindMany(ctx context.Context, filterFunc UserFilterFunc, optionsFunc ...UserFindManyOptionsFunc) ([]*modelpkg.User, error) {
+	var (
+		opts   *options.FindOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	models, err := Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpFindMany, Filter: filter}, func(ctx context.Context, op OpInfo) ([]*modelpkg.User, error) {
+		return dao.find(op.Filter, dao.dao.withDefaultSort(opts))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = dao.dao.afterFind(ctx, models...); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+// FindManyByIDs returns the models matching the ids in the same order as the ids.
+// Ids that do not match any document are skipped and repeated ids are returned only once.
+func (dao *UserMemory) FindManyByIDs(ctx context.Context, ids []string, optionsFunc ...UserFindManyOptionsFunc) ([]*modelpkg.User, error) {
+	objectIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := ParseObjectID(id)
+		if err != nil {
+			return nil, err
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+
+	if len(objectIDs) == 0 {
+		return make([]*modelpkg.User, 0), nil
+	}
+
+	var opts *options.FindOptions
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	found, err := Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpFindMany, Filter: dao.scoped(bson.M{"_id": bson.M{"$in": objectIDs}})}, func(ctx context.Context, op OpInfo) (map[primitive.ObjectID]*modelpkg.User, error) {
+		documents, err := dao.Collection.Find(op.Filter, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		found := make(map[primitive.ObjectID]*modelpkg.User, len(objectIDs))
+		for _, document := range documents {
+			objectID, ok := document.Lookup("_id").ObjectIDOK()
+			if !ok {
+				continue
+			}
+
+			model := &modelpkg.User{}
+			if err = bson.Unmarshal(document, model); err != nil {
+				return nil, err
+			}
+			found[objectID] = model
+		}
+
+		return found, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]*modelpkg.User, 0, len(found))
+	for _, objectID := range objectIDs {
+		if model, ok := found[objectID]; ok {
+			models = append(models, model)
+			delete(found, objectID)
+		}
+	}
+
+	if err = dao.dao.afterFind(ctx, models...); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+// DeleteOne deletes at most one document from the collection.
+// The document is marked as deleted instead of being removed when the model is soft deleted.
+func (dao *UserMemory) DeleteOne(ctx context.Context, filterFunc UserFilterFunc, optionsFunc ...UserDeleteOptionsFunc) (*mongo.DeleteResult, error) {
+	filter := dao.scoped(filterFunc(dao.Columns))
+
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpDeleteOne, Filter: filter}, func(ctx context.Context, op OpInfo) (*mongo.DeleteResult, error) {
+		return dao.Collection.DeleteOne(op.Filter)
+	})
+}
+
+// DeleteOneByID deletes at most one document from the collection.
+func (dao *UserMemory) DeleteOneByID(ctx context.Context, id string, optionsFunc ...UserDeleteOptionsFunc) (*mongo.DeleteResult, error) {
+	objectID, err := ParseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.DeleteOne(ctx, func(cols *UserColumns) interface{} {
+		return bson.M{"_id": objectID}
+	}, optionsFunc...)
+}
+
+// DeleteMany deletes documents from the collection.
+// The documents are marked as deleted instead of being removed when the model is soft deleted.
+func (dao *UserMemory) DeleteMany(ctx context.Context, filterFunc UserFilterFunc, optionsFunc ...UserDeleteOptionsFunc) (*mongo.DeleteResult, error) {
+	filter := dao.scoped(filterFunc(dao.Columns))
+
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: OpDeleteMany, Filter: filter}, func(ctx context.Context, op OpInfo) (*mongo.DeleteResult, error) {
+		return dao.Collection.DeleteMany(op.Filter)
+	})
+}
+
+// update the documents through the interceptors
+func (dao *UserMemory) update(ctx context.Context, kind OpKind, filterFunc UserFilterFunc, version int64, updateFunc UserUpdateFunc, optionsFunc ...UserUpdateOptionsFunc) (*mongo.UpdateResult, error) {
+	var (
+		opts   *options.UpdateOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	update, err := dao.dao.prepareUpdate(ctx, updateFunc(dao.Columns))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	if opts != nil && opts.Upsert != nil && *opts.Upsert {
+		return nil, ErrVersionUpsert
+	}
+
+	filter = AndFilter(filter, bson.D{{Key: "version", Value: version}})
+
+	upsert := opts != nil && opts.Upsert != nil && *opts.Upsert
+
+	return Invoke(ctx, OpInfo{Collection: dao.Collection.Name(), Operation: kind, Filter: filter, Update: update}, func(ctx context.Context, op OpInfo) (*mongo.UpdateResult, error) {
+		apply := dao.Collection.UpdateMany
+		if kind == OpUpdateOne {
+			apply = dao.Collection.UpdateOne
+		}
+
+		result, err := apply(op.Filter, op.Update, upsert)
+
+		if err == nil && result.MatchedCount == 0 {
+			return nil, ErrVersionConflict
+		}
+
+		return result, err
+	})
+}
+
+// find the documents and decode them into the models
+func (dao *UserMemory) find(filter interface{}, opts *options.FindOptions) ([]*modelpkg.User, error) {
+	documents, err := dao.Collection.Find(filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]*modelpkg.User, 0, len(documents))
+	for _, document := range documents {
+		model := &modelpkg.User{}
+		if err = bson.Unmarshal(document, model); err != nil {
+			return nil, err
+		}
+		models = append(models, model)
+	}
+
+	return models, nil
+}
+
+// apply the soft delete scope of the dao to the filter
+func (dao *UserMemory) scoped(filter interface{}) interface{} {
+	return userSoftDelete.Apply(filter, dao.scope)
+}
+
+// autofill when inserting data, the autoIncr fields are filled by the local counters of the collection
+func (dao *UserMemory) autofill(ctx context.Context, model *modelpkg.User) error {
+	if model.ID.IsZero() {
+		model.ID = primitive.NewObjectID()
+	}
+
+	if model.UID == 0 {
+		if id, err := dao.Collection.Incr("uid"); err != nil {
+			return err
+		} else {
+			model.UID = int32(id)
+		}
+	}
+
+	if model.RegisterTime == 0 {
+		model.RegisterTime = primitive.NewDateTimeFromTime(time.Now())
+	}
+
+	if model.LastLoginTime == 0 {
+		model.LastLoginTime = primitive.NewDateTimeFromTime(time.Now())
+	}
+
+	return nil
+}
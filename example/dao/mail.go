@@ -1,16 +1,41 @@
 package dao
 
 import (
+	"context"
 	"github.com/dobyte/mongo-dao-generator/example/dao/internal"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type MailColumns = internal.MailColumns
 
+type MailFilter = internal.MailFilter
+
+type MailUpdate = internal.MailUpdate
+
+type MailSort = internal.MailSort
+
+type MailChangeEvent = internal.MailChangeEvent
+
+type MailChangeStream = internal.MailChangeStream
+
+type MailRepository = internal.MailRepository
+
 type Mail struct {
 	*internal.Mail
 }
 
+var _ MailRepository = (*Mail)(nil)
+
 func NewMail(db *mongo.Database) *Mail {
 	return &Mail{Mail: internal.NewMail(db)}
 }
+
+// MailFindOneAs executes a find command and decodes one document into the partial struct P.
+func MailFindOneAs[P any](ctx context.Context, dao *Mail, filterFunc internal.MailFilterFunc, optionsFunc ...internal.MailFindOneOptionsFunc) (*P, error) {
+	return internal.MailFindOneAs[P](ctx, dao.Mail, filterFunc, optionsFunc...)
+}
+
+// MailFindManyAs executes a find command and decodes the matching documents into the partial struct P.
+func MailFindManyAs[P any](ctx context.Context, dao *Mail, filterFunc internal.MailFilterFunc, optionsFunc ...internal.MailFindManyOptionsFunc) ([]*P, error) {
+	return internal.MailFindManyAs[P](ctx, dao.Mail, filterFunc, optionsFunc...)
+}
@@ -0,0 +1,18 @@
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+package dao
+
+import (
+	"github.com/dobyte/mongo-dao-generator/example/dao/internal"
+)
+
+type MailMemory = internal.MailMemory
+
+// NewMailMemory creates an in-memory implementation of MailRepository for the unit tests without a database.
+func NewMailMemory() *MailMemory {
+	return internal.NewMailMemory()
+}
@@ -0,0 +1,20 @@
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+//go:build go1.21
+
+package dao
+
+import (
+	"github.com/dobyte/mongo-dao-generator/example/dao/internal"
+	"log/slog"
+)
+
+// SlowQueryLogger returns an interceptor which logs the operations taking at least the threshold of the options,
+// register it with Use. The values of the sensitive columns never appear in the logs.
+func SlowQueryLogger(logger *slog.Logger, opts SlowQueryOptions) Interceptor {
+	return internal.SlowQueryLogger(logger, opts)
+}
@@ -1,16 +1,41 @@
 package dao
 
 import (
+	"context"
 	"github.com/dobyte/mongo-dao-generator/example/dao/internal"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type UserColumns = internal.UserColumns
 
+type UserFilter = internal.UserFilter
+
+type UserUpdate = internal.UserUpdate
+
+type UserSort = internal.UserSort
+
+type UserChangeEvent = internal.UserChangeEvent
+
+type UserChangeStream = internal.UserChangeStream
+
+type UserRepository = internal.UserRepository
+
 type User struct {
 	*internal.User
 }
 
+var _ UserRepository = (*User)(nil)
+
 func NewUser(db *mongo.Database) *User {
 	return &User{User: internal.NewUser(db)}
 }
+
+// UserFindOneAs executes a find command and decodes one document into the partial struct P.
+func UserFindOneAs[P any](ctx context.Context, dao *User, filterFunc internal.UserFilterFunc, optionsFunc ...internal.UserFindOneOptionsFunc) (*P, error) {
+	return internal.UserFindOneAs[P](ctx, dao.User, filterFunc, optionsFunc...)
+}
+
+// UserFindManyAs executes a find command and decodes the matching documents into the partial struct P.
+func UserFindManyAs[P any](ctx context.Context, dao *User, filterFunc internal.UserFilterFunc, optionsFunc ...internal.UserFindManyOptionsFunc) ([]*P, error) {
+	return internal.UserFindManyAs[P](ctx, dao.User, filterFunc, optionsFunc...)
+}
@@ -0,0 +1,40 @@
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+package model
+
+import (
+	"fmt"
+)
+
+// Values returns the values of the constants declared for Gender.
+func (Gender) Values() []Gender {
+	return []Gender{GenderUnknown, GenderMale, GenderFemale}
+}
+
+// IsValid reports whether the value is one of the constants declared for Gender.
+func (g Gender) IsValid() bool {
+	switch g {
+	case GenderUnknown, GenderMale, GenderFemale:
+		return true
+	default:
+		return false
+	}
+}
+
+// String returns the description of the value from the comment of the constant.
+func (g Gender) String() string {
+	switch g {
+	case GenderUnknown:
+		return "未知"
+	case GenderMale:
+		return "男性"
+	case GenderFemale:
+		return "女性"
+	default:
+		return fmt.Sprintf("Gender(%v)", int(g))
+	}
+}
@@ -3,12 +3,14 @@ package model
 import "go.mongodb.org/mongo-driver/bson/primitive"
 
 //go:generate mongo-dao-generator -model-dir=. -model-names=Mail -dao-dir=../dao/
+//gen:sort -SendTime
 type Mail struct {
-    ID       primitive.ObjectID `bson:"_id" gen:"autoFill"`       // 邮件ID
-    Title    string             `bson:"title"`                    // 邮件标题
-    Content  string             `bson:"content"`                  // 邮件内容
-    Sender   int64              `bson:"sender"`                   // 邮件发送者
-    Receiver int64              `bson:"receiver"`                 // 邮件接受者
-    Status   int                `bson:"status"`                   // 邮件状态
-    SendTime primitive.DateTime `bson:"send_time" gen:"autoFill"` // 发送时间
-}
\ No newline at end of file
+	ID        primitive.ObjectID `bson:"_id" gen:"autoFill"`                                   // 邮件ID
+	Title     string             `bson:"title" gen:"required;len=1..64"`                       // 邮件标题
+	Content   string             `bson:"content"`                                              // 邮件内容
+	Sender    int64              `bson:"sender"`                                               // 邮件发送者
+	Receiver  int64              `bson:"receiver" gen:"index:name=idx_recv_status,group=recv"` // 邮件接受者
+	Status    int                `bson:"status" gen:"index:group=recv"`                        // 邮件状态
+	SendTime  primitive.DateTime `bson:"send_time" gen:"autoFill"`                             // 发送时间
+	DeletedAt primitive.DateTime `bson:"deleted_at" gen:"softDelete"`                          // 删除时间
+}
@@ -0,0 +1,38 @@
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+package model
+
+import (
+	"fmt"
+)
+
+// Values returns the values of the constants declared for Status.
+func (Status) Values() []Status {
+	return []Status{StatusNormal, StatusForbidden}
+}
+
+// IsValid reports whether the value is one of the constants declared for Status.
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusNormal, StatusForbidden:
+		return true
+	default:
+		return false
+	}
+}
+
+// String returns the description of the value from the comment of the constant.
+func (s Status) String() string {
+	switch s {
+	case StatusNormal:
+		return "正常"
+	case StatusForbidden:
+		return "封禁"
+	default:
+		return fmt.Sprintf("Status(%v)", int(s))
+	}
+}
@@ -0,0 +1,42 @@
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+package model
+
+import (
+	"fmt"
+)
+
+// Values returns the values of the constants declared for Type.
+func (Type) Values() []Type {
+	return []Type{TypeRobot, TypeGuest, TypeGeneral, TypeSystem}
+}
+
+// IsValid reports whether the value is one of the constants declared for Type.
+func (t Type) IsValid() bool {
+	switch t {
+	case TypeRobot, TypeGuest, TypeGeneral, TypeSystem:
+		return true
+	default:
+		return false
+	}
+}
+
+// String returns the description of the value from the comment of the constant.
+func (t Type) String() string {
+	switch t {
+	case TypeRobot:
+		return "机器人用户"
+	case TypeGuest:
+		return "游客用户"
+	case TypeGeneral:
+		return "普通用户"
+	case TypeSystem:
+		return "系统用户"
+	default:
+		return fmt.Sprintf("Type(%v)", int(t))
+	}
+}
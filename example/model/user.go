@@ -31,26 +31,27 @@ const (
 //go:generate mongo-dao-generator -model-dir=. -model-names=User -dao-dir=../dao/
 type User struct {
 	ID             primitive.ObjectID `bson:"_id" gen:"autoFill"`
-	UID            int32              `bson:"uid" gen:"autoIncr:uid"`         // 用户ID
-	Account        string             `bson:"account"`                        // 用户账号
-	Password       string             `bson:"password"`                       // 用户密码
-	Salt           string             `bson:"salt"`                           // 密码
-	Mobile         string             `bson:"mobile"`                         // 用户手机
-	Email          string             `bson:"email"`                          // 用户邮箱
-	Nickname       string             `bson:"nickname"`                       // 用户昵称
-	Signature      string             `bson:"signature"`                      // 用户签名
-	Gender         Gender             `bson:"gender"`                         // 用户性别
-	Level          int                `bson:"level"`                          // 用户等级
-	Experience     int                `bson:"experience"`                     // 用户经验
-	Coin           int                `bson:"coin"`                           // 用户金币
-	Type           Type               `bson:"type"`                           // 用户类型
-	Status         Status             `bson:"status"`                         // 用户状态
-	DeviceID       string             `bson:"device_id"`                      // 设备ID
-	ThirdPlatforms ThirdPlatforms     `bson:"third_platforms"`                // 第三方平台
-	RegisterIP     string             `bson:"register_ip"`                    // 注册IP
-	RegisterTime   primitive.DateTime `bson:"register_time" gen:"autoFill"`   // 注册时间
-	LastLoginIP    string             `bson:"last_login_ip"`                  // 最近登录IP
-	LastLoginTime  primitive.DateTime `bson:"last_login_time" gen:"autoFill"` // 最近登录时间
+	UID            int32              `bson:"uid" gen:"autoIncr:uid;unique"`           // 用户ID
+	Account        string             `bson:"account" gen:"unique;required;len=4..32"` // 用户账号
+	Password       string             `bson:"password" gen:"sensitive"`                // 用户密码
+	Salt           string             `bson:"salt" gen:"sensitive"`                    // 密码
+	Mobile         string             `bson:"mobile,omitempty" gen:"unique:partial"`   // 用户手机
+	Email          string             `bson:"email" gen:"email"`                       // 用户邮箱
+	Nickname       string             `bson:"nickname"`                                // 用户昵称
+	Signature      string             `bson:"signature"`                               // 用户签名
+	Gender         Gender             `bson:"gender"`                                  // 用户性别
+	Level          int                `bson:"level" gen:"min=0"`                       // 用户等级
+	Experience     int                `bson:"experience"`                              // 用户经验
+	Coin           int                `bson:"coin"`                                    // 用户金币
+	Type           Type               `bson:"type"`                                    // 用户类型
+	Status         Status             `bson:"status"`                                  // 用户状态
+	DeviceID       string             `bson:"device_id"`                               // 设备ID
+	ThirdPlatforms ThirdPlatforms     `bson:"third_platforms"`                         // 第三方平台
+	RegisterIP     string             `bson:"register_ip"`                             // 注册IP
+	RegisterTime   primitive.DateTime `bson:"register_time" gen:"autoFill"`            // 注册时间
+	LastLoginIP    string             `bson:"last_login_ip"`                           // 最近登录IP
+	LastLoginTime  primitive.DateTime `bson:"last_login_time" gen:"autoFill"`          // 最近登录时间
+	Version        int64              `bson:"version" gen:"version"`                   // 数据版本
 }
 
 // ThirdPlatforms 第三方平台
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"github.com/dobyte/mongo-dao-generator/template"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+const pkgAtomic = "sync/atomic"
+
+// the With methods of the factory overriding each field of the model
+func (m *model) factoryMethods(replaces map[string]string) string {
+	methods := make([]string, 0, len(m.fields))
+	for _, f := range m.fields {
+		if f.typeName == "interface{}" {
+			continue
+		}
+
+		fieldReplaces := make(map[string]string, len(replaces)+2)
+		for k, v := range replaces {
+			fieldReplaces[k] = v
+		}
+		fieldReplaces[varFieldNameKey] = f.name
+		fieldReplaces[varFieldTypeKey] = f.typeName
+
+		methods = append(methods, strings.TrimPrefix(doExpand(template.FactoryFieldTemplate, fieldReplaces), "\n"))
+	}
+
+	m.addImport(pkgAtomic)
+
+	return strings.Join(methods, "\n\n")
+}
+
+// the statements of the factory setting the default values of the fields, which satisfy the validation rules where possible
+func (m *model) factoryDefaults() string {
+	statements := make([]string, 0, len(m.fields))
+	for _, f := range m.fields {
+		if value := m.factoryDefault(f); value != "" {
+			statements = append(statements, fmt.Sprintf("model.%s = %s", f.name, value))
+		}
+	}
+
+	return strings.Join(statements, "\n\t")
+}
+
+// the go expression of the default value of the field, empty if the field is left to the zero value
+func (m *model) factoryDefault(f *field) string {
+	if f.autoFill != 0 || f == m.version || (m.softDelete != nil && m.softDelete.column == f.column) || f.typeName == "interface{}" {
+		return ""
+	}
+
+	if f.enum != nil && len(f.enum.consts) > 0 {
+		return m.modelPkgName + "." + f.enum.consts[0].name
+	}
+
+	if len(f.rules.oneof) > 0 {
+		return f.rules.oneofLiterals(f)[0]
+	}
+
+	switch f.typeName {
+	case "primitive.ObjectID":
+		return "primitive.NewObjectID()"
+	case "primitive.DateTime":
+		m.addImport(pkg1)
+		return "primitive.NewDateTimeFromTime(time.Now())"
+	case "time.Time":
+		return "time.Now()"
+	}
+
+	b, ok := f.typ.Underlying().(*types.Basic)
+	if !ok {
+		return ""
+	}
+
+	var value string
+
+	switch {
+	case b.Info()&types.IsString != 0:
+		if f.rules.pattern != "" {
+			return ""
+		}
+
+		if f.rules.email {
+			value = fmt.Sprintf("fmt.Sprintf(%s, seq)", strconv.Quote(f.column+"%d@example.com"))
+		} else {
+			value = fmt.Sprintf("fmt.Sprintf(%s, seq)", strconv.Quote(f.column+"-%d"))
+		}
+
+		if f.rules.lenMin != "" || f.rules.lenMax != "" {
+			value = fmt.Sprintf("%sFixtureString(%s, %s, %s)", m.commonPrefix, value, lenBound(f.rules.lenMin), lenBound(f.rules.lenMax))
+		}
+	case b.Info()&types.IsBoolean != 0:
+		if !f.rules.required {
+			return ""
+		}
+		return "true"
+	case b.Info()&types.IsNumeric != 0:
+		switch {
+		case f.rules.min != "":
+			if n, err := strconv.ParseFloat(f.rules.min, 64); err == nil && n == 0 {
+				return ""
+			}
+			return f.rules.min
+		case f.rules.required && f.rules.max != "":
+			return f.rules.max
+		case f.rules.required:
+			return "1"
+		default:
+			return ""
+		}
+	default:
+		return ""
+	}
+
+	if f.typeName != "string" {
+		value = fmt.Sprintf("%s(%s)", f.typeName, value)
+	}
+
+	return value
+}
+
+// the bound of the len rule passed to FixtureString, -1 means no bound
+func lenBound(bound string) string {
+	if bound == "" {
+		return "-1"
+	}
+
+	return bound
+}
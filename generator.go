@@ -5,11 +5,13 @@ import (
 	"github.com/dobyte/mongo-dao-generator/template"
 	"go/ast"
 	"go/token"
+	"go/types"
 	"golang.org/x/tools/go/packages"
 	"log"
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -36,10 +38,57 @@ const (
 	varDaoPrefixNameKey        = "VarDaoPrefixName"
 	varCollectionNameKey       = "VarCollectionName"
 	varAutofillCodeKey         = "VarAutofillCode"
+	varModelFilterDefineKey    = "VarModelFilterDefine"
+	varModelFilterInstanceKey  = "VarModelFilterInstance"
+	varModelUpdateDefineKey    = "VarModelUpdateDefine"
+	varModelUpdateInstanceKey  = "VarModelUpdateInstance"
+	varModelSortDefineKey      = "VarModelSortDefine"
+	varModelSortInstanceKey    = "VarModelSortInstance"
+	varModelDefaultSortKey     = "VarModelDefaultSort"
+	varModelIndexesKey         = "VarModelIndexes"
+	varModelSchemaKey          = "VarModelSchema"
+	varModelValidatorsKey      = "VarModelValidators"
+	varModelValidateCodeKey    = "VarModelValidateCode"
+	varEnumMethodsKey          = "VarEnumMethods"
+	varModelSoftDeleteKey      = "VarModelSoftDelete"
+	varSoftDeleteMethodsKey    = "VarSoftDeleteMethods"
+	varDeleteOneCodeKey        = "VarDeleteOneCode"
+	varDeleteManyCodeKey       = "VarDeleteManyCode"
+	varIncVersionCodeKey       = "VarIncVersionCode"
+	varReplaceCodeKey          = "VarReplaceCode"
+	varVersionParamKey         = "VarVersionParam"
+	varVersionArgKey           = "VarVersionArg"
+	varVersionFilterCodeKey    = "VarVersionFilterCode"
+	varVersionConflictCodeKey  = "VarVersionConflictCode"
+	varBeforeInsertCodeKey     = "VarBeforeInsertCode"
+	varAfterInsertCodeKey      = "VarAfterInsertCode"
+	varBeforeUpdateCodeKey     = "VarBeforeUpdateCode"
+	varBeforeUpdateDocCodeKey  = "VarBeforeUpdateDocumentCode"
+	varAfterFindCodeKey        = "VarAfterFindCode"
+	varModelSensitiveKey       = "VarModelSensitive"
+	varModelFieldNamesKey      = "VarModelFieldNames"
+	varNotFoundCodeKey         = "VarNotFoundCode"
+	varFindOneNotFoundDocKey   = "VarFindOneNotFoundDoc"
+	varRepositoryMethodsKey    = "VarRepositoryMethods"
+	varCommonPrefixKey         = "VarCommonPrefix"
+	varMemoryAutofillCodeKey   = "VarMemoryAutofillCode"
+	varMemorySoftDeleteKey     = "VarMemorySoftDeleteMethods"
+	varMemoryDeleteOneKey      = "VarMemoryDeleteOneCode"
+	varMemoryDeleteManyKey     = "VarMemoryDeleteManyCode"
+	varFactoryMethodsKey       = "VarFactoryMethods"
+	varFactoryDefaultsKey      = "VarFactoryDefaults"
+	varFieldNameKey            = "VarFieldName"
+	varFieldTypeKey            = "VarFieldType"
+	varTestAutofillChecksKey   = "VarTestAutofillChecks"
 )
 
 const defaultCounterName = "Counter"
 
+const (
+	directivePrefix = "//gen:"
+	directiveSort   = "sort"
+)
+
 type options struct {
 	modelDir      string
 	modelPkgPath  string
@@ -51,12 +100,25 @@ type options struct {
 	subPkgStyle   style
 	counterName   string
 	fileNameStyle style
+	otelEnable    bool
+	notFoundError bool
+	fixtures      bool
+	tests         bool
+	dryRun        bool
+	prune         bool
 }
 
 type generator struct {
 	opts       *options
 	counter    *counter
+	common     *common
+	enums      enums
 	modelNames map[string]struct{}
+	declared   map[string]struct{} // the struct types declared by the model package
+	manifest   *manifest
+	files      map[string]string
+	owners     map[string][]string
+	owner      string // the model whose files are being generated
 }
 
 func newGenerator(opts *options) *generator {
@@ -71,26 +133,105 @@ func newGenerator(opts *options) *generator {
 		log.Fatalf("error: %d model type names found", len(modelNames))
 	}
 
+	if err := checkFileNames(modelNames, opts); err != nil {
+		log.Fatal(err)
+	}
+
 	if opts.counterName == "" {
 		opts.counterName = defaultCounterName
 	}
 
+	// the generated tests build their models with the factories
+	if opts.tests {
+		opts.fixtures = true
+	}
+
 	return &generator{
 		opts:       opts,
 		counter:    newCounter(opts),
+		common:     newCommon(opts),
 		modelNames: modelNames,
+		files:      make(map[string]string),
+		owners:     make(map[string][]string),
 	}
 }
 
+// check that the files generated for the models overwrite neither each other nor the shared files of the dao package
+func checkFileNames(modelNames map[string]struct{}, opts *options) error {
+	names := make([]string, 0, len(modelNames))
+	for modelName := range modelNames {
+		names = append(names, modelName)
+	}
+
+	sort.Strings(names)
+
+	files := make(map[string]string)
+	if !opts.subPkgEnable {
+		for _, name := range []string{defaultCommonName, defaultMemoryName, defaultSlowLogName, defaultFixtureName, defaultTelemetryName} {
+			files[name] = fmt.Sprintf("the %s dao file", name)
+		}
+	}
+
+	for _, modelName := range names {
+		if strings.HasSuffix(toFileName(modelName, opts.fileNameStyle), "_test") {
+			return fmt.Errorf("error: model type name %s conflicts with the test files", modelName)
+		}
+
+		// the daos of the sub packages are generated into their own directories
+		if opts.subPkgEnable {
+			continue
+		}
+
+		for _, name := range []string{modelName, modelName + "Memory", modelName + "Factory"} {
+			file := toFileName(name, opts.fileNameStyle)
+			if owner, ok := files[file]; ok {
+				return fmt.Errorf("error: model type name %s conflicts with %s", modelName, owner)
+			}
+			files[file] = fmt.Sprintf("the dao files of model type name %s", modelName)
+		}
+	}
+
+	return nil
+}
+
 func (g *generator) makeDao() {
 	models := g.parseModels()
 
+	var err error
+	if g.manifest, err = loadManifest(filepath.Join(g.common.daoOutputDir, defaultManifestName)); err != nil {
+		log.Fatal(err)
+	}
+
+	g.makeCommonInternalDao()
+
+	g.makeCommonExternalDao()
+
+	g.makeTelemetry()
+
+	g.makeSlowQueryLogger()
+
+	g.makeMemoryCollection()
+
+	g.makeFixtures()
+
+	g.makeEnums()
+
 	for _, m := range models {
+		g.owner = m.modelName
+
 		g.makeModelInternalDao(m)
 
 		g.makeModelExternalDao(m)
 
-		fmt.Printf("%s's dao file generated successfully\n", m.modelName)
+		g.makeModelMemoryDao(m)
+
+		g.makeModelFactory(m)
+
+		g.makeModelTest(m)
+
+		if !g.opts.dryRun {
+			fmt.Printf("%s's dao file generated successfully\n", m.modelName)
+		}
 
 		if !m.isDependCounter {
 			continue
@@ -100,6 +241,12 @@ func (g *generator) makeDao() {
 
 		g.makeCounterExternalDao()
 	}
+
+	g.owner = ""
+
+	if err = g.finishManifest(); err != nil {
+		log.Fatal(err)
+	}
 }
 
 // generate an internal dao file based on model
@@ -116,11 +263,55 @@ func (g *generator) makeModelInternalDao(m *model) {
 	replaces[varModelColumnsDefineKey] = m.modelColumnsDefined()
 	replaces[varModelColumnsInstanceKey] = m.modelColumnsInstance()
 	replaces[varAutofillCodeKey] = m.autoFillCode()
+	replaces[varModelFilterDefineKey] = m.modelFilterDefined()
+	replaces[varModelFilterInstanceKey] = m.modelFilterInstance()
+	replaces[varModelUpdateDefineKey] = m.modelUpdateDefined()
+	replaces[varModelUpdateInstanceKey] = m.modelUpdateInstance()
+	replaces[varModelSortDefineKey] = m.modelSortDefined()
+	replaces[varModelSortInstanceKey] = m.modelSortInstance()
+	replaces[varModelDefaultSortKey] = m.modelDefaultSort()
+	replaces[varModelIndexesKey] = m.modelIndexes()
+	replaces[varModelSchemaKey] = m.modelSchema()
+	replaces[varModelValidatorsKey] = m.modelValidators()
+	replaces[varModelValidateCodeKey] = m.modelValidateCode()
+	replaces[varCommonPrefixKey] = m.commonPrefix
+	replaces[varModelSoftDeleteKey] = m.modelSoftDelete()
+	replaces[varSoftDeleteMethodsKey] = m.softDeleteMethods(template.SoftDeleteTemplate, replaces)
+	replaces[varDeleteOneCodeKey] = m.deleteCode("DeleteOne")
+	replaces[varDeleteManyCodeKey] = m.deleteCode("DeleteMany")
+	replaces[varIncVersionCodeKey] = m.incVersionCode()
+	replaces[varReplaceCodeKey] = m.replaceCode()
+	replaces[varVersionParamKey] = m.versionParam()
+	replaces[varVersionArgKey] = m.versionArg()
+	replaces[varVersionFilterCodeKey] = m.versionFilterCode()
+	replaces[varVersionConflictCodeKey] = m.versionConflictCode()
+	replaces[varRepositoryMethodsKey] = m.repositoryMethods(replaces)
+	replaces[varBeforeInsertCodeKey] = m.beforeInsertCode()
+	replaces[varAfterInsertCodeKey] = m.afterInsertCode()
+	replaces[varBeforeUpdateCodeKey] = m.beforeUpdateCode()
+	replaces[varBeforeUpdateDocCodeKey] = m.beforeUpdateDocumentCode()
+	replaces[varAfterFindCodeKey] = m.afterFindCode()
+	replaces[varModelSensitiveKey] = m.modelSensitive()
+	replaces[varModelFieldNamesKey] = m.modelFieldNames()
+	replaces[varNotFoundCodeKey] = m.notFoundCode()
+	replaces[varFindOneNotFoundDocKey] = m.findOneNotFoundDoc()
+	replaces[varMemoryAutofillCodeKey] = m.memoryAutoFillCode()
+	replaces[varMemorySoftDeleteKey] = m.memorySoftDeleteMethods(replaces)
+	replaces[varMemoryDeleteOneKey] = m.memoryDeleteCode("DeleteOne")
+	replaces[varMemoryDeleteManyKey] = m.memoryDeleteCode("DeleteMany")
+
+	tpl := template.InternalTemplate + template.MemoryTemplate
+	if g.opts.fixtures {
+		replaces[varFactoryMethodsKey] = m.factoryMethods(replaces)
+		replaces[varFactoryDefaultsKey] = m.factoryDefaults()
+		tpl += template.FactoryTemplate
+	}
+
 	replaces[varPackagesKey] = m.packages()
 
 	file := m.daoOutputDir + "/internal/" + m.daoOutputFile
 
-	err := doWrite(file, template.InternalTemplate, replaces)
+	err := g.doWrite(file, tpl, replaces)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -136,11 +327,33 @@ func (g *generator) makeModelExternalDao(m *model) {
 		case os.IsNotExist(err):
 		// ignore
 		case os.IsExist(err):
+			g.doKeep(file)
 			return
 		default:
 			log.Fatal(err)
 		}
 	} else {
+		g.doKeep(file)
+		return
+	}
+
+	replaces := make(map[string]string)
+	replaces[varDaoClassNameKey] = m.daoClassName
+	replaces[varDaoPrefixNameKey] = m.daoPrefixName
+	replaces[varDaoPackageNameKey] = m.daoPkgName
+	replaces[varDaoPackagePathKey] = m.daoPkgPath
+
+	err = g.doWrite(file, template.ExternalTemplate, replaces)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate an external file exposing the in-memory dao of the model, which is always overwritten
+func (g *generator) makeModelMemoryDao(m *model) {
+	file := m.daoOutputDir + "/" + toFileName(m.modelName+"Memory", m.opts.fileNameStyle) + ".go"
+
+	if !g.writable(file) {
 		return
 	}
 
@@ -150,7 +363,51 @@ func (g *generator) makeModelExternalDao(m *model) {
 	replaces[varDaoPackageNameKey] = m.daoPkgName
 	replaces[varDaoPackagePathKey] = m.daoPkgPath
 
-	err = doWrite(file, template.ExternalTemplate, replaces)
+	err := g.doWrite(file, template.MemoryExternalTemplate, replaces)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate an external file exposing the factory of the model when the fixtures are enabled
+// The factory of a former generation is left to the stale files of the manifest, which are only removed with -prune.
+func (g *generator) makeModelFactory(m *model) {
+	file := m.daoOutputDir + "/" + toFileName(m.modelName+"Factory", m.opts.fileNameStyle) + ".go"
+
+	if !g.opts.fixtures || !g.writable(file) {
+		return
+	}
+
+	replaces := make(map[string]string)
+	replaces[varDaoPrefixNameKey] = m.daoPrefixName
+	replaces[varDaoPackageNameKey] = m.daoPkgName
+	replaces[varDaoPackagePathKey] = m.daoPkgPath
+
+	err := g.doWrite(file, template.FactoryExternalTemplate, replaces)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate a test of the dao next to the external dao file when the tests are enabled
+// The test of a former generation is left to the stale files of the manifest, which are only removed with -prune.
+func (g *generator) makeModelTest(m *model) {
+	file := m.daoOutputDir + "/" + toFileName(m.modelName, m.opts.fileNameStyle) + "_test.go"
+
+	if !g.opts.tests || !g.writable(file) {
+		return
+	}
+
+	replaces := make(map[string]string)
+	replaces[varModelClassNameKey] = m.modelClassName
+	replaces[varModelPackageNameKey] = m.modelPkgName
+	replaces[varDaoClassNameKey] = m.daoClassName
+	replaces[varDaoPrefixNameKey] = m.daoPrefixName
+	replaces[varDaoPackageNameKey] = m.daoPkgName
+	replaces[varTestAutofillChecksKey] = m.testAutofillChecks()
+	replaces[varPackagesKey] = m.testPackages()
+
+	err := g.doWrite(file, template.TestTemplate, replaces)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -163,11 +420,13 @@ func (g *generator) makeCounterInternalDao() {
 	replaces[varDaoPrefixNameKey] = g.counter.daoPrefixName
 	replaces[varDaoVariableNameKey] = g.counter.daoVariableName
 	replaces[varCollectionNameKey] = g.counter.collectionName
+	replaces[varCommonPrefixKey] = g.counter.commonPrefix()
+	replaces[varPackagesKey] = g.counter.packages()
 	replaces[symbolBacktickKey] = symbolBacktick
 
 	file := g.counter.daoOutputDir + "/internal/" + g.counter.daoOutputFile
 
-	err := doWrite(file, template.CounterInternalTemplate, replaces)
+	err := g.doWrite(file, template.CounterInternalTemplate, replaces)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -183,11 +442,13 @@ func (g *generator) makeCounterExternalDao() {
 		case os.IsNotExist(err):
 		// ignore
 		case os.IsExist(err):
+			g.doKeep(file)
 			return
 		default:
 			log.Fatal(err)
 		}
 	} else {
+		g.doKeep(file)
 		return
 	}
 
@@ -196,7 +457,137 @@ func (g *generator) makeCounterExternalDao() {
 	replaces[varDaoPackageNameKey] = g.counter.daoPkgName
 	replaces[varDaoPackagePathKey] = g.counter.daoPkgPath
 
-	err = doWrite(file, template.CounterExternalTemplate, replaces)
+	err = g.doWrite(file, template.CounterExternalTemplate, replaces)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// print the indexes declared by the models as mongo shell commands
+func (g *generator) printIndexes() {
+	for _, m := range g.parseModels() {
+		fmt.Printf("// %s\n", m.modelName)
+
+		for _, idx := range m.indexes {
+			fmt.Println(idx.shellCommand(m.collectionName))
+		}
+	}
+}
+
+// generate an internal dao file shared by all models
+func (g *generator) makeCommonInternalDao() {
+	file := g.common.daoOutputDir + "/internal/" + g.common.daoOutputFile
+
+	replaces := make(map[string]string)
+	replaces[symbolBacktickKey] = symbolBacktick
+	replaces[varDaoPackagePathKey] = g.common.daoRootPath
+
+	err := g.doWrite(file, template.CommonInternalTemplate, replaces)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate the OpenTelemetry interceptor into the shared internal package when the instrumentation is enabled
+// The interceptor of a former generation is left to the stale files of the manifest, which are only removed with -prune.
+func (g *generator) makeTelemetry() {
+	if !g.opts.otelEnable {
+		return
+	}
+
+	err := g.doWrite(g.common.daoOutputDir+"/internal/"+defaultTelemetryName+".go", template.TelemetryTemplate, make(map[string]string))
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate the in-memory collection shared by the in-memory daos of all models
+func (g *generator) makeMemoryCollection() {
+	err := g.doWrite(g.common.daoOutputDir+"/internal/"+defaultMemoryName+".go", template.MemoryCommonTemplate, make(map[string]string))
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate the helpers of the factories and the fixture files when the fixtures are enabled
+// The files of a former generation are left to the stale files of the manifest, which are only removed with -prune.
+func (g *generator) makeFixtures() {
+	if !g.opts.fixtures {
+		return
+	}
+
+	replaces := make(map[string]string)
+	replaces[varDaoPackageNameKey] = g.common.daoPkgName
+	replaces[varDaoPackagePathKey] = g.common.daoRootPath
+
+	err := g.doWrite(g.common.daoOutputDir+"/internal/"+defaultFixtureName+".go", template.FixtureInternalTemplate, replaces)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	file := g.common.daoOutputDir + "/" + defaultFixtureName + ".go"
+	if !g.writable(file) {
+		return
+	}
+
+	err = g.doWrite(file, template.FixtureExternalTemplate, replaces)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate the slow query logger, which requires log/slog and is only built by go1.21 or later
+func (g *generator) makeSlowQueryLogger() {
+	replaces := make(map[string]string)
+	replaces[varDaoPackageNameKey] = g.common.daoPkgName
+	replaces[varDaoPackagePathKey] = g.common.daoRootPath
+
+	err := g.doWrite(g.common.daoOutputDir+"/internal/"+defaultSlowLogName+".go", template.SlowLogInternalTemplate, replaces)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = g.doWrite(g.common.daoOutputDir+"/"+defaultSlowLogName+".go", template.SlowLogExternalTemplate, replaces)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate the methods of the enums referenced by the models into the model package
+func (g *generator) makeEnums() {
+	for _, e := range g.enums {
+		if len(e.models) == 0 || !g.writable(e.file) {
+			continue
+		}
+
+		methods := e.methods()
+		if methods == "" {
+			continue
+		}
+
+		replaces := make(map[string]string)
+		replaces[varModelPackageNameKey] = e.pkgName
+		replaces[varPackagesKey] = e.packages(methods)
+		replaces[varEnumMethodsKey] = methods
+
+		err := g.doWrite(e.file, template.EnumTemplate, replaces)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		g.own(e.file, e.models...)
+	}
+}
+
+// generate an external dao file which exposes the shared types to the callers of the dao package
+func (g *generator) makeCommonExternalDao() {
+	file := g.common.daoOutputDir + "/" + g.common.daoOutputFile
+
+	replaces := make(map[string]string)
+	replaces[varDaoPackageNameKey] = g.common.daoPkgName
+	replaces[varDaoPackagePathKey] = g.common.daoRootPath
+
+	err := g.doWrite(file, template.CommonExternalTemplate, replaces)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -212,7 +603,10 @@ func (g *generator) parseModels() []*model {
 		modelPkgName = g.opts.modelPkgAlias
 	)
 
-	if g.opts.daoPkgPath == "" && pkg.Module != nil {
+	g.enums = parseEnums(pkg, g.opts)
+	g.declared = parseStructNames(pkg)
+
+	if g.opts.daoPkgPath == "" && g.opts.daoDir != "" && pkg.Module != nil {
 		outPath, err := filepath.Abs(g.opts.daoDir)
 		if err != nil {
 			log.Fatal(err)
@@ -223,6 +617,7 @@ func (g *generator) parseModels() []*model {
 	daoPkgPath = strings.ReplaceAll(daoPkgPath, `\`, `/`)
 
 	g.counter.setDaoPkgPath(daoPkgPath)
+	g.common.setDaoPkgPath(daoPkgPath)
 
 	for _, file := range pkg.Syntax {
 		if g.opts.modelPkgPath == "" && pkg.Module != nil && pkg.Fset != nil {
@@ -260,8 +655,15 @@ func (g *generator) parseModels() []*model {
 				model.setModelPkg(modelPkgName, modelPkgPath)
 				model.setDaoPkgPath(daoPkgPath)
 
+				if g.opts.subPkgEnable {
+					model.addImport(g.common.daoPkgPath, defaultCommonPkgAlias)
+				}
+
 				for _, item := range st.Fields.List {
-					name := item.Names[0].Name
+					name := embeddedName(item.Type)
+					if len(item.Names) > 0 {
+						name = item.Names[0].Name
+					}
 
 					if !isExportable(name) {
 						continue
@@ -269,6 +671,16 @@ func (g *generator) parseModels() []*model {
 
 					field := &field{name: name, column: name}
 
+					if typ := pkg.TypesInfo.TypeOf(item.Type); typ != nil {
+						model.setFieldType(field, typ, pkg.Types)
+
+						if e := g.enums.lookup(typ); e != nil && field.typeName != "interface{}" {
+							field.enum = e
+							field.rules.enum = true
+							e.use(model.modelName)
+						}
+					}
+
 					if item.Tag != nil && len(item.Tag.Value) > 2 {
 						runes := []rune(item.Tag.Value)
 						if runes[0] != '`' || runes[len(runes)-1] != '`' {
@@ -277,10 +689,17 @@ func (g *generator) parseModels() []*model {
 
 						tag := reflect.StructTag(runes[1 : len(runes)-1])
 
-						if column := tag.Get("bson"); column != "" {
+						column, flags, _ := strings.Cut(tag.Get("bson"), ",")
+						if column == "-" || strings.Contains(","+flags+",", ",inline,") {
+							continue
+						}
+
+						if column != "" {
 							field.column = column
 						}
 
+						field.omitEmpty = strings.Contains(","+flags+",", ",omitempty,")
+
 						val, ok := tag.Lookup("gen")
 						if ok {
 							parts := strings.Split(val, ";")
@@ -289,7 +708,16 @@ func (g *generator) parseModels() []*model {
 									continue
 								}
 
-								switch eles := strings.SplitN(part, ":", 2); eles[0] {
+								switch eles := splitTagPart(part); eles[0] {
+								case "required", "min", "max", "len", "email", "oneof", "pattern":
+									var value string
+									if len(eles) == 2 {
+										value = eles[1]
+									}
+
+									if err := field.rules.set(field, eles[0], value); err != nil {
+										log.Fatalf("%v in field %s of model %s", err, name, spec.Name.Name)
+									}
 								case "autoFill":
 									expr, ok := item.Type.(*ast.SelectorExpr)
 									if !ok {
@@ -305,6 +733,39 @@ func (g *generator) parseModels() []*model {
 										model.addImport(pkg1)
 										model.addImport(pkg3)
 									}
+								case "index", "unique":
+									var expr string
+									if len(eles) == 2 {
+										expr = eles[1]
+									}
+
+									decl, err := parseIndexDecl(expr, eles[0] == "unique")
+									if err != nil {
+										log.Fatalf("%v in field %s of model %s", err, name, spec.Name.Name)
+									}
+
+									field.indexes = append(field.indexes, decl)
+								case "sensitive":
+									field.sensitive = true
+								case "version":
+									if err := model.setVersionField(field); err != nil {
+										log.Fatal(err)
+									}
+								case "softDelete":
+									if err := model.setSoftDeleteField(field); err != nil {
+										log.Fatal(err)
+									}
+								case "ttl":
+									if len(eles) != 2 {
+										log.Fatalf("error: missing ttl seconds in field %s of model %s", name, spec.Name.Name)
+									}
+
+									decl, err := parseTTLIndexDecl(eles[1])
+									if err != nil {
+										log.Fatalf("%v in field %s of model %s", err, name, spec.Name.Name)
+									}
+
+									field.indexes = append(field.indexes, decl)
 								case "autoIncr":
 									if len(eles) != 2 || eles[1] == "" {
 										continue
@@ -366,6 +827,31 @@ func (g *generator) parseModels() []*model {
 					model.addFields(field)
 				}
 
+				if err := model.setIndexes(); err != nil {
+					log.Fatal(err)
+				}
+
+				model.setSchema(g.enums)
+				model.setValidators()
+
+				if obj := pkg.TypesInfo.Defs[spec.Name]; obj != nil {
+					model.setHooks(obj.Type())
+				}
+
+				directives := parseDirectives(decl.Doc, spec.Doc)
+
+				if column, ok := directives[directiveSoftDelete]; ok {
+					if err := model.setSoftDeleteColumn(column); err != nil {
+						log.Fatal(err)
+					}
+				}
+
+				if expr, ok := directives[directiveSort]; ok {
+					if err := model.setDefaultSort(expr); err != nil {
+						log.Fatal(err)
+					}
+				}
+
 				models = append(models, model)
 			}
 
@@ -376,12 +862,63 @@ func (g *generator) parseModels() []*model {
 	return models
 }
 
+// split a part of the gen tag into the name and the value at the first colon or equal sign
+func splitTagPart(part string) []string {
+	if i := strings.IndexAny(part, ":="); i >= 0 {
+		return []string{part[:i], part[i+1:]}
+	}
+
+	return []string{part}
+}
+
+// parse the struct-level directives in the form of //gen:name value
+func parseDirectives(groups ...*ast.CommentGroup) map[string]string {
+	directives := make(map[string]string)
+
+	for _, group := range groups {
+		if group == nil {
+			continue
+		}
+
+		for _, comment := range group.List {
+			if !strings.HasPrefix(comment.Text, directivePrefix) {
+				continue
+			}
+
+			eles := strings.SplitN(strings.TrimPrefix(comment.Text, directivePrefix), " ", 2)
+			if len(eles) == 2 {
+				directives[eles[0]] = strings.TrimSpace(eles[1])
+			} else {
+				directives[eles[0]] = ""
+			}
+		}
+	}
+
+	return directives
+}
+
+// collect the names of the struct types declared by the package
+func parseStructNames(pkg *packages.Package) map[string]struct{} {
+	names := make(map[string]struct{})
+
+	for _, name := range pkg.Types.Scope().Names() {
+		if obj, ok := pkg.Types.Scope().Lookup(name).(*types.TypeName); ok {
+			if _, ok = obj.Type().Underlying().(*types.Struct); ok {
+				names[name] = struct{}{}
+			}
+		}
+	}
+
+	return names
+}
+
 func (g *generator) loadPackage() *packages.Package {
 	cfg := &packages.Config{
 		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports | packages.NeedTypes | packages.NeedTypesSizes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedModule,
+		Dir:   g.opts.modelDir,
 		Tests: false,
 	}
-	pkgs, err := packages.Load(cfg, g.opts.modelDir)
+	pkgs, err := packages.Load(cfg, ".")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,607 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"golang.org/x/tools/go/packages"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files of the generator tests")
+
+const (
+	corpusDir = "testdata/corpus"
+	testsDir  = "testdata/tests" // the hand-written tests of the generated daos, outside the corpus since their packages only exist after the generation
+	goldenDir = "testdata/golden"
+	goldenExt = ".golden"
+)
+
+// a run of the generator over a model package of the corpus
+type goldenCase struct {
+	name       string
+	modelDir   string
+	modelNames []string
+	opts       func(opts *options)
+}
+
+var goldenCases = []goldenCase{
+	{
+		name:       "basic",
+		modelDir:   "basic/model",
+		modelNames: []string{"User", "Mail"},
+	},
+	{
+		name:       "subpkg",
+		modelDir:   "basic/model",
+		modelNames: []string{"User", "Mail"},
+		opts: func(opts *options) {
+			opts.subPkgEnable = true
+		},
+	},
+	{
+		name:       "embedded",
+		modelDir:   "embedded/model",
+		modelNames: []string{"Profile"},
+		opts: func(opts *options) {
+			opts.notFoundError = true
+			opts.tests = true
+		},
+	},
+	{
+		name:       "styles",
+		modelDir:   "styles/model",
+		modelNames: []string{"UserProfile", "LoginRecord"},
+		opts: func(opts *options) {
+			opts.subPkgEnable = true
+			opts.subPkgStyle = underscoreCase
+			opts.fileNameStyle = kebabCase
+			opts.counterName = "id-counter"
+			opts.tests = true
+		},
+	},
+	{
+		name:       "otel",
+		modelDir:   "basic/model",
+		modelNames: []string{"Mail"},
+		opts: func(opts *options) {
+			opts.otelEnable = true
+		},
+	},
+}
+
+// TestGolden runs the generator over the corpus and compares the generated files with the golden files.
+// Run go test -run TestGolden -update to update the golden files after an intended change of the output.
+func TestGolden(t *testing.T) {
+	version = "(test)"
+
+	root := copyCorpus(t)
+
+	for _, c := range goldenCases {
+		t.Run(c.name, func(t *testing.T) {
+			daoDir := filepath.Join(root, c.name, "dao")
+
+			opts := &options{
+				modelDir:      filepath.Join(root, c.modelDir),
+				modelNames:    c.modelNames,
+				daoDir:        daoDir,
+				subPkgStyle:   kebabCase,
+				fileNameStyle: underscoreCase,
+			}
+			if c.opts != nil {
+				c.opts(opts)
+			}
+
+			newGenerator(opts).makeDao()
+
+			generated := generatedFiles(t, root, daoDir)
+			generated = append(generated, generatedFiles(t, root, filepath.Join(root, c.modelDir))...)
+
+			for _, file := range generated {
+				compareGolden(t, filepath.Join(goldenDir, c.name, file.name+goldenExt), file.data)
+			}
+
+			checkGoldenFiles(t, filepath.Join(goldenDir, c.name), generated)
+		})
+	}
+
+	t.Run("compile", func(t *testing.T) {
+		checkCompile(t, root)
+	})
+
+	copyFiles(t, testsDir, root)
+
+	t.Run("vet", func(t *testing.T) {
+		runGo(t, root, "vet", "./...")
+	})
+
+	t.Run("tests", func(t *testing.T) {
+		runGo(t, root, "test", "./basic/dao/internal", "./otel/dao/internal")
+	})
+}
+
+// TestPrune switches the corpus to sub packages, which removes the flat daos except the edited one.
+func TestPrune(t *testing.T) {
+	root := copyCorpus(t)
+	daoDir := filepath.Join(root, "basic", "dao")
+
+	opts := &options{
+		modelDir:      filepath.Join(root, "basic", "model"),
+		modelNames:    []string{"User", "Mail"},
+		daoDir:        daoDir,
+		subPkgStyle:   kebabCase,
+		fileNameStyle: underscoreCase,
+	}
+
+	newGenerator(opts).makeDao()
+
+	edited := filepath.Join(daoDir, "user.go")
+	data, err := os.ReadFile(edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.WriteFile(edited, append(data, "\n// edited\n"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts.subPkgEnable = true
+	opts.dryRun = true
+	opts.prune = true
+
+	newGenerator(opts).makeDao()
+
+	for _, file := range []string{"mail.go", "internal/mail.go"} {
+		if _, err = os.Stat(filepath.Join(daoDir, file)); err != nil {
+			t.Errorf("%s is removed by a dry run: %v", file, err)
+		}
+	}
+
+	if _, err = os.Stat(filepath.Join(daoDir, "mail")); !os.IsNotExist(err) {
+		t.Errorf("the sub packages are written by a dry run")
+	}
+
+	opts.dryRun = false
+
+	newGenerator(opts).makeDao()
+
+	for _, file := range []string{"mail.go", "mail_memory.go", "internal/mail.go", "internal/user.go", "counter.go", "internal/counter.go"} {
+		if _, err = os.Stat(filepath.Join(daoDir, file)); !os.IsNotExist(err) {
+			t.Errorf("stale %s is not pruned", file)
+		}
+	}
+
+	for _, file := range []string{"user.go", "user/user.go", "mail/mail.go", "mail/internal/mail.go"} {
+		if _, err = os.Stat(filepath.Join(daoDir, file)); err != nil {
+			t.Errorf("%s is missing: %v", file, err)
+		}
+	}
+
+	m, err := loadManifest(filepath.Join(daoDir, defaultManifestName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := m.Files["user.go"]; ok {
+		t.Errorf("the edited user.go is still in the manifest")
+	}
+
+	if _, ok := m.Files["user/internal/user.go"]; !ok {
+		t.Errorf("user/internal/user.go is not in the manifest")
+	}
+}
+
+// TestPruneDisabledFeatures reports the files of the disabled flags as stale and only removes the unedited ones with -prune.
+// TestPruneOtherModels generates the models one by one into the same directory, which keeps the files of the other models.
+func TestPruneOtherModels(t *testing.T) {
+	root := copyCorpus(t)
+	daoDir := filepath.Join(root, "basic", "dao")
+
+	opts := &options{
+		modelDir:      filepath.Join(root, "basic", "model"),
+		daoDir:        daoDir,
+		subPkgStyle:   kebabCase,
+		fileNameStyle: underscoreCase,
+		prune:         true,
+	}
+
+	for _, modelName := range []string{"Mail", "User", "Mail"} {
+		opts.modelNames = []string{modelName}
+
+		newGenerator(opts).makeDao()
+	}
+
+	for _, file := range []string{"mail.go", "mail_memory.go", "internal/mail.go", "user.go", "user_memory.go", "internal/user.go", "counter.go", "../model/gender_enum.go"} {
+		if _, err := os.Stat(filepath.Join(daoDir, filepath.FromSlash(file))); err != nil {
+			t.Errorf("%s of another model is pruned: %v", file, err)
+		}
+	}
+
+	m, err := loadManifest(filepath.Join(daoDir, defaultManifestName))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if owners := m.Owners["internal/user.go"]; len(owners) != 1 || owners[0] != "User" {
+		t.Errorf("the owners of internal/user.go are %v, want [User]", owners)
+	}
+}
+
+func TestPruneDisabledFeatures(t *testing.T) {
+	root := copyCorpus(t)
+	daoDir := filepath.Join(root, "basic", "dao")
+
+	opts := &options{
+		modelDir:      filepath.Join(root, "basic", "model"),
+		modelNames:    []string{"User", "Mail"},
+		daoDir:        daoDir,
+		subPkgStyle:   kebabCase,
+		fileNameStyle: underscoreCase,
+		otelEnable:    true,
+		tests:         true,
+	}
+
+	newGenerator(opts).makeDao()
+
+	edited := filepath.Join(daoDir, "fixture.go")
+	data, err := os.ReadFile(edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.WriteFile(edited, append(data, "\n// edited\n"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files := []string{"internal/telemetry.go", "internal/fixture.go", "user_factory.go", "user_test.go", "mail_test.go"}
+
+	opts.otelEnable = false
+	opts.tests = false
+	opts.fixtures = false
+
+	for _, prune := range []bool{false, true} {
+		opts.prune = prune
+
+		newGenerator(opts).makeDao()
+
+		for _, file := range files {
+			_, err = os.Stat(filepath.Join(daoDir, filepath.FromSlash(file)))
+			if prune && !os.IsNotExist(err) {
+				t.Errorf("stale %s is not pruned", file)
+			}
+			if !prune && err != nil {
+				t.Errorf("stale %s is removed without -prune: %v", file, err)
+			}
+		}
+
+		if _, err = os.Stat(edited); err != nil {
+			t.Errorf("prune %v: the edited fixture.go is removed: %v", prune, err)
+		}
+	}
+}
+
+func TestCheckFileNames(t *testing.T) {
+	tests := []struct {
+		names     []string
+		subPkg    bool
+		fileStyle style
+		conflict  bool
+	}{
+		{names: []string{"User", "Mail"}},
+		{names: []string{"Common"}, conflict: true},
+		{names: []string{"Memory"}, conflict: true},
+		{names: []string{"SlowLog"}, fileStyle: lowerCase, conflict: true},
+		{names: []string{"Fixture"}, conflict: true},
+		{names: []string{"Telemetry"}, conflict: true},
+		{names: []string{"User", "UserMemory"}, conflict: true},
+		{names: []string{"User", "UserFactory"}, fileStyle: kebabCase, conflict: true},
+		{names: []string{"UserTest"}, conflict: true},
+		{names: []string{"UserTest"}, fileStyle: kebabCase},
+		{names: []string{"Memory", "User", "UserMemory"}, subPkg: true},
+		{names: []string{"UserTest"}, subPkg: true, conflict: true},
+	}
+
+	for _, tt := range tests {
+		modelNames := make(map[string]struct{}, len(tt.names))
+		for _, name := range tt.names {
+			modelNames[name] = struct{}{}
+		}
+
+		fileStyle := tt.fileStyle
+		if fileStyle == "" {
+			fileStyle = underscoreCase
+		}
+
+		err := checkFileNames(modelNames, &options{subPkgEnable: tt.subPkg, fileNameStyle: fileStyle})
+		if (err != nil) != tt.conflict {
+			t.Errorf("checkFileNames(%v, sub package %v, %s) = %v, want conflict %v", tt.names, tt.subPkg, fileStyle, err, tt.conflict)
+		}
+	}
+}
+
+// TestHandWrittenFiles keeps the files written by the users under the names of the generated files.
+func TestHandWrittenFiles(t *testing.T) {
+	tests := []struct {
+		file string
+		opts func(opts *options)
+	}{
+		{
+			file: "user_test.go",
+			opts: func(opts *options) {
+				opts.tests = true
+			},
+		},
+		{
+			file: "user_memory.go",
+			opts: func(opts *options) {},
+		},
+		{
+			file: "user_factory.go",
+			opts: func(opts *options) {
+				opts.fixtures = true
+			},
+		},
+		{
+			file: "fixture.go",
+			opts: func(opts *options) {
+				opts.fixtures = true
+			},
+		},
+		{
+			file: "../model/gender_enum.go",
+			opts: func(opts *options) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			root := copyCorpus(t)
+			daoDir := filepath.Join(root, "basic", "dao")
+			file := filepath.Join(daoDir, filepath.FromSlash(tt.file))
+			data := []byte("package " + filepath.Base(filepath.Dir(file)) + "\n\n// written by hand\n")
+
+			if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := os.WriteFile(file, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			opts := &options{
+				modelDir:      filepath.Join(root, "basic", "model"),
+				modelNames:    []string{"User", "Mail"},
+				daoDir:        daoDir,
+				subPkgStyle:   kebabCase,
+				fileNameStyle: underscoreCase,
+				prune:         true,
+			}
+
+			for _, enable := range []bool{false, true} {
+				if enable {
+					tt.opts(opts)
+				}
+
+				newGenerator(opts).makeDao()
+
+				got, err := os.ReadFile(file)
+				if err != nil {
+					t.Fatalf("enabled %v: %v", enable, err)
+				}
+
+				if !bytes.Equal(got, data) {
+					t.Fatalf("enabled %v: %s is overwritten", enable, tt.file)
+				}
+			}
+		})
+	}
+}
+
+// a file written by the generator, named relative to the root of the corpus without the case directory
+type generatedFile struct {
+	name string
+	data []byte
+}
+
+// copy the corpus into a temporary module, so the generated files never touch the testdata
+func copyCorpus(t *testing.T) string {
+	t.Helper()
+
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	copyFiles(t, corpusDir, root)
+
+	return root
+}
+
+// copy the files of the source directory into the root
+func copyFiles(t *testing.T, src, root string) {
+	t.Helper()
+
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		file := filepath.Join(root, strings.TrimPrefix(path, src))
+		if err = os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+			return err
+		}
+
+		return os.WriteFile(file, data, 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// the files of the directory which are not part of the corpus
+func generatedFiles(t *testing.T, root, dir string) []generatedFile {
+	t.Helper()
+
+	files := make([]generatedFile, 0)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		if _, err = os.Stat(filepath.Join(corpusDir, rel)); err == nil {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		parts := strings.SplitN(filepath.ToSlash(rel), "/", 2)
+		files = append(files, generatedFile{name: filepath.FromSlash(parts[len(parts)-1]), data: data})
+
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+
+	return files
+}
+
+// compare the generated file with the golden file, or write the golden file with -update
+func compareGolden(t *testing.T, golden string, data []byte) {
+	t.Helper()
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(golden, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v, run go test -run TestGolden -update to create it", err)
+	}
+
+	if !bytes.Equal(want, data) {
+		t.Errorf("%s differs from the generated file:\n%s", golden, firstDiff(want, data))
+	}
+}
+
+// report the golden files which are no longer generated, they are removed with -update
+func checkGoldenFiles(t *testing.T, dir string, generated []generatedFile) {
+	t.Helper()
+
+	names := make(map[string]struct{}, len(generated))
+	for _, file := range generated {
+		names[file.name] = struct{}{}
+	}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := names[strings.TrimSuffix(rel, goldenExt)]; ok {
+			return nil
+		}
+
+		if *update {
+			return os.Remove(path)
+		}
+
+		t.Errorf("%s is no longer generated", path)
+
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+}
+
+// the first line which differs between the golden file and the generated file
+func firstDiff(want, got []byte) string {
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(string(got), "\n")
+
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+
+		if w != g {
+			return fmt.Sprintf("line %d:\n\twant: %s\n\tgot:  %s", i+1, w, g)
+		}
+	}
+
+	return ""
+}
+
+// run the go command in the corpus, which vets the generated daos or runs the hand-written tests against them
+func runGo(t *testing.T, root string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("go", args...)
+	cmd.Dir = root
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("go %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+// type check the generated packages and tests of all the cases with go/types
+func checkCompile(t *testing.T, root string) {
+	t.Helper()
+
+	cfg := &packages.Config{
+		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+		Dir:   root,
+		Tests: true,
+	}
+
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(pkgs) == 0 {
+		t.Fatal("no packages found in the corpus")
+	}
+
+	for _, pkg := range pkgs {
+		for _, err := range pkg.Errors {
+			t.Errorf("%s: %v", pkg.PkgPath, err)
+		}
+	}
+}
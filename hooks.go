@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+const (
+	hookBeforeInsert         = "BeforeInsert"
+	hookAfterInsert          = "AfterInsert"
+	hookBeforeUpdate         = "BeforeUpdate"
+	hookBeforeUpdateDocument = "BeforeUpdateDocument"
+	hookAfterFind            = "AfterFind"
+)
+
+// the names of the lifecycle hooks in the order they are detected
+var hookNames = []string{hookBeforeInsert, hookAfterInsert, hookBeforeUpdate, hookBeforeUpdateDocument, hookAfterFind}
+
+// the signatures of the lifecycle hooks, BeforeUpdateDocument receives the update document of the updates which load no model
+var hookSignatures = map[string]string{
+	hookBeforeInsert:         "func(context.Context) error",
+	hookAfterInsert:          "func(context.Context) error",
+	hookBeforeUpdate:         "func(context.Context) error",
+	hookBeforeUpdateDocument: "func(context.Context, interface{}) (interface{}, error)",
+	hookAfterFind:            "func(context.Context) error",
+}
+
+// detect the lifecycle hooks declared by the methods of the model
+// The methods with the name of a hook but another signature are not hooks, they are skipped and never called.
+func (m *model) setHooks(typ types.Type) {
+	m.hooks = make(map[string]bool)
+
+	for _, name := range hookNames {
+		obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(typ), false, nil, name)
+		fn, ok := obj.(*types.Func)
+		if !ok {
+			continue
+		}
+
+		if signature := signatureString(fn.Type().(*types.Signature)); signature != hookSignatures[name] {
+			fmt.Printf("skip method %s of model %s, it is %s instead of the hook signature %s\n", name, m.modelName, signature, hookSignatures[name])
+			continue
+		}
+
+		m.hooks[name] = true
+	}
+}
+
+func (m *model) beforeInsertCode() string {
+	if !m.hooks[hookBeforeInsert] {
+		return "return nil"
+	}
+
+	return "return model.BeforeInsert(ctx)"
+}
+
+func (m *model) afterInsertCode() string {
+	if !m.hooks[hookAfterInsert] {
+		return "return nil"
+	}
+
+	return "return model.AfterInsert(ctx)"
+}
+
+func (m *model) beforeUpdateCode() string {
+	if !m.hooks[hookBeforeUpdate] {
+		return "return nil"
+	}
+
+	return "return model.BeforeUpdate(ctx)"
+}
+
+// the update documents are passed to the BeforeUpdateDocument hook of an empty model, which only selects the hook
+func (m *model) beforeUpdateDocumentCode() string {
+	if !m.hooks[hookBeforeUpdateDocument] {
+		return "return update, nil"
+	}
+
+	return fmt.Sprintf("return (&%s.%s{}).BeforeUpdateDocument(ctx, update)", m.modelPkgName, m.modelClassName)
+}
+
+func (m *model) afterFindCode() (str string) {
+	if !m.hooks[hookAfterFind] {
+		return "return nil"
+	}
+
+	str += "for _, model := range models {\n"
+	str += "\t\tif err := model.AfterFind(ctx); err != nil {\n"
+	str += "\t\t\treturn err\n"
+	str += "\t\t}\n"
+	str += "\t}\n\n"
+	str += "\treturn nil"
+
+	return
+}
+
+// render the signature without the names of the parameters and results, example: func(context.Context) error
+func signatureString(sig *types.Signature) string {
+	tuple := func(t *types.Tuple) []string {
+		items := make([]string, 0, t.Len())
+		for i := 0; i < t.Len(); i++ {
+			item := types.TypeString(t.At(i).Type(), (*types.Package).Path)
+			if item == "any" {
+				item = "interface{}"
+			}
+			items = append(items, item)
+		}
+		return items
+	}
+
+	str := "func(" + strings.Join(tuple(sig.Params()), ", ") + ")"
+
+	switch results := tuple(sig.Results()); len(results) {
+	case 0:
+	case 1:
+		str += " " + results[0]
+	default:
+		str += " (" + strings.Join(results, ", ") + ")"
+	}
+
+	return str
+}
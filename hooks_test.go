@@ -0,0 +1,49 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+)
+
+func TestSetHooks(t *testing.T) {
+	src := `package model
+
+import "context"
+
+type User struct{}
+
+func (u *User) BeforeInsert(ctx context.Context) error { return nil }
+
+func (u *User) BeforeUpdate(ctx context.Context, update interface{}) (interface{}, error) { return update, nil }
+
+func (u User) BeforeUpdateDocument(ctx context.Context, update interface{}) (interface{}, error) { return update, nil }
+
+func (u User) AfterFind(ctx context.Context) error { return nil }
+`
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "user.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("model", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := newModel(&options{})
+	m.setModelName("User")
+	m.setHooks(pkg.Scope().Lookup("User").Type())
+
+	want := map[string]bool{hookBeforeInsert: true, hookBeforeUpdateDocument: true, hookAfterFind: true}
+	if !reflect.DeepEqual(m.hooks, want) {
+		t.Errorf("hooks = %v, want %v", m.hooks, want)
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// an index declared by the gen tag of a field
+type indexDecl struct {
+	name    string
+	group   string
+	order   int
+	unique  bool
+	sparse  bool
+	partial bool
+	ttl     *int32
+}
+
+type indexKey struct {
+	column string
+	order  int
+}
+
+type index struct {
+	name    string
+	keys    []indexKey
+	unique  bool
+	sparse  bool
+	partial bool
+	ttl     *int32
+}
+
+// parse the index options in the form of name=idx_recv_status,order=-1,group=recv,sparse,partial
+func parseIndexDecl(expr string, unique bool) (*indexDecl, error) {
+	decl := &indexDecl{order: 1, unique: unique}
+
+	for _, item := range strings.Split(expr, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		switch eles := strings.SplitN(item, "=", 2); eles[0] {
+		case "name":
+			if len(eles) != 2 || eles[1] == "" {
+				return nil, fmt.Errorf("error: invalid index option %s", item)
+			}
+			decl.name = eles[1]
+		case "group":
+			if len(eles) != 2 || eles[1] == "" {
+				return nil, fmt.Errorf("error: invalid index option %s", item)
+			}
+			decl.group = eles[1]
+		case "order":
+			if len(eles) != 2 || (eles[1] != "1" && eles[1] != "-1") {
+				return nil, fmt.Errorf("error: invalid index option %s", item)
+			}
+			decl.order, _ = strconv.Atoi(eles[1])
+		case "unique":
+			decl.unique = true
+		case "sparse":
+			decl.sparse = true
+		case "partial":
+			decl.partial = true
+		default:
+			return nil, fmt.Errorf("error: unknown index option %s", item)
+		}
+	}
+
+	return decl, nil
+}
+
+// parse the ttl index in the form of seconds
+func parseTTLIndexDecl(expr string) (*indexDecl, error) {
+	seconds, err := strconv.ParseInt(strings.TrimSpace(expr), 10, 32)
+	if err != nil || seconds < 0 {
+		return nil, fmt.Errorf("error: invalid ttl seconds %s", expr)
+	}
+
+	ttl := int32(seconds)
+
+	return &indexDecl{order: 1, ttl: &ttl}, nil
+}
+
+// build the indexes of the model from the index declarations of the fields
+func (m *model) setIndexes() error {
+	groups := make(map[string]*index)
+
+	for _, f := range m.fields {
+		for _, decl := range f.indexes {
+			// the partial indexes only cover the documents where the column exists, so the zero value must be omitted
+			if decl.partial && !f.omitEmpty {
+				return fmt.Errorf("error: partial index of field %s in model %s requires the omitempty option of the bson tag", f.name, m.modelName)
+			}
+
+			if decl.group == "" {
+				m.indexes = append(m.indexes, &index{
+					name:    decl.name,
+					keys:    []indexKey{{column: f.column, order: decl.order}},
+					unique:  decl.unique,
+					sparse:  decl.sparse,
+					partial: decl.partial,
+					ttl:     decl.ttl,
+				})
+				continue
+			}
+
+			if decl.ttl != nil {
+				return fmt.Errorf("error: ttl index of field %s in model %s can not be compound", f.name, m.modelName)
+			}
+
+			idx, ok := groups[decl.group]
+			if !ok {
+				idx = &index{}
+				groups[decl.group] = idx
+				m.indexes = append(m.indexes, idx)
+			}
+
+			idx.keys = append(idx.keys, indexKey{column: f.column, order: decl.order})
+			idx.unique = idx.unique || decl.unique
+			idx.sparse = idx.sparse || decl.sparse
+			idx.partial = idx.partial || decl.partial
+
+			if decl.name != "" {
+				if idx.name != "" && idx.name != decl.name {
+					return fmt.Errorf("error: index group %s in model %s has conflicting names %s and %s", decl.group, m.modelName, idx.name, decl.name)
+				}
+				idx.name = decl.name
+			}
+		}
+	}
+
+	names := make(map[string]struct{}, len(m.indexes))
+	for _, idx := range m.indexes {
+		if idx.sparse && idx.partial {
+			return fmt.Errorf("error: index of model %s can not be both sparse and partial", m.modelName)
+		}
+
+		if idx.name == "" {
+			idx.name = idx.defaultName()
+		}
+
+		if _, ok := names[idx.name]; ok {
+			return fmt.Errorf("error: duplicate index %s in model %s", idx.name, m.modelName)
+		}
+		names[idx.name] = struct{}{}
+	}
+
+	return nil
+}
+
+func (m *model) modelIndexes() (str string) {
+	for _, idx := range m.indexes {
+		keys := make([]string, 0, len(idx.keys))
+		for _, key := range idx.keys {
+			keys = append(keys, fmt.Sprintf("{Key: \"%s\", Value: %d}", key.column, key.order))
+		}
+
+		str += fmt.Sprintf("\t{\n\t\tName: \"%s\",\n\t\tKeys: bson.D{%s},\n", idx.name, strings.Join(keys, ", "))
+
+		if idx.unique {
+			str += "\t\tUnique: true,\n"
+		}
+
+		if idx.sparse {
+			str += "\t\tSparse: true,\n"
+		}
+
+		if idx.ttl != nil {
+			str += fmt.Sprintf("\t\tExpireAfterSeconds: %sExpireAfter(%d),\n", m.commonPrefix, *idx.ttl)
+		}
+
+		if idx.partial {
+			exprs := make([]string, 0, len(idx.keys))
+			for _, key := range idx.keys {
+				exprs = append(exprs, fmt.Sprintf("{Key: \"%s\", Value: bson.D{{Key: \"$exists\", Value: true}}}", key.column))
+			}
+			str += fmt.Sprintf("\t\tPartialFilter: bson.D{%s},\n", strings.Join(exprs, ", "))
+		}
+
+		str += "\t},\n"
+	}
+
+	str = strings.TrimPrefix(str, "\t")
+	str = strings.TrimSuffix(str, "\n")
+	return
+}
+
+// render the index as a mongo shell command
+func (idx *index) shellCommand(collection string) string {
+	keys := make([]string, 0, len(idx.keys))
+	for _, key := range idx.keys {
+		keys = append(keys, fmt.Sprintf("%q: %d", key.column, key.order))
+	}
+
+	opts := []string{fmt.Sprintf("%q: %q", "name", idx.name)}
+
+	if idx.unique {
+		opts = append(opts, fmt.Sprintf("%q: true", "unique"))
+	}
+
+	if idx.sparse {
+		opts = append(opts, fmt.Sprintf("%q: true", "sparse"))
+	}
+
+	if idx.ttl != nil {
+		opts = append(opts, fmt.Sprintf("%q: %d", "expireAfterSeconds", *idx.ttl))
+	}
+
+	if idx.partial {
+		exprs := make([]string, 0, len(idx.keys))
+		for _, key := range idx.keys {
+			exprs = append(exprs, fmt.Sprintf("%q: {\"$exists\": true}", key.column))
+		}
+		opts = append(opts, fmt.Sprintf("%q: {%s}", "partialFilterExpression", strings.Join(exprs, ", ")))
+	}
+
+	return fmt.Sprintf("db.getCollection(%q).createIndex({%s}, {%s})", collection, strings.Join(keys, ", "), strings.Join(opts, ", "))
+}
+
+// the default index name generated by mongodb, example: receiver_1_status_-1
+func (idx *index) defaultName() string {
+	parts := make([]string, 0, len(idx.keys)*2)
+	for _, key := range idx.keys {
+		parts = append(parts, key.column, strconv.Itoa(key.order))
+	}
+
+	return strings.Join(parts, "_")
+}
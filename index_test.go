@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestPartialIndexOmitEmpty(t *testing.T) {
+	for _, omitEmpty := range []bool{false, true} {
+		decl, err := parseIndexDecl("partial", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m := newModel(&options{})
+		m.setModelName("User")
+		m.addFields(&field{name: "Mobile", column: "mobile", omitEmpty: omitEmpty, indexes: []*indexDecl{decl}})
+
+		err = m.setIndexes()
+		if omitEmpty && err != nil {
+			t.Errorf("partial index with omitempty: %v", err)
+		}
+		if !omitEmpty && err == nil {
+			t.Errorf("partial index without omitempty is accepted")
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"strings"
 )
 
+const commandIndexes = "indexes"
+
 var (
 	modelDir      = flag.String("model-dir", "", "specify the model directory; must be set")
 	modelNames    = flag.String("model-names", "", "specify the comma-separated list of model name; must be set")
@@ -19,12 +21,19 @@ var (
 	subPkgStyle   = flag.String("sub-pkg-style", "kebab", "specify the generation style for sub package; options: kebab | underscore | lower | camel | pascal; default is kebab")
 	counterName   = flag.String("counter-name", "", "specify the counter name; default is counter")
 	fileNameStyle = flag.String("file-style", "underscore", "specify the generation style for file; options: kebab | underscore | lower | camel | pascal; default is underscore")
+	otelEnable    = flag.Bool("otel", false, "specify whether to generate the OpenTelemetry tracing and metrics of the dao operations; default disable")
+	notFoundError = flag.Bool("not-found-error", false, "specify whether FindOne returns ErrNotFound instead of a nil model when no document matches; default disable")
+	fixtures      = flag.Bool("fixtures", false, "specify whether to generate the model factories and the fixture loaders for the tests; default disable")
+	tests         = flag.Bool("tests", false, "specify whether to generate a test of every dao running against the database of MONGO_URI, which implies -fixtures; default disable")
+	dryRun        = flag.Bool("dry-run", false, "specify whether to only print the files which would be written or removed; default disable")
+	prune         = flag.Bool("prune", false, "specify whether to remove the files of the last generation which are no longer generated, unless they were edited; default disable")
 )
 
 // Usage is a replacement usage function for the flags package.
 func usage() {
 	fmt.Fprintf(os.Stderr, "Usage of mongo-dao-generator:\n")
 	fmt.Fprintf(os.Stderr, "\tmongo-dao-generator [flags] -model-dir=. -model-names=T,T -dao-dir=./dao\n")
+	fmt.Fprintf(os.Stderr, "\tmongo-dao-generator indexes [flags] -model-dir=. -model-names=T,T\n")
 	fmt.Fprintf(os.Stderr, "For more information, see:\n")
 	fmt.Fprintf(os.Stderr, "\thttps://github.com/dobyte/mongo-dao-generator\n")
 	fmt.Fprintf(os.Stderr, "Flags:\n")
@@ -36,7 +45,17 @@ func main() {
 	log.SetFlags(0)
 	log.SetPrefix("mongo-dao-generator: ")
 	flag.Usage = usage
-	flag.Parse()
+
+	var (
+		command string
+		args    = os.Args[1:]
+	)
+
+	if len(args) > 0 && args[0] == commandIndexes {
+		command, args = args[0], args[1:]
+	}
+
+	_ = flag.CommandLine.Parse(args)
 
 	if len(*modelDir) == 0 {
 		flag.Usage()
@@ -48,12 +67,12 @@ func main() {
 		os.Exit(2)
 	}
 
-	if len(*daoDir) == 0 {
+	if len(*daoDir) == 0 && command != commandIndexes {
 		flag.Usage()
 		os.Exit(2)
 	}
 
-	newGenerator(&options{
+	g := newGenerator(&options{
 		daoDir:        *daoDir,
 		daoPkgPath:    *daoPkgPath,
 		modelDir:      *modelDir,
@@ -64,5 +83,18 @@ func main() {
 		subPkgStyle:   style(*subPkgStyle),
 		counterName:   *counterName,
 		fileNameStyle: style(*fileNameStyle),
-	}).makeDao()
+		otelEnable:    *otelEnable,
+		notFoundError: *notFoundError,
+		fixtures:      *fixtures,
+		tests:         *tests,
+		dryRun:        *dryRun,
+		prune:         *prune,
+	})
+
+	switch command {
+	case commandIndexes:
+		g.printIndexes()
+	default:
+		g.makeDao()
+	}
 }
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"strings"
+)
+
+const defaultManifestName = "mongo-dao-generator.json"
+
+// the version of the generator recorded in the manifest, which is the module version when installed by go install
+var version = buildVersion()
+
+// the files written by the generator, which are mapped from their paths relative to the dao directory to the sha256 of their contents
+// The owners are the models the files are generated for, the shared files of the dao package have none.
+type manifest struct {
+	Version string              `json:"version"`
+	Files   map[string]string   `json:"files"`
+	Owners  map[string][]string `json:"owners,omitempty"`
+}
+
+func buildVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+
+	return "(devel)"
+}
+
+// load the manifest of the last generation, an empty manifest is returned when the dao directory has none
+func loadManifest(file string) (*manifest, error) {
+	m := &manifest{Files: make(map[string]string), Owners: make(map[string][]string)}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	if m.Files == nil {
+		m.Files = make(map[string]string)
+	}
+
+	if m.Owners == nil {
+		m.Owners = make(map[string][]string)
+	}
+
+	return m, nil
+}
+
+// the sha256 of the content of the file, the go files are hashed after gofmt so formatting them is not an edit
+func hashContent(file string, data []byte) string {
+	if filepath.Ext(file) == ".go" {
+		if formatted, err := format.Source(data); err == nil {
+			data = formatted
+		}
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// the path of the file relative to the dao directory, which keys the files of the manifest
+func (g *generator) manifestKey(file string) string {
+	root, err := filepath.Abs(g.common.daoOutputDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	path, err := filepath.Abs(file)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// expand the template and write the file, which is recorded in the manifest
+// With -dry-run the file is only reported as created, updated or unchanged.
+func (g *generator) doWrite(file string, tpl string, replaces map[string]string) error {
+	data := []byte(strings.TrimPrefix(doExpand(tpl, replaces), "\n"))
+
+	g.files[g.manifestKey(file)] = hashContent(file, data)
+	g.own(file, g.owner)
+
+	if g.opts.dryRun {
+		old, err := os.ReadFile(file)
+		switch {
+		case os.IsNotExist(err):
+			fmt.Printf("create %s\n", file)
+		case err != nil:
+			return err
+		case bytes.Equal(old, data):
+			fmt.Printf("unchanged %s\n", file)
+		default:
+			fmt.Printf("update %s\n", file)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, data, os.ModePerm)
+}
+
+// check whether the file can be written, which is when it does not exist yet or the manifest lists it as generated
+// The files written by the users under the names of the generated files are left alone.
+func (g *generator) writable(file string) bool {
+	if _, ok := g.manifest.Files[g.manifestKey(file)]; ok {
+		return true
+	}
+
+	_, err := os.Stat(file)
+	if os.IsNotExist(err) {
+		return true
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("skip %s, it was not generated\n", file)
+
+	return false
+}
+
+// keep the file which is only generated once and then owned by the user, such as the external dao files
+// It stays in the manifest when it was written by the generator, so it is not pruned as stale.
+func (g *generator) doKeep(file string) {
+	key := g.manifestKey(file)
+
+	if hash, ok := g.manifest.Files[key]; ok {
+		g.files[key] = hash
+		g.own(file, g.owner)
+	}
+}
+
+// record the models owning the file written by this generation
+func (g *generator) own(file string, modelNames ...string) {
+	key := g.manifestKey(file)
+
+	for _, modelName := range modelNames {
+		if modelName != "" && !contains(g.owners[key], modelName) {
+			g.owners[key] = append(g.owners[key], modelName)
+		}
+	}
+}
+
+// the owners of the last generation which are kept by this generation, which are the models still declared by the model package
+// but not generated this time, so their files are left to the generations of those models
+func (g *generator) otherOwners(key string) []string {
+	owners := make([]string, 0)
+
+	for _, modelName := range g.manifest.Owners[key] {
+		_, generated := g.modelNames[modelName]
+		_, declared := g.declared[modelName]
+		if declared && !generated {
+			owners = append(owners, modelName)
+		}
+	}
+
+	return owners
+}
+
+// handle the stale files of the last generation and write the manifest of this generation
+// The stale files are the files which are no longer generated, such as the daos of a renamed model or the files of a disabled feature.
+// The files of the models which are not generated this time are kept, so the models can be generated one by one into the same directory.
+// The stale files are only removed with -prune, and never when they were edited after the generation.
+func (g *generator) finishManifest() error {
+	for key := range g.files {
+		g.owners[key] = append(g.otherOwners(key), g.owners[key]...)
+	}
+
+	stale := make([]string, 0)
+	for key, hash := range g.manifest.Files {
+		if _, ok := g.files[key]; ok {
+			continue
+		}
+
+		if owners := g.otherOwners(key); len(owners) > 0 {
+			g.files[key] = hash
+			g.owners[key] = owners
+			continue
+		}
+
+		stale = append(stale, key)
+	}
+
+	sort.Strings(stale)
+
+	for _, key := range stale {
+		file := filepath.Join(g.common.daoOutputDir, filepath.FromSlash(key))
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		switch {
+		case !g.opts.prune:
+			g.files[key] = g.manifest.Files[key]
+			g.owners[key] = g.manifest.Owners[key]
+			fmt.Printf("stale %s, run with -prune to remove it\n", file)
+		case hashContent(file, data) != g.manifest.Files[key]:
+			fmt.Printf("keep %s, it was edited after the generation\n", file)
+		case g.opts.dryRun:
+			fmt.Printf("remove %s\n", file)
+		default:
+			if err = os.Remove(file); err != nil {
+				return err
+			}
+			removeEmptyDirs(filepath.Dir(file), g.common.daoOutputDir)
+			fmt.Printf("remove %s\n", file)
+		}
+	}
+
+	file := filepath.Join(g.common.daoOutputDir, defaultManifestName)
+
+	if g.opts.dryRun {
+		fmt.Printf("write %s\n", file)
+		return nil
+	}
+
+	owners := make(map[string][]string)
+	for key, modelNames := range g.owners {
+		if _, ok := g.files[key]; ok && len(modelNames) > 0 {
+			sort.Strings(modelNames)
+			owners[key] = modelNames
+		}
+	}
+
+	data, err := json.MarshalIndent(&manifest{Version: version, Files: g.files, Owners: owners}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, append(data, '\n'), os.ModePerm)
+}
+
+// remove the empty directories left by the pruned files, up to the dao directory
+func removeEmptyDirs(dir, root string) {
+	root = filepath.Clean(root)
+
+	for dir = filepath.Clean(dir); dir != root && strings.HasPrefix(dir, root+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+	}
+}
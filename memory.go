@@ -0,0 +1,21 @@
+package main
+
+import (
+	"github.com/dobyte/mongo-dao-generator/template"
+)
+
+// the replaces of the templates shared with the dao, expanded for the memory dao of the model
+func (m *model) memoryReplaces(replaces map[string]string) map[string]string {
+	memoryReplaces := make(map[string]string, len(replaces))
+	for k, v := range replaces {
+		memoryReplaces[k] = v
+	}
+	memoryReplaces[varDaoClassNameKey] = m.daoClassName + "Memory"
+
+	return memoryReplaces
+}
+
+// the soft delete methods of the memory dao, which mark the documents of the memory collection
+func (m *model) memorySoftDeleteMethods(replaces map[string]string) string {
+	return m.softDeleteMethods(template.MemorySoftDeleteTemplate, replaces)
+}
@@ -2,9 +2,9 @@ package main
 
 import (
 	"fmt"
+	"go/types"
 	"path/filepath"
 	"reflect"
-	"sort"
 	"strings"
 )
 
@@ -29,6 +29,17 @@ const (
 	pkg5 = "go.mongodb.org/mongo-driver/mongo/options"
 	pkg6 = "errors"
 	pkg7 = "go.mongodb.org/mongo-driver/bson"
+	pkg8 = "fmt"
+)
+
+type fieldKind int
+
+const (
+	genericField fieldKind = iota // Field
+	orderedField                  // OrderedField
+	numberField                   // numeric, checked by the min, max and oneof rules
+	stringField                   // StringField
+	arrayField                    // ArrayField
 )
 
 type field struct {
@@ -36,14 +47,34 @@ type field struct {
 	column            string
 	comment           string
 	documents         []string
+	kind              fieldKind
+	typ               types.Type
+	typeName          string
+	elemTypeName      string
 	autoFill          autoFill
 	autoIncrFieldName string
 	autoIncrFieldKind reflect.Kind
+	indexes           []*indexDecl
+	rules             fieldRules
+	enum              *enum
+	sensitive         bool
+	omitEmpty         bool
+}
+
+type sortKey struct {
+	column string
+	order  int
 }
 
 type model struct {
 	opts               *options
 	fields             []*field
+	defaultSort        []sortKey
+	indexes            []*index
+	schema             schemaDoc
+	softDelete         *softDelete
+	version            *field
+	hooks              map[string]bool
 	imports            map[string]string
 	modelName          string
 	modelClassName     string
@@ -58,6 +89,7 @@ type model struct {
 	daoOutputFile      string
 	daoPrefixName      string
 	collectionName     string
+	commonPrefix       string
 	fieldNameMaxLen    int
 	fieldComplexMaxLen int
 	isDependCounter    bool
@@ -71,10 +103,12 @@ func newModel(opts *options) *model {
 	}
 
 	m.addImport(pkg2)
+	m.addImport(pkg3)
 	m.addImport(pkg4)
 	m.addImport(pkg5)
 	m.addImport(pkg6)
 	m.addImport(pkg7)
+	m.addImport(pkg8)
 
 	return m
 }
@@ -123,6 +157,60 @@ func (m *model) setDaoPkgPath(path string) {
 	}
 
 	m.daoPkgName = toPackageName(filepath.Base(m.daoPkgPath))
+
+	if m.opts.subPkgEnable {
+		m.commonPrefix = defaultCommonPkgAlias + "."
+	}
+}
+
+// set the go type of the field and the kind of filter field it maps to
+func (m *model) setFieldType(f *field, typ types.Type, pkg *types.Package) {
+	f.typ = typ
+
+	if !isReferable(typ, pkg) {
+		f.typeName = "interface{}"
+		return
+	}
+
+	qualifier := func(p *types.Package) string {
+		if p == pkg {
+			return m.modelPkgName
+		}
+		m.addImport(p.Path())
+		return p.Name()
+	}
+
+	f.typeName = types.TypeString(typ, qualifier)
+
+	// the named types of the bson primitives are classified by their names instead of their underlying types,
+	// such as primitive.DateTime which is an int64 but cannot be incremented by the server
+	if named, ok := typ.(*types.Named); ok && named.Obj().Pkg() != nil {
+		switch named.Obj().Pkg().Path() + "." + named.Obj().Name() {
+		case pkg3 + ".ObjectID", pkg3 + ".DateTime", pkg3 + ".Timestamp", pkg3 + ".Decimal128", "time.Time":
+			f.kind = orderedField
+			return
+		}
+
+		if named.Obj().Pkg().Path() == pkg3 {
+			return
+		}
+	}
+
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case t.Info()&types.IsString != 0:
+			f.kind = stringField
+		case t.Info()&types.IsNumeric != 0:
+			f.kind = numberField
+		}
+	case *types.Slice:
+		if b, ok := t.Elem().(*types.Basic); ok && b.Kind() == types.Byte {
+			break
+		}
+		f.kind = arrayField
+		f.elemTypeName = types.TypeString(t.Elem(), qualifier)
+	}
 }
 
 func (m *model) addImport(pkg string, alias ...string) {
@@ -177,30 +265,178 @@ func (m *model) modelColumnsInstance() (str string) {
 	return
 }
 
-func (m *model) packages() (str string) {
-	packages := make([]string, 0, len(m.imports))
-	for pkg := range m.imports {
-		packages = append(packages, pkg)
+func (m *model) modelFilterDefined() (str string) {
+	for i, f := range m.fields {
+		str += fmt.Sprintf("\t%s%s%s", f.name, strings.Repeat(" ", m.fieldNameMaxLen-len(f.name)+1), m.commonPrefix+filterFieldType(f))
+		if i != len(m.fields)-1 {
+			str += "\n"
+		}
+	}
+
+	str = strings.TrimPrefix(str, "\t")
+	return
+}
+
+func (m *model) modelFilterInstance() (str string) {
+	for i, f := range m.fields {
+		var values string
+		if f.enum != nil {
+			values = ", " + f.enum.valuesExpr(f.typeName)
+		}
+
+		str += fmt.Sprintf("\t%s:%s%sNew%s(\"%s\"%s),", f.name, strings.Repeat(" ", m.fieldNameMaxLen-len(f.name)+1), m.commonPrefix, filterFieldType(f), f.column, values)
+		if i != len(m.fields)-1 {
+			str += "\n"
+		}
 	}
 
-	sort.Slice(packages, func(i, j int) bool {
-		return packages[i] < packages[j]
-	})
+	str = strings.TrimPrefix(str, "\t")
+	return
+}
 
-	for _, pkg := range packages {
-		if alias := m.imports[pkg]; alias != "" {
-			str += fmt.Sprintf("\t%s \"%s\"\n", alias, pkg)
+// set the default sort from a comma-separated list of field names or columns, a leading minus means descending order
+func (m *model) setDefaultSort(expr string) error {
+	for _, item := range strings.Split(expr, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		key := sortKey{order: 1}
+		if strings.HasPrefix(item, "-") {
+			key.order = -1
+			item = item[1:]
 		} else {
-			str += fmt.Sprintf("\t\"%s\"\n", pkg)
+			item = strings.TrimPrefix(item, "+")
+		}
+
+		for _, f := range m.fields {
+			if f.name == item || f.column == item {
+				key.column = f.column
+				break
+			}
+		}
+
+		if key.column == "" {
+			return fmt.Errorf("error: sort field %s not found in model %s", item, m.modelName)
+		}
+
+		m.defaultSort = append(m.defaultSort, key)
+	}
+
+	return nil
+}
+
+func (m *model) modelDefaultSort() string {
+	if len(m.defaultSort) == 0 {
+		return m.commonPrefix + "Sort"
+	}
+
+	keys := make([]string, 0, len(m.defaultSort))
+	for _, key := range m.defaultSort {
+		keys = append(keys, fmt.Sprintf("{Key: \"%s\", Value: %d}", key.column, key.order))
+	}
+
+	return fmt.Sprintf("= %sSort{%s}", m.commonPrefix, strings.Join(keys, ", "))
+}
+
+func (m *model) modelSortDefined() (str string) {
+	for i, f := range m.fields {
+		str += fmt.Sprintf("\t%s%s%sSortField", f.name, strings.Repeat(" ", m.fieldNameMaxLen-len(f.name)+1), m.commonPrefix)
+		if i != len(m.fields)-1 {
+			str += "\n"
+		}
+	}
+
+	str = strings.TrimPrefix(str, "\t")
+	return
+}
+
+func (m *model) modelSortInstance() (str string) {
+	for i, f := range m.fields {
+		str += fmt.Sprintf("\t%s:%s%sNewSortField(\"%s\"),", f.name, strings.Repeat(" ", m.fieldNameMaxLen-len(f.name)+1), m.commonPrefix, f.column)
+		if i != len(m.fields)-1 {
+			str += "\n"
 		}
 	}
 
 	str = strings.TrimPrefix(str, "\t")
+	return
+}
+
+func (m *model) modelUpdateDefined() (str string) {
+	for _, f := range m.fields {
+		if f.column == "_id" || f == m.version {
+			continue
+		}
+
+		str += fmt.Sprintf("\t%s%s%s\n", f.name, strings.Repeat(" ", m.fieldNameMaxLen-len(f.name)+1), m.commonPrefix+updateFieldType(f))
+	}
+
+	str = strings.TrimPrefix(str, "\t")
+	str = strings.TrimSuffix(str, "\n")
+	return
+}
+
+func (m *model) modelUpdateInstance() (str string) {
+	for _, f := range m.fields {
+		if f.column == "_id" || f == m.version {
+			continue
+		}
+
+		var validator string
+		if !f.rules.isEmpty() {
+			validator = ", " + m.validatorName(f)
+		}
+
+		str += fmt.Sprintf("\t\t%s:%s%sNew%s(updater, \"%s\"%s),\n", f.name, strings.Repeat(" ", m.fieldNameMaxLen-len(f.name)+1), m.commonPrefix, updateFieldType(f), f.column, validator)
+	}
+
+	str = strings.TrimPrefix(str, "\t\t")
 	str = strings.TrimSuffix(str, "\n")
 	return
 }
 
-func (m *model) autoFillCode() (str string) {
+// the enums are updated by the ordered update field, since the arithmetic operators could leave the declared values
+func updateFieldType(f *field) string {
+	if f.enum != nil {
+		return fmt.Sprintf("OrderedUpdateField[%s]", f.typeName)
+	}
+
+	switch f.kind {
+	case orderedField, stringField:
+		return fmt.Sprintf("OrderedUpdateField[%s]", f.typeName)
+	case numberField:
+		return fmt.Sprintf("NumberUpdateField[%s]", f.typeName)
+	case arrayField:
+		return fmt.Sprintf("ArrayUpdateField[%s]", f.elemTypeName)
+	default:
+		return fmt.Sprintf("UpdateField[%s]", f.typeName)
+	}
+}
+
+func filterFieldType(f *field) string {
+	if f.enum != nil {
+		return fmt.Sprintf("EnumField[%s]", f.typeName)
+	}
+
+	switch f.kind {
+	case orderedField, numberField:
+		return fmt.Sprintf("OrderedField[%s]", f.typeName)
+	case stringField:
+		return fmt.Sprintf("StringField[%s]", f.typeName)
+	case arrayField:
+		return fmt.Sprintf("ArrayField[%s]", f.elemTypeName)
+	default:
+		return fmt.Sprintf("Field[%s]", f.typeName)
+	}
+}
+
+func (m *model) packages() string {
+	return formatPackages(m.imports)
+}
+
+func (m *model) autoFillCode() string {
 	var (
 		counterName      = toPascalCase(m.opts.counterName)
 		counterPkgPrefix string
@@ -210,6 +446,16 @@ func (m *model) autoFillCode() (str string) {
 		counterPkgPrefix = fmt.Sprintf("%s.", toPackageName(counterName))
 	}
 
+	return m.autoFillCodeWith(counterPkgPrefix + "New" + counterName + "(dao.Database).Incr(ctx, \"%s\")")
+}
+
+// the autofill code of the memory dao, whose autoIncr fields are filled by the local counters of the memory collection
+func (m *model) memoryAutoFillCode() string {
+	return m.autoFillCodeWith("dao.Collection.Incr(\"%s\")")
+}
+
+// build the autofill code, the incr format is the call returning the next value of the autoIncr field named by %s
+func (m *model) autoFillCodeWith(incr string) (str string) {
 	for _, f := range m.fields {
 		if f.autoFill == 0 {
 			continue
@@ -230,7 +476,7 @@ func (m *model) autoFillCode() (str string) {
 			str += "\t}"
 		case autoIncr:
 			str += fmt.Sprintf("\tif model.%s == 0 {\n", f.name)
-			str += fmt.Sprintf("\t\tif id, err := %sNew%s(dao.Database).Incr(ctx, \"%s\"); err != nil {\n", counterPkgPrefix, counterName, f.autoIncrFieldName)
+			str += fmt.Sprintf("\t\tif id, err := "+incr+"; err != nil {\n", f.autoIncrFieldName)
 			str += "\t\t\treturn err\n"
 			str += "\t\t} else {\n"
 
@@ -271,3 +517,19 @@ func (m *model) autoFillCode() (str string) {
 
 	return
 }
+
+// register the sensitive columns of the model, whose values are redacted from the logs
+func (m *model) modelSensitive() string {
+	columns := make([]string, 0)
+	for _, f := range m.fields {
+		if f.sensitive {
+			columns = append(columns, fmt.Sprintf("%q", f.column))
+		}
+	}
+
+	if len(columns) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("func init() {\n\t%sRegisterSensitiveColumns(%q, %s)\n}", m.commonPrefix, m.collectionName, strings.Join(columns, ", "))
+}
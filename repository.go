@@ -0,0 +1,17 @@
+package main
+
+import (
+	"github.com/dobyte/mongo-dao-generator/template"
+	"strings"
+)
+
+// the methods of the repository interface depending on the soft delete of the model
+func (m *model) repositoryMethods(replaces map[string]string) string {
+	methods := make([]string, 0, 1)
+
+	if str := m.softDeleteMethods(template.SoftDeleteRepositoryTemplate, replaces); str != "" {
+		methods = append(methods, str)
+	}
+
+	return strings.Join(methods, "\n\t")
+}
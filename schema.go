@@ -0,0 +1,285 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const primitivePkgPath = "go.mongodb.org/mongo-driver/bson/primitive"
+
+// an entry of a json schema document, the value is a string, a literal or a nested document
+type schemaEntry struct {
+	key   string
+	value interface{}
+}
+
+type schemaDoc []schemaEntry
+
+// a go literal rendered into the generated code as is
+type schemaLiteral string
+
+// build the json schema of the model from the go types and the validation rules of the fields
+func (m *model) setSchema(items enums) {
+	var (
+		required   = make([]string, 0)
+		properties = make(schemaDoc, 0, len(m.fields))
+	)
+
+	for _, f := range m.fields {
+		if f.rules.required {
+			required = append(required, strconv.Quote(f.column))
+		}
+
+		property := typeSchema(f.typ, items, make(map[types.Type]bool)).merge(f.rules.schema(f))
+
+		properties = append(properties, schemaEntry{key: f.column, value: property})
+	}
+
+	m.schema = schemaDoc{{key: "bsonType", value: "object"}}
+
+	if len(required) > 0 {
+		m.schema = append(m.schema, schemaEntry{key: "required", value: schemaLiteral(fmt.Sprintf("bson.A{%s}", strings.Join(required, ", ")))})
+	}
+
+	m.schema = append(m.schema, schemaEntry{key: "properties", value: properties})
+}
+
+// merge the entries into the document, the entries replace the ones with the same keys
+func (doc schemaDoc) merge(entries schemaDoc) schemaDoc {
+	merged := make(schemaDoc, 0, len(doc)+len(entries))
+
+	for _, entry := range doc {
+		replaced := false
+		for _, e := range entries {
+			if e.key == entry.key {
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			merged = append(merged, entry)
+		}
+	}
+
+	return append(merged, entries...)
+}
+
+func (m *model) modelSchema() string {
+	return renderSchema(m.schema, 0)
+}
+
+// resolve the json schema of the go type in the same way as the bson encoder encodes it
+func typeSchema(typ types.Type, items enums, visited map[types.Type]bool) schemaDoc {
+	if typ == nil {
+		return schemaDoc{}
+	}
+
+	if e := items.lookup(typ); e != nil && e.codec == enumCodecString {
+		return append(bsonType("string"), schemaEntry{key: "enum", value: schemaLiteral(fmt.Sprintf("bson.A{%s}", strings.Join(e.schemaValues(), ", ")))})
+	}
+
+	if hasCustomCodec(typ) {
+		return schemaDoc{}
+	}
+
+	if named, ok := typ.(*types.Named); ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == primitivePkgPath {
+		switch named.Obj().Name() {
+		case "ObjectID":
+			return bsonType("objectId")
+		case "DateTime":
+			return bsonType("date")
+		case "Decimal128":
+			return bsonType("decimal")
+		case "Timestamp":
+			return bsonType("timestamp")
+		case "Binary":
+			return bsonType("binData")
+		case "Regex":
+			return bsonType("regex")
+		default:
+			return schemaDoc{}
+		}
+	}
+
+	if named, ok := typ.(*types.Named); ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "time" && named.Obj().Name() == "Time" {
+		return bsonType("date")
+	}
+
+	var doc schemaDoc
+
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		switch t.Kind() {
+		case types.String:
+			doc = bsonType("string")
+		case types.Bool:
+			doc = bsonType("bool")
+		case types.Int8, types.Int16, types.Int32, types.Uint8, types.Uint16:
+			doc = bsonType("int")
+		case types.Int:
+			doc = bsonType("int", "long")
+		case types.Int64, types.Uint, types.Uint32, types.Uint64:
+			doc = bsonType("long")
+		case types.Float32, types.Float64:
+			doc = bsonType("double")
+		default:
+			return schemaDoc{}
+		}
+	case *types.Pointer:
+		doc = typeSchema(t.Elem(), items, visited)
+		return nullable(doc)
+	case *types.Slice:
+		if b, ok := t.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+			return nullable(bsonType("binData"))
+		}
+		doc = bsonType("array")
+		if elem := typeSchema(t.Elem(), items, visited); len(elem) > 0 {
+			doc = append(doc, schemaEntry{key: "items", value: elem})
+		}
+		return nullable(doc)
+	case *types.Array:
+		doc = bsonType("array")
+		if elem := typeSchema(t.Elem(), items, visited); len(elem) > 0 {
+			doc = append(doc, schemaEntry{key: "items", value: elem})
+		}
+	case *types.Map:
+		return nullable(bsonType("object"))
+	case *types.Struct:
+		if visited[typ] {
+			return bsonType("object")
+		}
+		visited[typ] = true
+		defer delete(visited, typ)
+
+		doc = bsonType("object")
+		if properties := structSchema(t, items, visited); len(properties) > 0 {
+			doc = append(doc, schemaEntry{key: "properties", value: properties})
+		}
+	default:
+		return schemaDoc{}
+	}
+
+	if e := items.lookup(typ); e != nil {
+		doc = append(doc, schemaEntry{key: "enum", value: schemaLiteral(fmt.Sprintf("bson.A{%s}", strings.Join(e.schemaValues(), ", ")))})
+	}
+
+	return doc
+}
+
+// resolve the properties of the struct from the bson tags of the fields
+func structSchema(st *types.Struct, items enums, visited map[types.Type]bool) schemaDoc {
+	properties := make(schemaDoc, 0, st.NumFields())
+
+	for i := 0; i < st.NumFields(); i++ {
+		v := st.Field(i)
+		if !v.Exported() {
+			continue
+		}
+
+		tag := reflect.StructTag(st.Tag(i)).Get("bson")
+		if tag == "-" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+
+		inline := false
+		for _, part := range parts[1:] {
+			if part == "inline" {
+				inline = true
+			}
+		}
+
+		if inline {
+			if inner, ok := v.Type().Underlying().(*types.Struct); ok {
+				properties = append(properties, structSchema(inner, items, visited)...)
+			}
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(v.Name())
+		}
+
+		properties = append(properties, schemaEntry{key: name, value: typeSchema(v.Type(), items, visited)})
+	}
+
+	return properties
+}
+
+// check whether the type is encoded by its own marshaler
+func hasCustomCodec(typ types.Type) bool {
+	for _, method := range []string{"MarshalBSON", "MarshalBSONValue"} {
+		if obj, _, _ := types.LookupFieldOrMethod(typ, true, nil, method); obj != nil {
+			if _, ok := obj.(*types.Func); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func bsonType(names ...string) schemaDoc {
+	if len(names) == 1 {
+		return schemaDoc{{key: "bsonType", value: names[0]}}
+	}
+
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, strconv.Quote(name))
+	}
+
+	return schemaDoc{{key: "bsonType", value: schemaLiteral(fmt.Sprintf("bson.A{%s}", strings.Join(quoted, ", ")))}}
+}
+
+// allow the null value which the bson encoder writes for nil pointers, slices and maps
+func nullable(doc schemaDoc) schemaDoc {
+	for i, entry := range doc {
+		if entry.key != "bsonType" {
+			continue
+		}
+
+		switch v := entry.value.(type) {
+		case string:
+			doc[i].value = schemaLiteral(fmt.Sprintf("bson.A{%q, %q}", v, "null"))
+		case schemaLiteral:
+			doc[i].value = schemaLiteral(strings.TrimSuffix(string(v), "}") + `, "null"}`)
+		}
+
+		return doc
+	}
+
+	return doc
+}
+
+func renderSchema(doc schemaDoc, depth int) string {
+	if len(doc) == 0 {
+		return "bson.D{}"
+	}
+
+	indent := strings.Repeat("\t", depth+1)
+
+	str := "bson.D{\n"
+	for _, entry := range doc {
+		var value string
+		switch v := entry.value.(type) {
+		case string:
+			value = strconv.Quote(v)
+		case schemaLiteral:
+			value = string(v)
+		case schemaDoc:
+			value = renderSchema(v, depth+1)
+		}
+
+		str += fmt.Sprintf("%s{Key: %q, Value: %s},\n", indent, entry.key, value)
+	}
+	str += strings.Repeat("\t", depth) + "}"
+
+	return str
+}
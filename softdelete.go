@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	directiveSoftDelete     = "softDelete"
+	defaultSoftDeleteColumn = "deleted_at"
+)
+
+// the column marking the soft deleted documents
+type softDelete struct {
+	column string
+	zero   string // the go expression of the zero value of the field, empty if the column is not a field of the model
+}
+
+// mark the field as the soft delete column
+func (m *model) setSoftDeleteField(f *field) error {
+	if m.softDelete != nil {
+		return fmt.Errorf("error: model %s has more than one soft delete column", m.modelName)
+	}
+
+	var zero string
+
+	switch f.typeName {
+	case "primitive.DateTime":
+		zero = "primitive.DateTime(0)"
+	case "time.Time":
+		zero = "time.Time{}"
+	case "*primitive.DateTime", "*time.Time":
+		zero = "nil"
+	default:
+		return fmt.Errorf("error: soft delete field %s of model %s must be primitive.DateTime or time.Time", f.name, m.modelName)
+	}
+
+	m.softDelete = &softDelete{column: f.column, zero: zero}
+
+	return nil
+}
+
+// mark the column declared by the struct directive as the soft delete column
+func (m *model) setSoftDeleteColumn(column string) error {
+	if column == "" {
+		column = defaultSoftDeleteColumn
+	}
+
+	for _, f := range m.fields {
+		if f.column == column {
+			if m.softDelete != nil && m.softDelete.column == column {
+				return nil
+			}
+			return m.setSoftDeleteField(f)
+		}
+	}
+
+	if m.softDelete != nil {
+		return fmt.Errorf("error: model %s has more than one soft delete column", m.modelName)
+	}
+
+	m.softDelete = &softDelete{column: column, zero: "nil"}
+
+	return nil
+}
+
+func (m *model) modelSoftDelete() string {
+	if m.softDelete == nil {
+		return fmt.Sprintf("*%sSoftDelete", m.commonPrefix)
+	}
+
+	str := fmt.Sprintf("= &%sSoftDelete{Column: %q", m.commonPrefix, m.softDelete.column)
+	if m.softDelete.zero != "nil" {
+		str += fmt.Sprintf(", Zero: %s", m.softDelete.zero)
+	}
+	str += "}"
+
+	return str
+}
+
+// the code deleting the documents, which marks them as deleted when the model is soft deleted, method is DeleteOne or DeleteMany
+func (m *model) deleteCode(method string) string {
+	if m.softDelete == nil {
+		return fmt.Sprintf("return dao.Collection.%s(ctx, op.Filter, opts)", method)
+	}
+
+	return fmt.Sprintf("return %sSoftDelete.%s(ctx, dao.Collection, op.Filter, opts)", m.daoVariableName, method)
+}
+
+// the code deleting the documents of the memory collection
+func (m *model) memoryDeleteCode(method string) string {
+	if m.softDelete == nil {
+		return fmt.Sprintf("return dao.Collection.%s(op.Filter)", method)
+	}
+
+	return fmt.Sprintf("return dao.Collection.SoftDelete(%sSoftDelete, op.Filter, %t)", m.daoVariableName, method == "DeleteMany")
+}
+
+// the methods generated for the soft deleted model only
+func (m *model) softDeleteMethods(tpl string, replaces map[string]string) string {
+	if m.softDelete == nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(doExpand(tpl, replaces), "\n")
+}
@@ -0,0 +1,1900 @@
+package template
+
+const CommonExternalTemplate = `
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+package ${VarDaoPackageName}
+
+import (
+	"context"
+	"${VarDaoPackagePath}/internal"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrEmptyUpdate       = internal.ErrEmptyUpdate
+	ErrVersionConflict   = internal.ErrVersionConflict
+	ErrVersionUpsert     = internal.ErrVersionUpsert
+	ErrNotFound          = internal.ErrNotFound
+	ErrDuplicateKey      = internal.ErrDuplicateKey
+	ErrInvalidID         = internal.ErrInvalidID
+	ErrValidation        = internal.ErrValidation
+	ErrMemoryUnsupported = internal.ErrMemoryUnsupported
+)
+
+// WithTx runs the function in a transaction, the txCtx must be passed to the dao methods to join the transaction.
+func WithTx(ctx context.Context, client *mongo.Client, fn func(txCtx context.Context) error, opts ...*options.TransactionOptions) error {
+	return internal.WithTx(ctx, client, fn, opts...)
+}
+
+// Use appends the interceptors to the chain shared by all daos, the first interceptor is the outermost one.
+func Use(interceptors ...Interceptor) {
+	internal.Use(interceptors...)
+}
+
+type (
+	FieldError        = internal.FieldError
+	ValidationError   = internal.ValidationError
+	DuplicateKeyError = internal.DuplicateKeyError
+	ValidationLevel   = internal.ValidationLevel
+	ValidationAction  = internal.ValidationAction
+	IndexSpec         = internal.IndexSpec
+	IndexView         = internal.IndexView
+	IndexChange       = internal.IndexChange
+	IndexDiff         = internal.IndexDiff
+	Sort              = internal.Sort
+	ResumeTokenStore  = internal.ResumeTokenStore
+	UpdateDescription = internal.UpdateDescription
+	OpKind            = internal.OpKind
+	OpInfo            = internal.OpInfo
+	Handler           = internal.Handler
+	Interceptor       = internal.Interceptor
+	SlowQueryOptions  = internal.SlowQueryOptions
+)
+
+const (
+	ValidationLevelOff      = internal.ValidationLevelOff
+	ValidationLevelStrict   = internal.ValidationLevelStrict
+	ValidationLevelModerate = internal.ValidationLevelModerate
+	ValidationActionError   = internal.ValidationActionError
+	ValidationActionWarn    = internal.ValidationActionWarn
+	OpCount                 = internal.OpCount
+	OpEstimatedCount        = internal.OpEstimatedCount
+	OpExists                = internal.OpExists
+	OpDistinct              = internal.OpDistinct
+	OpAggregate             = internal.OpAggregate
+	OpWatch                 = internal.OpWatch
+	OpInsertOne             = internal.OpInsertOne
+	OpInsertMany            = internal.OpInsertMany
+	OpUpdateOne             = internal.OpUpdateOne
+	OpUpdateMany            = internal.OpUpdateMany
+	OpReplaceOne            = internal.OpReplaceOne
+	OpFindOne               = internal.OpFindOne
+	OpFindMany              = internal.OpFindMany
+	OpDeleteOne             = internal.OpDeleteOne
+	OpDeleteMany            = internal.OpDeleteMany
+	OpRestore               = internal.OpRestore
+	OpIncr                  = internal.OpIncr
+)
+`
+
+const CommonInternalTemplate = `
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrEmptyUpdate     = errors.New("update document is empty")
+	ErrVersionConflict = errors.New("version conflict, the document has been changed by another writer")
+	ErrVersionUpsert   = errors.New("upsert is not supported by the version checked operations")
+	ErrNotFound        = errors.New("document not found")
+	ErrDuplicateKey    = errors.New("duplicate key")
+	ErrInvalidID       = errors.New("invalid object id")
+	ErrValidation      = errors.New("validation failed")
+)
+
+var (
+	dupKeyPattern       = regexp.MustCompile("index: (\\S+) dup key: \\{(.*)\\}")
+	dupKeyStringPattern = regexp.MustCompile("\"(?:[^\"\\\\]|\\\\.)*\"")
+)
+
+var projections sync.Map
+
+// FilterBuilder provides the logical operators used to compose filters.
+type FilterBuilder struct{}
+
+// And joins filters with a logical AND and matches the documents that satisfy all the filters.
+func (FilterBuilder) And(filters ...bson.D) bson.D {
+	return join("$and", filters)
+}
+
+// Or joins filters with a logical OR and matches the documents that satisfy at least one of the filters.
+// Without filters it matches no document, so an empty list of alternatives never selects the whole collection.
+func (FilterBuilder) Or(filters ...bson.D) bson.D {
+	if len(filters) == 0 {
+		return bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: bson.A{}}}}}
+	}
+
+	return join("$or", filters)
+}
+
+// Nor joins filters with a logical NOR and matches the documents that fail all the filters.
+// Without filters it matches all the documents like And.
+func (FilterBuilder) Nor(filters ...bson.D) bson.D {
+	if len(filters) == 0 {
+		return bson.D{}
+	}
+
+	return bson.D{{Key: "$nor", Value: toArray(filters)}}
+}
+
+// Not inverts the filter and matches the documents that do not satisfy it.
+func (FilterBuilder) Not(filter bson.D) bson.D {
+	return bson.D{{Key: "$nor", Value: bson.A{filter}}}
+}
+
+// Field provides the comparison operators shared by all the columns.
+type Field[T any] struct {
+	name string
+}
+
+func NewField[T any](name string) Field[T] {
+	return Field[T]{name: name}
+}
+
+// Eq matches the documents where the value of the column equals the specified value.
+func (f Field[T]) Eq(value T) bson.D {
+	return bson.D{{Key: f.name, Value: value}}
+}
+
+// Ne matches the documents where the value of the column does not equal the specified value.
+func (f Field[T]) Ne(value T) bson.D {
+	return f.operate("$ne", value)
+}
+
+// In matches the documents where the value of the column equals any value in the specified values.
+func (f Field[T]) In(values ...T) bson.D {
+	if values == nil {
+		values = make([]T, 0)
+	}
+
+	return f.operate("$in", values)
+}
+
+// Nin matches the documents where the value of the column equals none of the specified values.
+func (f Field[T]) Nin(values ...T) bson.D {
+	if values == nil {
+		values = make([]T, 0)
+	}
+
+	return f.operate("$nin", values)
+}
+
+// Exists matches the documents that contain or do not contain the column.
+func (f Field[T]) Exists(exists bool) bson.D {
+	return f.operate("$exists", exists)
+}
+
+func (f Field[T]) operate(operator string, value interface{}) bson.D {
+	return bson.D{{Key: f.name, Value: bson.D{{Key: operator, Value: value}}}}
+}
+
+// OrderedField provides the range operators for the columns whose values can be ordered.
+type OrderedField[T any] struct {
+	Field[T]
+}
+
+func NewOrderedField[T any](name string) OrderedField[T] {
+	return OrderedField[T]{Field: NewField[T](name)}
+}
+
+// Gt matches the documents where the value of the column is greater than the specified value.
+func (f OrderedField[T]) Gt(value T) bson.D {
+	return f.operate("$gt", value)
+}
+
+// Gte matches the documents where the value of the column is greater than or equal to the specified value.
+func (f OrderedField[T]) Gte(value T) bson.D {
+	return f.operate("$gte", value)
+}
+
+// Lt matches the documents where the value of the column is less than the specified value.
+func (f OrderedField[T]) Lt(value T) bson.D {
+	return f.operate("$lt", value)
+}
+
+// Lte matches the documents where the value of the column is less than or equal to the specified value.
+func (f OrderedField[T]) Lte(value T) bson.D {
+	return f.operate("$lte", value)
+}
+
+// Between matches the documents where the value of the column is within the closed interval [min, max].
+func (f OrderedField[T]) Between(min, max T) bson.D {
+	return bson.D{{Key: f.name, Value: bson.D{{Key: "$gte", Value: min}, {Key: "$lte", Value: max}}}}
+}
+
+// EnumField provides the filter conditions for the columns whose type has constants declared.
+type EnumField[T any] struct {
+	OrderedField[T]
+	values []T
+}
+
+func NewEnumField[T any](name string, values []T) EnumField[T] {
+	return EnumField[T]{OrderedField: NewOrderedField[T](name), values: values}
+}
+
+// Valid matches the documents where the value of the column is one of the declared constants.
+func (f EnumField[T]) Valid() bson.D {
+	return f.In(f.values...)
+}
+
+// Invalid matches the documents where the value of the column is none of the declared constants.
+func (f EnumField[T]) Invalid() bson.D {
+	return f.Nin(f.values...)
+}
+
+// StringField provides the pattern operators for the string columns.
+type StringField[T any] struct {
+	OrderedField[T]
+}
+
+func NewStringField[T any](name string) StringField[T] {
+	return StringField[T]{OrderedField: NewOrderedField[T](name)}
+}
+
+// Regex matches the documents where the value of the column matches the regular expression.
+func (f StringField[T]) Regex(pattern string, options ...string) bson.D {
+	regex := primitive.Regex{Pattern: pattern}
+	if len(options) > 0 {
+		regex.Options = options[0]
+	}
+
+	return bson.D{{Key: f.name, Value: regex}}
+}
+
+// ArrayField provides the array operators for the slice columns.
+type ArrayField[E any] struct {
+	Field[[]E]
+}
+
+func NewArrayField[E any](name string) ArrayField[E] {
+	return ArrayField[E]{Field: NewField[[]E](name)}
+}
+
+// Contains matches the documents where the array contains the specified element.
+func (f ArrayField[E]) Contains(element E) bson.D {
+	return bson.D{{Key: f.name, Value: element}}
+}
+
+// ContainsAny matches the documents where the array contains at least one of the specified elements.
+func (f ArrayField[E]) ContainsAny(elements ...E) bson.D {
+	if elements == nil {
+		elements = make([]E, 0)
+	}
+
+	return f.operate("$in", elements)
+}
+
+// All matches the documents where the array contains all the specified elements.
+func (f ArrayField[E]) All(elements ...E) bson.D {
+	if elements == nil {
+		elements = make([]E, 0)
+	}
+
+	return f.operate("$all", elements)
+}
+
+// Size matches the documents where the array has the specified number of elements.
+func (f ArrayField[E]) Size(size int) bson.D {
+	return f.operate("$size", size)
+}
+
+// ElemMatch matches the documents where at least one element of the array satisfies the filter.
+func (f ArrayField[E]) ElemMatch(filter bson.D) bson.D {
+	return f.operate("$elemMatch", filter)
+}
+
+// FieldError describes a column whose value violates a validation rule declared by the gen tags.
+type FieldError struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// ValidationError collects the columns whose values violate the validation rules.
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Error())
+	}
+
+	return ErrValidation.Error() + ": " + strings.Join(messages, "; ")
+}
+
+// Is reports whether the target is ErrValidation.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// DuplicateKeyError is returned when a write violates a unique index, the keys are the field names of the index.
+type DuplicateKeyError struct {
+	Index string
+	Keys  []string
+	err   error
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("%s %s violates the unique index %s", ErrDuplicateKey.Error(), strings.Join(e.Keys, ", "), e.Index)
+}
+
+// Is reports whether the target is ErrDuplicateKey.
+func (e *DuplicateKeyError) Is(target error) bool {
+	return target == ErrDuplicateKey
+}
+
+// Unwrap returns the error of the driver.
+func (e *DuplicateKeyError) Unwrap() error {
+	return e.err
+}
+
+// MapError converts the duplicate key error of the driver into a DuplicateKeyError, other errors are returned as is.
+// The keys are taken from the key pattern reported by the server, the declared index of the same name or the message,
+// and the columns are mapped to the field names of the model.
+func MapError(err error, indexes []IndexSpec, fieldNames map[string]string) error {
+	message, raw, ok := duplicateKey(err)
+	if !ok {
+		return err
+	}
+
+	var (
+		index   string
+		columns []string
+	)
+
+	matches := dupKeyPattern.FindStringSubmatch(message)
+	if matches != nil {
+		index = matches[1]
+	}
+
+	if pattern, ok := raw.Lookup("keyPattern").DocumentOK(); ok {
+		if elements, err := pattern.Elements(); err == nil {
+			for _, element := range elements {
+				columns = append(columns, element.Key())
+			}
+		}
+	}
+
+	if columns == nil && index != "" {
+		for _, spec := range indexes {
+			if spec.Name == index {
+				for _, key := range spec.Keys {
+					columns = append(columns, key.Key)
+				}
+				break
+			}
+		}
+	}
+
+	if columns == nil && matches != nil {
+		for _, item := range strings.Split(dupKeyStringPattern.ReplaceAllString(matches[2], "\"\""), ", ") {
+			if i := strings.Index(item, ": "); i > 0 {
+				columns = append(columns, strings.TrimSpace(item[:i]))
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if name, ok := fieldNames[column]; ok {
+			keys = append(keys, name)
+		} else {
+			keys = append(keys, column)
+		}
+	}
+
+	return &DuplicateKeyError{Index: index, Keys: keys, err: err}
+}
+
+// ParseObjectID parses the hex string into an object id, the error matches ErrInvalidID.
+func ParseObjectID(id string) (primitive.ObjectID, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return objectID, fmt.Errorf("%w: %q", ErrInvalidID, id)
+	}
+
+	return objectID, nil
+}
+
+// find the message and the raw document of the first duplicate key error reported by the server
+func duplicateKey(err error) (string, bson.Raw, bool) {
+	if err == nil || !mongo.IsDuplicateKeyError(err) {
+		return "", nil, false
+	}
+
+	var writeException mongo.WriteException
+	if errors.As(err, &writeException) {
+		for _, e := range writeException.WriteErrors {
+			if isDuplicateKeyCode(e.Code) {
+				return e.Message, e.Raw, true
+			}
+		}
+	}
+
+	var bulkWriteException mongo.BulkWriteException
+	if errors.As(err, &bulkWriteException) {
+		for _, e := range bulkWriteException.WriteErrors {
+			if isDuplicateKeyCode(e.Code) {
+				return e.Message, e.Raw, true
+			}
+		}
+	}
+
+	var commandError mongo.CommandError
+	if errors.As(err, &commandError) {
+		return commandError.Message, commandError.Raw, true
+	}
+
+	return err.Error(), nil, true
+}
+
+func isDuplicateKeyCode(code int) bool {
+	return code == 11000 || code == 11001 || code == 12582
+}
+
+// Validate returns a ValidationError of the failed columns, or nil if there is no failed column.
+func Validate(errs ...*FieldError) error {
+	fields := make([]*FieldError, 0, len(errs))
+	for _, err := range errs {
+		if err != nil {
+			fields = append(fields, err)
+		}
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Fields: fields}
+}
+
+// StringValues converts the values returned by a distinct command into strings.
+func StringValues(values []interface{}) ([]string, error) {
+	items := make([]string, 0, len(values))
+	for _, value := range values {
+		item, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid distinct value type %T, expected string", value)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// Int64Values converts the values returned by a distinct command into integers.
+func Int64Values(values []interface{}) ([]int64, error) {
+	items := make([]int64, 0, len(values))
+	for _, value := range values {
+		switch item := value.(type) {
+		case int32:
+			items = append(items, int64(item))
+		case int64:
+			items = append(items, item)
+		default:
+			return nil, fmt.Errorf("invalid distinct value type %T, expected integer", value)
+		}
+	}
+
+	return items, nil
+}
+
+// IsEmail reports whether the value is a bare email address.
+func IsEmail(value string) bool {
+	addr, err := mail.ParseAddress(value)
+	return err == nil && addr.Address == value
+}
+
+// Updater collects the update operations of the columns and groups them by operator.
+type Updater struct {
+	operators []string
+	documents map[string]bson.D
+	errs      []*FieldError
+}
+
+func NewUpdater() *Updater {
+	return &Updater{documents: make(map[string]bson.D)}
+}
+
+// Document returns the update document grouped by operator.
+func (u *Updater) Document() bson.D {
+	doc := make(bson.D, 0, len(u.operators))
+	for _, operator := range u.operators {
+		doc = append(doc, bson.E{Key: operator, Value: u.documents[operator]})
+	}
+
+	return doc
+}
+
+// Err returns the validation error of the values passed to the update operators.
+func (u *Updater) Err() error {
+	return Validate(u.errs...)
+}
+
+func (u *Updater) fail(err *FieldError) {
+	if err != nil {
+		u.errs = append(u.errs, err)
+	}
+}
+
+func (u *Updater) add(operator string, name string, value interface{}) {
+	doc, ok := u.documents[operator]
+	if !ok {
+		u.operators = append(u.operators, operator)
+	}
+
+	for i := range doc {
+		if doc[i].Key == name {
+			doc[i].Value = value
+			return
+		}
+	}
+
+	u.documents[operator] = append(doc, bson.E{Key: name, Value: value})
+}
+
+// IsEmptyUpdate reports whether the update document contains no update operations.
+func IsEmptyUpdate(update interface{}) bool {
+	return isEmptyDocument(update)
+}
+
+func isEmptyDocument(document interface{}) bool {
+	switch doc := document.(type) {
+	case nil:
+		return true
+	case bson.D:
+		return len(doc) == 0
+	case bson.M:
+		return len(doc) == 0
+	case map[string]interface{}:
+		return len(doc) == 0
+	case bson.A:
+		return len(doc) == 0
+	case []interface{}:
+		return len(doc) == 0
+	default:
+		return false
+	}
+}
+
+// UpdateField provides the update operators shared by all the columns.
+// The values passed to Set, SetOnInsert, Min and Max are checked by the validator of the column,
+// and Unset is checked as setting the zero value.
+type UpdateField[T any] struct {
+	name      string
+	updater   *Updater
+	validator func(T) *FieldError
+}
+
+func NewUpdateField[T any](updater *Updater, name string, validator ...func(T) *FieldError) UpdateField[T] {
+	f := UpdateField[T]{name: name, updater: updater}
+
+	if len(validator) > 0 {
+		f.validator = validator[0]
+	}
+
+	return f
+}
+
+// Set sets the value of the column to the specified value.
+func (f UpdateField[T]) Set(value T) {
+	f.validate(value)
+	f.updater.add("$set", f.name, value)
+}
+
+// SetOnInsert sets the value of the column to the specified value only when an upsert inserts a document.
+func (f UpdateField[T]) SetOnInsert(value T) {
+	f.validate(value)
+	f.updater.add("$setOnInsert", f.name, value)
+}
+
+// Unset removes the column from the document.
+func (f UpdateField[T]) Unset() {
+	var zero T
+	f.validate(zero)
+	f.updater.add("$unset", f.name, "")
+}
+
+func (f UpdateField[T]) validate(value T) {
+	if f.validator != nil {
+		f.updater.fail(f.validator(value))
+	}
+}
+
+// OrderedUpdateField provides the comparison update operators for the columns whose values can be ordered.
+type OrderedUpdateField[T any] struct {
+	UpdateField[T]
+}
+
+func NewOrderedUpdateField[T any](updater *Updater, name string, validator ...func(T) *FieldError) OrderedUpdateField[T] {
+	return OrderedUpdateField[T]{UpdateField: NewUpdateField[T](updater, name, validator...)}
+}
+
+// Min updates the value of the column only if the specified value is less than the current value.
+func (f OrderedUpdateField[T]) Min(value T) {
+	f.validate(value)
+	f.updater.add("$min", f.name, value)
+}
+
+// Max updates the value of the column only if the specified value is greater than the current value.
+func (f OrderedUpdateField[T]) Max(value T) {
+	f.validate(value)
+	f.updater.add("$max", f.name, value)
+}
+
+// NumberUpdateField provides the arithmetic update operators for the numeric columns.
+type NumberUpdateField[T any] struct {
+	OrderedUpdateField[T]
+}
+
+func NewNumberUpdateField[T any](updater *Updater, name string, validator ...func(T) *FieldError) NumberUpdateField[T] {
+	return NumberUpdateField[T]{OrderedUpdateField: NewOrderedUpdateField[T](updater, name, validator...)}
+}
+
+// Inc increments the value of the column by the specified amount.
+func (f NumberUpdateField[T]) Inc(value T) {
+	f.updater.add("$inc", f.name, value)
+}
+
+// Mul multiplies the value of the column by the specified number.
+func (f NumberUpdateField[T]) Mul(value T) {
+	f.updater.add("$mul", f.name, value)
+}
+
+// ArrayUpdateField provides the array update operators for the slice columns.
+type ArrayUpdateField[E any] struct {
+	UpdateField[[]E]
+}
+
+func NewArrayUpdateField[E any](updater *Updater, name string, validator ...func([]E) *FieldError) ArrayUpdateField[E] {
+	return ArrayUpdateField[E]{UpdateField: NewUpdateField[[]E](updater, name, validator...)}
+}
+
+// Push appends the specified elements to the array.
+func (f ArrayUpdateField[E]) Push(elements ...E) {
+	if elements == nil {
+		elements = make([]E, 0)
+	}
+
+	f.updater.add("$push", f.name, bson.D{{Key: "$each", Value: elements}})
+}
+
+// AddToSet adds the specified elements to the array unless they are already present.
+func (f ArrayUpdateField[E]) AddToSet(elements ...E) {
+	if elements == nil {
+		elements = make([]E, 0)
+	}
+
+	f.updater.add("$addToSet", f.name, bson.D{{Key: "$each", Value: elements}})
+}
+
+// Pull removes all the instances of the specified element from the array.
+func (f ArrayUpdateField[E]) Pull(element E) {
+	f.updater.add("$pull", f.name, element)
+}
+
+// PullAll removes all the instances of the specified elements from the array.
+func (f ArrayUpdateField[E]) PullAll(elements ...E) {
+	if elements == nil {
+		elements = make([]E, 0)
+	}
+
+	f.updater.add("$pullAll", f.name, elements)
+}
+
+// PopFirst removes the first element of the array.
+func (f ArrayUpdateField[E]) PopFirst() {
+	f.updater.add("$pop", f.name, -1)
+}
+
+// PopLast removes the last element of the array.
+func (f ArrayUpdateField[E]) PopLast() {
+	f.updater.add("$pop", f.name, 1)
+}
+
+// Projection returns the projection document built from the bson tags of the struct P.
+func Projection[P any]() bson.D {
+	typ := reflect.TypeOf((*P)(nil)).Elem()
+
+	if projection, ok := projections.Load(typ); ok {
+		return projection.(bson.D)
+	}
+
+	projection := make(bson.D, 0)
+	for _, column := range columns(typ) {
+		projection = append(projection, bson.E{Key: column, Value: 1})
+	}
+
+	projections.Store(typ, projection)
+
+	return projection
+}
+
+// FindOneAs executes a find command and decodes one document into the struct P, projecting only the columns of P.
+func FindOneAs[P any](ctx context.Context, collection *mongo.Collection, filter interface{}, opts *options.FindOneOptions) (*P, error) {
+	opts = options.MergeFindOneOptions(opts)
+
+	if opts.Projection == nil {
+		opts.SetProjection(Projection[P]())
+	}
+
+	model := new(P)
+
+	err := collection.FindOne(ctx, filter, opts).Decode(model)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// FindManyAs executes a find command and decodes the matching documents into the struct P, projecting only the columns of P.
+func FindManyAs[P any](ctx context.Context, collection *mongo.Collection, filter interface{}, opts *options.FindOptions) ([]*P, error) {
+	opts = options.MergeFindOptions(opts)
+
+	if opts.Projection == nil {
+		opts.SetProjection(Projection[P]())
+	}
+
+	cur, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]*P, 0)
+
+	if err = cur.All(ctx, &models); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+// resolve the columns of the struct from the bson tags in the same way as the bson encoder
+func columns(typ reflect.Type) []string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	items := make([]string, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup("bson")
+		if !ok && !strings.Contains(string(field.Tag), ":") {
+			tag = string(field.Tag)
+		}
+
+		if tag == "-" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		name := parts[0]
+
+		inline := false
+		for _, part := range parts[1:] {
+			if part == "inline" {
+				inline = true
+			}
+		}
+
+		if inline {
+			items = append(items, columns(field.Type)...)
+			continue
+		}
+
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		items = append(items, name)
+	}
+
+	return items
+}
+
+// IndexSpec describes an index declared by the gen tags of a model.
+type IndexSpec struct {
+	Name               string
+	Keys               bson.D
+	Unique             bool
+	Sparse             bool
+	ExpireAfterSeconds *int32
+	PartialFilter      bson.D
+}
+
+// Model returns the index model used to create the index.
+func (s IndexSpec) Model() mongo.IndexModel {
+	opts := options.Index().SetName(s.Name)
+
+	if s.Unique {
+		opts.SetUnique(true)
+	}
+
+	if s.Sparse {
+		opts.SetSparse(true)
+	}
+
+	if s.ExpireAfterSeconds != nil {
+		opts.SetExpireAfterSeconds(*s.ExpireAfterSeconds)
+	}
+
+	if s.PartialFilter != nil {
+		opts.SetPartialFilterExpression(s.PartialFilter)
+	}
+
+	return mongo.IndexModel{Keys: s.Keys, Options: opts}
+}
+
+// IndexView lists, creates and drops the indexes of a collection.
+// It is satisfied by the index view of a collection returned by NewIndexView, and can be replaced by an in-memory stand-in in tests.
+type IndexView interface {
+	List(ctx context.Context) ([]IndexSpec, error)
+	Create(ctx context.Context, specs []IndexSpec) error
+	Drop(ctx context.Context, name string) error
+}
+
+type collectionIndexView struct {
+	view mongo.IndexView
+}
+
+func NewIndexView(view mongo.IndexView) IndexView {
+	return &collectionIndexView{view: view}
+}
+
+// List lists the indexes of the collection.
+func (v *collectionIndexView) List(ctx context.Context) ([]IndexSpec, error) {
+	cur, err := v.view.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]struct {
+		Name                    string ${SymbolBacktick}bson:"name"${SymbolBacktick}
+		Key                     bson.D ${SymbolBacktick}bson:"key"${SymbolBacktick}
+		Unique                  bool   ${SymbolBacktick}bson:"unique"${SymbolBacktick}
+		Sparse                  bool   ${SymbolBacktick}bson:"sparse"${SymbolBacktick}
+		ExpireAfterSeconds      *int32 ${SymbolBacktick}bson:"expireAfterSeconds"${SymbolBacktick}
+		PartialFilterExpression bson.D ${SymbolBacktick}bson:"partialFilterExpression"${SymbolBacktick}
+	}, 0)
+
+	if err = cur.All(ctx, &items); err != nil {
+		return nil, err
+	}
+
+	specs := make([]IndexSpec, 0, len(items))
+	for _, item := range items {
+		specs = append(specs, IndexSpec{
+			Name:               item.Name,
+			Keys:               item.Key,
+			Unique:             item.Unique,
+			Sparse:             item.Sparse,
+			ExpireAfterSeconds: item.ExpireAfterSeconds,
+			PartialFilter:      item.PartialFilterExpression,
+		})
+	}
+
+	return specs, nil
+}
+
+// Create creates the indexes on the collection.
+func (v *collectionIndexView) Create(ctx context.Context, specs []IndexSpec) error {
+	if len(specs) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.IndexModel, 0, len(specs))
+	for _, spec := range specs {
+		models = append(models, spec.Model())
+	}
+
+	_, err := v.view.CreateMany(ctx, models)
+	return err
+}
+
+// Drop drops the index from the collection.
+func (v *collectionIndexView) Drop(ctx context.Context, name string) error {
+	_, err := v.view.DropOne(ctx, name)
+	return err
+}
+
+// IndexChange is an index whose keys or options differ between the code and the collection.
+type IndexChange struct {
+	Declared IndexSpec
+	Actual   IndexSpec
+}
+
+// IndexDiff is the difference between the declared indexes and the indexes of a collection.
+type IndexDiff struct {
+	Missing []IndexSpec   // declared in code but missing from the collection
+	Extra   []IndexSpec   // present in the collection but not declared in code
+	Changed []IndexChange // declared in code with different keys or options
+}
+
+// IsEmpty reports whether the declared indexes match the indexes of the collection.
+func (d *IndexDiff) IsEmpty() bool {
+	return len(d.Missing) == 0 && len(d.Extra) == 0 && len(d.Changed) == 0
+}
+
+// DiffIndexes compares the declared indexes with the indexes listed by the index view.
+// Indexes are matched by name first and then by keys, the default _id index is ignored.
+func DiffIndexes(ctx context.Context, view IndexView, declared []IndexSpec) (*IndexDiff, error) {
+	actual, err := view.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		diff      = &IndexDiff{}
+		unmatched = make([]IndexSpec, 0, len(declared))
+		remaining = make(map[string]IndexSpec, len(actual))
+	)
+
+	for _, spec := range actual {
+		if spec.Name != "_id_" {
+			remaining[spec.Name] = spec
+		}
+	}
+
+	for _, spec := range declared {
+		if existing, ok := remaining[spec.Name]; ok {
+			delete(remaining, spec.Name)
+			if !spec.equal(existing) {
+				diff.Changed = append(diff.Changed, IndexChange{Declared: spec, Actual: existing})
+			}
+			continue
+		}
+		unmatched = append(unmatched, spec)
+	}
+
+	for _, spec := range unmatched {
+		matched := false
+		for _, existing := range actual {
+			if _, ok := remaining[existing.Name]; ok && equalKeys(spec.Keys, existing.Keys) {
+				delete(remaining, existing.Name)
+				diff.Changed = append(diff.Changed, IndexChange{Declared: spec, Actual: existing})
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			diff.Missing = append(diff.Missing, spec)
+		}
+	}
+
+	for _, spec := range actual {
+		if existing, ok := remaining[spec.Name]; ok {
+			diff.Extra = append(diff.Extra, existing)
+		}
+	}
+
+	return diff, nil
+}
+
+// SyncIndexes makes the indexes listed by the index view match the declared indexes and returns the difference found before syncing.
+// Changed indexes are dropped and recreated, and extra indexes are only dropped when dropExtra is true.
+func SyncIndexes(ctx context.Context, view IndexView, declared []IndexSpec, dropExtra bool) (*IndexDiff, error) {
+	diff, err := DiffIndexes(ctx, view, declared)
+	if err != nil {
+		return nil, err
+	}
+
+	creates := make([]IndexSpec, 0, len(diff.Missing)+len(diff.Changed))
+	creates = append(creates, diff.Missing...)
+
+	for _, change := range diff.Changed {
+		if err = view.Drop(ctx, change.Actual.Name); err != nil {
+			return nil, err
+		}
+		creates = append(creates, change.Declared)
+	}
+
+	if dropExtra {
+		for _, spec := range diff.Extra {
+			if err = view.Drop(ctx, spec.Name); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err = view.Create(ctx, creates); err != nil {
+		return nil, err
+	}
+
+	return diff, nil
+}
+
+func (s IndexSpec) equal(other IndexSpec) bool {
+	if s.Name != other.Name || s.Unique != other.Unique || s.Sparse != other.Sparse {
+		return false
+	}
+
+	if (s.ExpireAfterSeconds == nil) != (other.ExpireAfterSeconds == nil) {
+		return false
+	}
+
+	if s.ExpireAfterSeconds != nil && *s.ExpireAfterSeconds != *other.ExpireAfterSeconds {
+		return false
+	}
+
+	return equalKeys(s.Keys, other.Keys) && equalDocuments(s.PartialFilter, other.PartialFilter)
+}
+
+// compare the index keys regardless of the numeric types of the directions
+func equalKeys(a, b bson.D) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].Key != b[i].Key || !equalDocuments(bson.D{a[i]}, bson.D{b[i]}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compare the documents regardless of the numeric types of the values
+func equalDocuments(a, b bson.D) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bson.D:
+		items := make(bson.D, 0, len(v))
+		for _, item := range v {
+			items = append(items, bson.E{Key: item.Key, Value: normalize(item.Value)})
+		}
+		return items
+	case bson.A:
+		items := make(bson.A, 0, len(v))
+		for _, item := range v {
+			items = append(items, normalize(item))
+		}
+		return items
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	default:
+		return v
+	}
+}
+
+// ExpireAfter returns the number of seconds after which the documents of a ttl index expire.
+func ExpireAfter(seconds int32) *int32 {
+	return &seconds
+}
+
+type ValidationLevel string
+
+const (
+	ValidationLevelOff      ValidationLevel = "off"
+	ValidationLevelStrict   ValidationLevel = "strict"
+	ValidationLevelModerate ValidationLevel = "moderate"
+)
+
+type ValidationAction string
+
+const (
+	ValidationActionError ValidationAction = "error"
+	ValidationActionWarn  ValidationAction = "warn"
+)
+
+// ApplyValidator sets the json schema validator of the collection, and creates the collection with the validator if it does not exist.
+func ApplyValidator(ctx context.Context, db *mongo.Database, collection string, schema bson.D, level ValidationLevel, action ValidationAction) error {
+	validator := bson.D{{Key: "$jsonSchema", Value: schema}}
+
+	err := db.RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: collection},
+		{Key: "validator", Value: validator},
+		{Key: "validationLevel", Value: string(level)},
+		{Key: "validationAction", Value: string(action)},
+	}).Err()
+
+	var e mongo.CommandError
+	if errors.As(err, &e) && e.Code == 26 {
+		opts := options.CreateCollection().
+			SetValidator(validator).
+			SetValidationLevel(string(level)).
+			SetValidationAction(string(action))
+
+		return db.CreateCollection(ctx, collection, opts)
+	}
+
+	return err
+}
+
+// AndFilter combines the filter with the conditions, the empty filter is replaced by the conditions.
+func AndFilter(filter interface{}, conditions bson.D) interface{} {
+	if isEmptyDocument(filter) {
+		return conditions
+	}
+
+	return bson.D{{Key: "$and", Value: bson.A{filter, conditions}}}
+}
+
+// IncVersion adds the increment of the version column to the update document or the update pipeline.
+func IncVersion(update interface{}, column string) (interface{}, error) {
+	switch doc := update.(type) {
+	case bson.D:
+		return incVersionDocument(doc, column), nil
+	case bson.M:
+		return incVersionDocument(toDocument(doc), column), nil
+	case map[string]interface{}:
+		return incVersionDocument(toDocument(doc), column), nil
+	case mongo.Pipeline:
+		return append(toArray(doc), incVersionStage(column)), nil
+	case bson.A:
+		return append(append(bson.A{}, doc...), incVersionStage(column)), nil
+	case []interface{}:
+		return append(append(bson.A{}, doc...), incVersionStage(column)), nil
+	default:
+		data, err := bson.Marshal(update)
+		if err != nil {
+			return nil, err
+		}
+
+		var d bson.D
+		if err = bson.Unmarshal(data, &d); err != nil {
+			return nil, err
+		}
+
+		return incVersionDocument(d, column), nil
+	}
+}
+
+func incVersionDocument(doc bson.D, column string) bson.D {
+	updated := make(bson.D, 0, len(doc)+1)
+	found := false
+
+	for _, e := range doc {
+		if e.Key == "$inc" {
+			var inc bson.D
+			switch v := e.Value.(type) {
+			case bson.D:
+				inc = append(inc, v...)
+			case bson.M:
+				inc = toDocument(v)
+			case map[string]interface{}:
+				inc = toDocument(v)
+			}
+			e = bson.E{Key: "$inc", Value: append(inc, bson.E{Key: column, Value: 1})}
+			found = true
+		}
+		updated = append(updated, e)
+	}
+
+	if !found {
+		updated = append(updated, bson.E{Key: "$inc", Value: bson.D{{Key: column, Value: 1}}})
+	}
+
+	return updated
+}
+
+func incVersionStage(column string) bson.D {
+	value := bson.D{{Key: "$add", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$" + column, 0}}}, 1}}}
+	return bson.D{{Key: "$set", Value: bson.D{{Key: column, Value: value}}}}
+}
+
+func toDocument(m map[string]interface{}) bson.D {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	doc := make(bson.D, 0, len(m))
+	for _, key := range keys {
+		doc = append(doc, bson.E{Key: key, Value: m[key]})
+	}
+
+	return doc
+}
+
+// Scope selects the documents visible to the operations of a soft deleted model.
+type Scope int
+
+const (
+	ScopeDefault     Scope = iota // exclude the soft deleted documents
+	ScopeWithTrashed              // include the soft deleted documents
+	ScopeOnlyTrashed              // only include the soft deleted documents
+)
+
+// SoftDelete describes the column marking the soft deleted documents.
+type SoftDelete struct {
+	Column string
+	Zero   interface{} // the zero value of the column written by inserts, nil if the column is not a field of the model
+}
+
+// Filter returns the filter of the scope, or nil if the scope does not filter the documents.
+func (s *SoftDelete) Filter(scope Scope) bson.D {
+	if s == nil {
+		return nil
+	}
+
+	values := bson.A{nil}
+	if s.Zero != nil {
+		values = append(values, s.Zero)
+	}
+
+	switch scope {
+	case ScopeDefault:
+		return bson.D{{Key: s.Column, Value: bson.D{{Key: "$in", Value: values}}}}
+	case ScopeOnlyTrashed:
+		return bson.D{{Key: s.Column, Value: bson.D{{Key: "$nin", Value: values}}}}
+	default:
+		return nil
+	}
+}
+
+// Apply combines the filter with the filter of the scope.
+func (s *SoftDelete) Apply(filter interface{}, scope Scope) interface{} {
+	scoped := s.Filter(scope)
+	if scoped == nil {
+		return filter
+	}
+
+	return AndFilter(filter, scoped)
+}
+
+// ApplyPipeline prepends the filter of the scope to the pipeline as a match stage.
+func (s *SoftDelete) ApplyPipeline(pipeline interface{}, scope Scope) interface{} {
+	scoped := s.Filter(scope)
+	if scoped == nil {
+		return pipeline
+	}
+
+	stages := bson.A{bson.D{{Key: "$match", Value: scoped}}}
+
+	if pipeline != nil {
+		v := reflect.ValueOf(pipeline)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return pipeline
+		}
+
+		for i := 0; i < v.Len(); i++ {
+			stages = append(stages, v.Index(i).Interface())
+		}
+	}
+
+	return stages
+}
+
+// DeleteOne marks at most one document matching the filter as deleted, the options of the delete apply to the update marking it.
+func (s *SoftDelete) DeleteOne(ctx context.Context, collection *mongo.Collection, filter interface{}, opts *options.DeleteOptions) (*mongo.DeleteResult, error) {
+	result, err := collection.UpdateOne(ctx, filter, s.deletion(), s.updateOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	return &mongo.DeleteResult{DeletedCount: result.ModifiedCount}, nil
+}
+
+// DeleteMany marks the documents matching the filter as deleted, the options of the delete apply to the update marking them.
+func (s *SoftDelete) DeleteMany(ctx context.Context, collection *mongo.Collection, filter interface{}, opts *options.DeleteOptions) (*mongo.DeleteResult, error) {
+	result, err := collection.UpdateMany(ctx, filter, s.deletion(), s.updateOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	return &mongo.DeleteResult{DeletedCount: result.ModifiedCount}, nil
+}
+
+// Restore clears the deletion mark of the documents matching the filter.
+func (s *SoftDelete) Restore(ctx context.Context, collection *mongo.Collection, filter interface{}) (*mongo.UpdateResult, error) {
+	return collection.UpdateMany(ctx, filter, s.restoration())
+}
+
+func (s *SoftDelete) restoration() bson.D {
+	return bson.D{{Key: "$unset", Value: bson.D{{Key: s.Column, Value: ""}}}}
+}
+
+func (s *SoftDelete) deletion() bson.D {
+	return bson.D{{Key: "$set", Value: bson.D{{Key: s.Column, Value: primitive.NewDateTimeFromTime(time.Now())}}}}
+}
+
+// convert the options of the delete into the options of the update marking the documents
+func (s *SoftDelete) updateOptions(opts *options.DeleteOptions) *options.UpdateOptions {
+	if opts == nil {
+		return nil
+	}
+
+	return &options.UpdateOptions{Collation: opts.Collation, Comment: opts.Comment, Hint: opts.Hint, Let: opts.Let}
+}
+
+// Sort is an ordered list of sort keys which is encoded as a sort document.
+type Sort bson.D
+
+// Then appends the sort keys used to order the documents that are equal by the previous keys.
+func (s Sort) Then(sorts ...Sort) Sort {
+	sort := make(Sort, 0, len(s)+len(sorts))
+	sort = append(sort, s...)
+	for _, item := range sorts {
+		sort = append(sort, item...)
+	}
+
+	return sort
+}
+
+// MarshalBSON encodes the sort keys as a sort document.
+func (s Sort) MarshalBSON() ([]byte, error) {
+	return bson.Marshal(bson.D(s))
+}
+
+// SortField provides the sort directions for a column.
+type SortField struct {
+	name string
+}
+
+func NewSortField(name string) SortField {
+	return SortField{name: name}
+}
+
+// Asc sorts the documents by the column in ascending order.
+func (f SortField) Asc() Sort {
+	return Sort{{Key: f.name, Value: 1}}
+}
+
+// Desc sorts the documents by the column in descending order.
+func (f SortField) Desc() Sort {
+	return Sort{{Key: f.name, Value: -1}}
+}
+
+// ResumeTokenStore persists the resume token of a change stream, so that a restarted watcher continues after the last handled event.
+type ResumeTokenStore interface {
+	// Load returns the saved resume token, nil means the change stream starts from the current time.
+	Load(ctx context.Context) (bson.Raw, error)
+	// Save persists the resume token of the handled event.
+	Save(ctx context.Context, token bson.Raw) error
+}
+
+// UpdateDescription describes the columns changed by an update event, the paths start with the Go field names of the model.
+type UpdateDescription struct {
+	UpdatedFields map[string]bson.RawValue
+	RemovedFields []string
+}
+
+// ChangeEvent is a change event of the collection with the full document decoded into the model.
+// FullDocument is nil for delete events, and for update events unless the full document lookup is enabled by the options.
+type ChangeEvent[T any] struct {
+	ResumeToken       bson.Raw
+	OperationType     string
+	DocumentKey       bson.D
+	FullDocument      *T
+	UpdateDescription *UpdateDescription
+	ClusterTime       primitive.Timestamp
+}
+
+type changeEvent[T any] struct {
+	ID                bson.Raw            ${SymbolBacktick}bson:"_id"${SymbolBacktick}
+	OperationType     string              ${SymbolBacktick}bson:"operationType"${SymbolBacktick}
+	DocumentKey       bson.D              ${SymbolBacktick}bson:"documentKey"${SymbolBacktick}
+	FullDocument      *T                  ${SymbolBacktick}bson:"fullDocument"${SymbolBacktick}
+	ClusterTime       primitive.Timestamp ${SymbolBacktick}bson:"clusterTime"${SymbolBacktick}
+	UpdateDescription *struct {
+		UpdatedFields bson.Raw ${SymbolBacktick}bson:"updatedFields"${SymbolBacktick}
+		RemovedFields []string ${SymbolBacktick}bson:"removedFields"${SymbolBacktick}
+	} ${SymbolBacktick}bson:"updateDescription"${SymbolBacktick}
+}
+
+// ChangeStream iterates over the change events of the collection decoded into ChangeEvent.
+type ChangeStream[T any] struct {
+	stream  *mongo.ChangeStream
+	store   ResumeTokenStore
+	fields  map[string]string
+	event   *ChangeEvent[T]
+	pending bson.Raw
+	err     error
+}
+
+// Watch opens a change stream on the collection. The resume token saved in the store is used to resume the change stream
+// unless the options set where to start, and the columns map the paths of the update descriptions back to the Go field names.
+func Watch[T any](ctx context.Context, collection *mongo.Collection, pipeline interface{}, opts *options.ChangeStreamOptions, store ResumeTokenStore, columns interface{}) (*ChangeStream[T], error) {
+	opts = options.MergeChangeStreamOptions(opts)
+
+	if store != nil && opts.ResumeAfter == nil && opts.StartAfter == nil && opts.StartAtOperationTime == nil {
+		token, err := store.Load(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if token != nil {
+			opts.SetResumeAfter(token)
+		}
+	}
+
+	if pipeline == nil {
+		pipeline = mongo.Pipeline{}
+	}
+
+	stream, err := collection.Watch(ctx, pipeline, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChangeStream[T]{stream: stream, store: store, fields: fieldNames(columns)}, nil
+}
+
+// Next waits for the next event and decodes it, false is returned when the change stream is closed or fails.
+// The resume token of the previous event is saved into the store first, so an event is only marked as handled
+// once the caller asks for the next one.
+func (s *ChangeStream[T]) Next(ctx context.Context) bool {
+	if s.err != nil {
+		return false
+	}
+
+	if s.store != nil && s.pending != nil {
+		if s.err = s.store.Save(ctx, s.pending); s.err != nil {
+			return false
+		}
+		s.pending = nil
+	}
+
+	if !s.stream.Next(ctx) {
+		return false
+	}
+
+	raw := &changeEvent[T]{}
+	if s.err = s.stream.Decode(raw); s.err != nil {
+		return false
+	}
+
+	s.event = &ChangeEvent[T]{
+		ResumeToken:   raw.ID,
+		OperationType: raw.OperationType,
+		DocumentKey:   raw.DocumentKey,
+		FullDocument:  raw.FullDocument,
+		ClusterTime:   raw.ClusterTime,
+	}
+
+	if raw.UpdateDescription != nil {
+		if s.event.UpdateDescription, s.err = s.updateDescription(raw.UpdateDescription.UpdatedFields, raw.UpdateDescription.RemovedFields); s.err != nil {
+			return false
+		}
+	}
+
+	s.pending = raw.ID
+
+	return true
+}
+
+// Event returns the event decoded by the last call of Next.
+func (s *ChangeStream[T]) Event() *ChangeEvent[T] {
+	return s.event
+}
+
+// ResumeToken returns the resume token of the last event returned by the change stream.
+func (s *ChangeStream[T]) ResumeToken() bson.Raw {
+	return s.stream.ResumeToken()
+}
+
+// Err returns the error which stopped the change stream.
+func (s *ChangeStream[T]) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+
+	return s.stream.Err()
+}
+
+// Close closes the change stream, the resume token of the last event is not saved.
+func (s *ChangeStream[T]) Close(ctx context.Context) error {
+	return s.stream.Close(ctx)
+}
+
+func (s *ChangeStream[T]) updateDescription(updated bson.Raw, removed []string) (*UpdateDescription, error) {
+	desc := &UpdateDescription{
+		UpdatedFields: make(map[string]bson.RawValue),
+		RemovedFields: make([]string, 0, len(removed)),
+	}
+
+	elements, err := updated.Elements()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, element := range elements {
+		desc.UpdatedFields[s.fieldPath(element.Key())] = element.Value()
+	}
+
+	for _, path := range removed {
+		desc.RemovedFields = append(desc.RemovedFields, s.fieldPath(path))
+	}
+
+	return desc, nil
+}
+
+// map the column path to the path starting with the Go field name, example: third_platforms.wechat => ThirdPlatforms.wechat
+func (s *ChangeStream[T]) fieldPath(path string) string {
+	if name, ok := s.fields[path]; ok {
+		return name
+	}
+
+	column, rest, found := strings.Cut(path, ".")
+	if name, ok := s.fields[column]; ok && found {
+		return name + "." + rest
+	}
+
+	return path
+}
+
+// resolve the Go field names of the columns struct keyed by the column names
+func fieldNames(columns interface{}) map[string]string {
+	names := make(map[string]string)
+
+	value := reflect.Indirect(reflect.ValueOf(columns))
+	if value.Kind() != reflect.Struct {
+		return names
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		if field := value.Field(i); field.Kind() == reflect.String {
+			names[field.String()] = value.Type().Field(i).Name
+		}
+	}
+
+	return names
+}
+
+// OpKind is the kind of the operation passed through the interceptors, named after the dao method.
+type OpKind string
+
+const (
+	OpCount          OpKind = "Count"
+	OpEstimatedCount OpKind = "EstimatedCount"
+	OpExists         OpKind = "Exists"
+	OpDistinct       OpKind = "Distinct"
+	OpAggregate      OpKind = "Aggregate"
+	OpWatch          OpKind = "Watch"
+	OpInsertOne      OpKind = "InsertOne"
+	OpInsertMany     OpKind = "InsertMany"
+	OpUpdateOne      OpKind = "UpdateOne"
+	OpUpdateMany     OpKind = "UpdateMany"
+	OpReplaceOne     OpKind = "ReplaceOne"
+	OpFindOne        OpKind = "FindOne"
+	OpFindMany       OpKind = "FindMany"
+	OpDeleteOne      OpKind = "DeleteOne"
+	OpDeleteMany     OpKind = "DeleteMany"
+	OpRestore        OpKind = "Restore"
+	OpIncr           OpKind = "Incr"
+)
+
+// OpInfo describes an operation of a dao, the fields which do not apply to the operation are nil.
+type OpInfo struct {
+	Database   string
+	Collection string
+	Operation  OpKind
+	Filter     interface{}
+	Update     interface{}
+	Pipeline   interface{}
+	Document   interface{}   // the document of InsertOne and ReplaceOne
+	Documents  []interface{} // the documents of InsertMany
+}
+
+// Handler executes the operation described by the op.
+type Handler func(ctx context.Context, op OpInfo) error
+
+// Interceptor wraps the operations of the daos, it may change the ctx and the op before calling next, or skip next to cancel the operation.
+type Interceptor func(ctx context.Context, op OpInfo, next Handler) error
+
+var interceptors []Interceptor
+
+// Use appends the interceptors to the chain shared by all daos, the first interceptor is the outermost one.
+// Use is not safe for concurrent use with the operations and should be called before the daos are used.
+func Use(items ...Interceptor) {
+	interceptors = append(interceptors, items...)
+}
+
+// Intercept runs the handler through the chain of the interceptors.
+func Intercept(ctx context.Context, op OpInfo, handler Handler) error {
+	chain := interceptors
+
+	var next func(i int) Handler
+	next = func(i int) Handler {
+		if i == len(chain) {
+			return handler
+		}
+
+		return func(ctx context.Context, op OpInfo) error {
+			return chain[i](ctx, op, next(i+1))
+		}
+	}
+
+	return next(0)(ctx, op)
+}
+
+// Invoke runs the handler through the chain of the interceptors and returns the result of the handler.
+func Invoke[R any](ctx context.Context, op OpInfo, handler func(ctx context.Context, op OpInfo) (R, error)) (R, error) {
+	var result R
+
+	err := Intercept(ctx, op, func(ctx context.Context, op OpInfo) (err error) {
+		result, err = handler(ctx, op)
+		return
+	})
+
+	return result, err
+}
+
+// SlowQueryOptions configures the logger of the slow operations.
+type SlowQueryOptions struct {
+	Threshold  time.Duration // the operations taking at least the threshold are logged
+	ShowValues bool          // log the values of the filters, the values of the sensitive columns are redacted anyway
+}
+
+const (
+	daoPackagePath = "${VarDaoPackagePath}"
+	redactedValue  = "[REDACTED]"
+	maskedValue    = "?"
+)
+
+var sensitiveColumns sync.Map
+
+// RegisterSensitiveColumns marks the columns of the collection whose values never appear in the logs of the daos.
+func RegisterSensitiveColumns(collection string, columns ...string) {
+	items := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		items[column] = true
+	}
+
+	sensitiveColumns.Store(collection, items)
+}
+
+// IsSensitiveColumn reports whether the path is a sensitive column of the collection or a path inside it.
+func IsSensitiveColumn(collection, path string) bool {
+	items, ok := sensitiveColumns.Load(collection)
+	if !ok {
+		return false
+	}
+
+	for {
+		if items.(map[string]bool)[path] {
+			return true
+		}
+
+		i := strings.LastIndexByte(path, '.')
+		if i < 0 {
+			return false
+		}
+		path = path[:i]
+	}
+}
+
+// RedactFilter renders the shape of the filter or the pipeline as extended json, the values are replaced with ?
+// unless showValues is set, and the values of the sensitive columns of the collection are always redacted.
+func RedactFilter(collection string, filter interface{}, showValues bool) string {
+	switch filter.(type) {
+	case bson.D, bson.M, map[string]interface{}, bson.A, []interface{}, []bson.D, mongo.Pipeline:
+	default:
+		data, err := bson.Marshal(filter)
+		if err != nil {
+			return maskedValue
+		}
+
+		doc := bson.D{}
+		if err = bson.Unmarshal(data, &doc); err != nil {
+			return maskedValue
+		}
+		filter = doc
+	}
+
+	data, err := bson.MarshalExtJSON(bson.D{{Key: "v", Value: redact(collection, "", filter, showValues)}}, false, false)
+	if err != nil {
+		return maskedValue
+	}
+
+	return strings.TrimSuffix(strings.TrimPrefix(string(data), "{\"v\":"), "}")
+}
+
+func redact(collection, path string, value interface{}, showValues bool) interface{} {
+	switch v := value.(type) {
+	case bson.D:
+		doc := make(bson.D, 0, len(v))
+		for _, e := range v {
+			p := path
+			if !strings.HasPrefix(e.Key, "$") {
+				if p != "" {
+					p += "."
+				}
+				p += e.Key
+
+				if IsSensitiveColumn(collection, p) {
+					doc = append(doc, bson.E{Key: e.Key, Value: redactedValue})
+					continue
+				}
+			}
+
+			doc = append(doc, bson.E{Key: e.Key, Value: redact(collection, p, e.Value, showValues)})
+		}
+		return doc
+	case bson.M:
+		return redact(collection, path, toDocument(v), showValues)
+	case map[string]interface{}:
+		return redact(collection, path, toDocument(v), showValues)
+	case bson.A:
+		return redactArray(collection, path, v, showValues)
+	case []interface{}:
+		return redactArray(collection, path, v, showValues)
+	case mongo.Pipeline:
+		return redact(collection, path, toArray(v), showValues)
+	case []bson.D:
+		return redact(collection, path, toArray(v), showValues)
+	default:
+		if showValues {
+			return v
+		}
+		return maskedValue
+	}
+}
+
+func redactArray(collection, path string, items []interface{}, showValues bool) bson.A {
+	arr := make(bson.A, 0, len(items))
+	for _, item := range items {
+		arr = append(arr, redact(collection, path, item, showValues))
+	}
+
+	return arr
+}
+
+// resolve the code which called the operation, the first frame outside the dao packages after the outermost interceptor chain
+func slowQueryCaller() string {
+	var (
+		pcs    = make([]uintptr, 64)
+		frames = runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+		caller string
+	)
+
+	for {
+		frame, more := frames.Next()
+
+		switch {
+		case strings.HasPrefix(frame.Function, daoPackagePath+"/internal.Invoke"), strings.HasPrefix(frame.Function, daoPackagePath+"/internal.Intercept"):
+			caller = ""
+		case caller == "" && !strings.HasPrefix(frame.Function, daoPackagePath+".") && !strings.HasPrefix(frame.Function, daoPackagePath+"/"):
+			caller = fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+
+		if !more {
+			return caller
+		}
+	}
+}
+
+// TxMaxAttempts is the maximum number of times WithTx runs a transaction failed with a transient error.
+var TxMaxAttempts = 5
+
+// WithTx runs the function in a transaction on a new session, and commits the transaction when the function succeeds.
+// The transaction is run again when it fails with a transient transaction error, and the commit is retried when its result is unknown,
+// so the function must be safe to run more than once. The txCtx must be passed to the dao methods to join the transaction.
+// The function joins the transaction of the ctx instead of starting a new one when the ctx already carries a session.
+func WithTx(ctx context.Context, client *mongo.Client, fn func(txCtx context.Context) error, opts ...*options.TransactionOptions) error {
+	if mongo.SessionFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	session, err := client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	txOpts := options.MergeTransactionOptions(opts...)
+
+	for attempt := 1; ; attempt++ {
+		err = mongo.WithSession(ctx, session, func(sc mongo.SessionContext) error {
+			if err := session.StartTransaction(txOpts); err != nil {
+				return err
+			}
+
+			if err := fn(sc); err != nil {
+				_ = session.AbortTransaction(sc)
+				return err
+			}
+
+			return commitTx(sc, session)
+		})
+		if err == nil || attempt >= TxMaxAttempts || ctx.Err() != nil || !hasErrorLabel(err, "TransientTransactionError") {
+			return err
+		}
+	}
+}
+
+// commit the transaction, the commit is retried while its result is unknown
+func commitTx(ctx context.Context, session mongo.Session) error {
+	for attempt := 1; ; attempt++ {
+		err := session.CommitTransaction(ctx)
+		if err == nil || attempt >= TxMaxAttempts || ctx.Err() != nil || !hasErrorLabel(err, "UnknownTransactionCommitResult") {
+			return err
+		}
+	}
+}
+
+func hasErrorLabel(err error, label string) bool {
+	var labeled interface{ HasErrorLabel(string) bool }
+	return errors.As(err, &labeled) && labeled.HasErrorLabel(label)
+}
+
+func join(operator string, filters []bson.D) bson.D {
+	switch len(filters) {
+	case 0:
+		return bson.D{}
+	case 1:
+		return filters[0]
+	default:
+		return bson.D{{Key: operator, Value: toArray(filters)}}
+	}
+}
+
+func toArray(filters []bson.D) bson.A {
+	items := make(bson.A, 0, len(filters))
+	for _, filter := range filters {
+		items = append(items, filter)
+	}
+
+	return items
+}
+`
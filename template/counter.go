@@ -27,11 +27,7 @@ const CounterInternalTemplate = `
 package internal
 
 import (
-	"context"
-	"errors"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	${VarPackages}
 )
 
 type ${VarDaoClassName} struct {
@@ -79,19 +75,31 @@ func (dao *${VarDaoClassName}) Incr(ctx context.Context, key string, incr ...int
 		value = incr[0]
 	}
 
-	rst := dao.Collection.FindOneAndUpdate(ctx, bson.M{
-		dao.Columns.ID: key,
-	}, bson.M{"$inc": bson.M{
-		dao.Columns.Value: value,
-	}}, &options.FindOneAndUpdateOptions{
-		Upsert:         &upsert,
-		ReturnDocument: &returnDocument,
-	})
+	// the counter is excluded from the transaction of the ctx, the counter document is shared by all writers
+	// and would serialize the concurrent transactions, so the values consumed by aborted transactions are skipped
+	if mongo.SessionFromContext(ctx) != nil {
+		ctx = mongo.NewSessionContext(ctx, nil)
+	}
 
-	if err := rst.Decode(counter); err != nil {
-		return 0, err
+	op := ${VarCommonPrefix}OpInfo{
+		Database:   dao.Database.Name(),
+		Collection: dao.Collection.Name(),
+		Operation:  ${VarCommonPrefix}OpIncr,
+		Filter:     bson.M{dao.Columns.ID: key},
+		Update:     bson.M{"$inc": bson.M{dao.Columns.Value: value}},
 	}
 
-	return counter.Value, nil
+	return ${VarCommonPrefix}Invoke(ctx, op, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (int64, error) {
+		rst := dao.Collection.FindOneAndUpdate(ctx, op.Filter, op.Update, &options.FindOneAndUpdateOptions{
+			Upsert:         &upsert,
+			ReturnDocument: &returnDocument,
+		})
+
+		if err := rst.Decode(counter); err != nil {
+			return 0, err
+		}
+
+		return counter.Value, nil
+	})
 }
 `
@@ -0,0 +1,14 @@
+package template
+
+const EnumTemplate = `
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+package ${VarModelPackageName}
+
+${VarPackages}
+${VarEnumMethods}
+`
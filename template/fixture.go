@@ -0,0 +1,218 @@
+package template
+
+const FixtureInternalTemplate = `
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Sequence returns a value func of the factories which formats the sequence number of the built model, such as Sequence("user%d").
+func Sequence(format string) func(seq int64) string {
+	return func(seq int64) string {
+		return fmt.Sprintf(format, seq)
+	}
+}
+
+// Fixed returns a value func of the factories which always returns the value.
+func Fixed[T any](value T) func(seq int64) T {
+	return func(seq int64) T {
+		return value
+	}
+}
+
+// FixtureString pads the value with x or truncates it to fit the length bounds of a field, a negative bound means no bound.
+func FixtureString(value string, minLen, maxLen int) string {
+	if n := utf8.RuneCountInString(value); minLen >= 0 && n < minLen {
+		value += strings.Repeat("x", minLen-n)
+	}
+
+	if maxLen >= 0 && utf8.RuneCountInString(value) > maxLen {
+		value = string([]rune(value)[:maxLen])
+	}
+
+	return value
+}
+
+// FixtureDocuments returns the documents listed under the collection in the fixture data as extended json.
+// The fixture data is an object keyed by the collection names, whose values are the arrays of the documents.
+// The data is decoded by the unmarshal func, such as yaml.Unmarshal of gopkg.in/yaml.v3, nil means json.Unmarshal.
+func FixtureDocuments(data []byte, unmarshal func(data []byte, v interface{}) error, collection string) ([][]byte, error) {
+	if unmarshal == nil {
+		unmarshal = json.Unmarshal
+	}
+
+	fixtures := make(map[string][]interface{})
+	if err := unmarshal(data, &fixtures); err != nil {
+		return nil, err
+	}
+
+	documents := make([][]byte, 0, len(fixtures[collection]))
+	for i, fixture := range fixtures[collection] {
+		document, err := json.Marshal(fixture)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d]: %w", collection, i, err)
+		}
+
+		documents = append(documents, document)
+	}
+
+	return documents, nil
+}
+`
+
+const FixtureExternalTemplate = `
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+package ${VarDaoPackageName}
+
+import (
+	"${VarDaoPackagePath}/internal"
+)
+
+// Sequence returns a value func of the factories which formats the sequence number of the built model, such as Sequence("user%d").
+func Sequence(format string) func(seq int64) string {
+	return internal.Sequence(format)
+}
+
+// Fixed returns a value func of the factories which always returns the value.
+func Fixed[T any](value T) func(seq int64) T {
+	return internal.Fixed(value)
+}
+`
+
+const FactoryTemplate = `
+
+// ${VarDaoPrefixName}Factory builds the models with default values for the tests.
+// The models are numbered by a sequence starting at 1, which is shared by the copies of the factory.
+type ${VarDaoPrefixName}Factory struct {
+	seq       *int64
+	overrides []func(model *${VarModelPackageName}.${VarModelClassName}, seq int64)
+}
+
+// New${VarDaoPrefixName}Factory creates a factory with a new sequence.
+func New${VarDaoPrefixName}Factory() *${VarDaoPrefixName}Factory {
+	return &${VarDaoPrefixName}Factory{seq: new(int64)}
+}
+
+// With returns a copy of the factory which applies the override to the built models after the default values.
+func (f *${VarDaoPrefixName}Factory) With(override func(model *${VarModelPackageName}.${VarModelClassName}, seq int64)) *${VarDaoPrefixName}Factory {
+	overrides := make([]func(model *${VarModelPackageName}.${VarModelClassName}, seq int64), 0, len(f.overrides)+1)
+	overrides = append(append(overrides, f.overrides...), override)
+
+	return &${VarDaoPrefixName}Factory{seq: f.seq, overrides: overrides}
+}
+
+${VarFactoryMethods}
+
+// Build returns a model with the default values and the overrides of the factory.
+// The autofill fields are left to the dao, except when they are overridden.
+func (f *${VarDaoPrefixName}Factory) Build() *${VarModelPackageName}.${VarModelClassName} {
+	seq := atomic.AddInt64(f.seq, 1)
+
+	model := &${VarModelPackageName}.${VarModelClassName}{}
+	${VarFactoryDefaults}
+
+	for _, override := range f.overrides {
+		override(model, seq)
+	}
+
+	return model
+}
+
+// BuildMany returns n models built by Build.
+func (f *${VarDaoPrefixName}Factory) BuildMany(n int) []*${VarModelPackageName}.${VarModelClassName} {
+	models := make([]*${VarModelPackageName}.${VarModelClassName}, 0, n)
+	for i := 0; i < n; i++ {
+		models = append(models, f.Build())
+	}
+
+	return models
+}
+
+// Create builds n models and inserts them through the dao.
+func (f *${VarDaoPrefixName}Factory) Create(ctx context.Context, dao ${VarDaoPrefixName}Repository, n int) ([]*${VarModelPackageName}.${VarModelClassName}, error) {
+	models := f.BuildMany(n)
+	if len(models) == 0 {
+		return models, nil
+	}
+
+	if _, err := dao.InsertMany(ctx, models); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+// Load inserts the documents listed under "${VarCollectionName}" in the fixture data through the dao.
+// Each document is decoded as extended json over a model built by the factory, so the missing columns keep the default values.
+// The data is decoded by the unmarshal func, such as yaml.Unmarshal of gopkg.in/yaml.v3, nil means json.Unmarshal.
+func (f *${VarDaoPrefixName}Factory) Load(ctx context.Context, dao ${VarDaoPrefixName}Repository, data []byte, unmarshal func(data []byte, v interface{}) error) ([]*${VarModelPackageName}.${VarModelClassName}, error) {
+	documents, err := ${VarCommonPrefix}FixtureDocuments(data, unmarshal, "${VarCollectionName}")
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]*${VarModelPackageName}.${VarModelClassName}, 0, len(documents))
+	for i, document := range documents {
+		model := f.Build()
+		if err = bson.UnmarshalExtJSON(document, false, model); err != nil {
+			return nil, fmt.Errorf("${VarCollectionName}[%d]: %w", i, err)
+		}
+
+		models = append(models, model)
+	}
+
+	if len(models) == 0 {
+		return models, nil
+	}
+
+	if _, err = dao.InsertMany(ctx, models); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+`
+
+const FactoryFieldTemplate = `
+// With${VarFieldName} returns a copy of the factory which sets ${VarFieldName} to the value for the sequence of the built model.
+func (f *${VarDaoPrefixName}Factory) With${VarFieldName}(value func(seq int64) ${VarFieldType}) *${VarDaoPrefixName}Factory {
+	return f.With(func(model *${VarModelPackageName}.${VarModelClassName}, seq int64) {
+		model.${VarFieldName} = value(seq)
+	})
+}`
+
+const FactoryExternalTemplate = `
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+package ${VarDaoPackageName}
+
+import (
+	"${VarDaoPackagePath}/internal"
+)
+
+type ${VarDaoPrefixName}Factory = internal.${VarDaoPrefixName}Factory
+
+// New${VarDaoPrefixName}Factory creates a factory building the models with default values for the tests.
+func New${VarDaoPrefixName}Factory() *${VarDaoPrefixName}Factory {
+	return internal.New${VarDaoPrefixName}Factory()
+}
+`
@@ -0,0 +1,1795 @@
+package template
+
+const MemoryCommonTemplate = `
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrMemoryUnsupported is returned by the in-memory daos for the operations and the operators they do not support.
+var ErrMemoryUnsupported = errors.New("not supported by the memory dao")
+
+// MemoryCollection keeps the documents of an in-memory dao and evaluates a subset of the query language against them.
+// The filters support the equality, $eq, $ne, $gt, $gte, $lt, $lte, $in, $nin, $exists, $regex, $and, $or and $nor,
+// the updates support $set, $setOnInsert, $unset, $inc, $mul, $min, $max, $push, $addToSet, $pop, $pull and $pullAll,
+// and the finds support sort, skip and limit. The unique indexes are enforced with the duplicate key errors of the dao.
+type MemoryCollection struct {
+	mu         sync.Mutex
+	name       string
+	indexes    []IndexSpec
+	fieldNames map[string]string
+	documents  []bson.D
+	counters   map[string]int64
+}
+
+// NewMemoryCollection creates an empty collection which enforces the unique indexes of the specs.
+func NewMemoryCollection(name string, indexes []IndexSpec, fieldNames map[string]string) *MemoryCollection {
+	return &MemoryCollection{
+		name:       name,
+		indexes:    indexes,
+		fieldNames: fieldNames,
+		counters:   make(map[string]int64),
+	}
+}
+
+// Name returns the name of the collection.
+func (c *MemoryCollection) Name() string {
+	return c.name
+}
+
+// Incr increments the local counter of the key and returns the new value, it replaces the counter dao for autoIncr.
+func (c *MemoryCollection) Incr(key string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counters[key]++
+
+	return c.counters[key], nil
+}
+
+// InsertOne inserts the document, the document violating a unique index is rejected with a DuplicateKeyError.
+func (c *MemoryCollection) InsertOne(document interface{}) (*mongo.InsertOneResult, error) {
+	doc, err := memoryDocument(document)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id, err := c.insert(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mongo.InsertOneResult{InsertedID: id}, nil
+}
+
+// InsertMany inserts the documents in order and stops at the first document which fails.
+func (c *MemoryCollection) InsertMany(documents []interface{}) (*mongo.InsertManyResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := &mongo.InsertManyResult{}
+	for _, document := range documents {
+		doc, err := memoryDocument(document)
+		if err != nil {
+			return result, err
+		}
+
+		id, err := c.insert(doc)
+		if err != nil {
+			return result, err
+		}
+
+		result.InsertedIDs = append(result.InsertedIDs, id)
+	}
+
+	return result, nil
+}
+
+// FindOne returns the first document matching the filter in the order of the options, or nil if no document matches.
+func (c *MemoryCollection) FindOne(filter interface{}, opts *options.FindOneOptions) (bson.Raw, error) {
+	opts = options.MergeFindOneOptions(opts)
+
+	findOpts := &options.FindOptions{Sort: opts.Sort, Skip: opts.Skip}
+
+	documents, err := c.Find(filter, findOpts.SetLimit(1))
+	if err != nil || len(documents) == 0 {
+		return nil, err
+	}
+
+	return documents[0], nil
+}
+
+// Find returns the documents matching the filter, ordered, skipped and limited by the options.
+func (c *MemoryCollection) Find(filter interface{}, opts *options.FindOptions) ([]bson.Raw, error) {
+	opts = options.MergeFindOptions(opts)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	matched, err := c.find(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Sort != nil {
+		if err = c.sort(matched, opts.Sort); err != nil {
+			return nil, err
+		}
+	}
+
+	matched = memoryPage(matched, opts.Skip, opts.Limit)
+
+	documents := make([]bson.Raw, 0, len(matched))
+	for _, i := range matched {
+		data, err := bson.Marshal(c.documents[i])
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, data)
+	}
+
+	return documents, nil
+}
+
+// Count returns the number of the documents matching the filter, skipped and limited by the options.
+func (c *MemoryCollection) Count(filter interface{}, opts *options.CountOptions) (int64, error) {
+	opts = options.MergeCountOptions(opts)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	matched, err := c.find(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(memoryPage(matched, opts.Skip, opts.Limit))), nil
+}
+
+// EstimatedCount returns the number of the documents in the collection.
+func (c *MemoryCollection) EstimatedCount() (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return int64(len(c.documents)), nil
+}
+
+// Distinct returns the unique values of the column in the documents matching the filter, the arrays are flattened.
+func (c *MemoryCollection) Distinct(column string, filter interface{}) ([]interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	matched, err := c.find(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, 0)
+	for _, i := range matched {
+		for _, value := range memoryLookup(c.documents[i], strings.Split(column, ".")) {
+			if _, ok := value.(bson.A); ok || memoryContains(values, value) {
+				continue
+			}
+			values = append(values, value)
+		}
+	}
+
+	return values, nil
+}
+
+// UpdateOne applies the update to the first document matching the filter, or inserts a document if upsert is set.
+func (c *MemoryCollection) UpdateOne(filter interface{}, update interface{}, upsert bool) (*mongo.UpdateResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.update(filter, update, upsert, false)
+}
+
+// UpdateMany applies the update to the documents matching the filter, or inserts a document if upsert is set.
+func (c *MemoryCollection) UpdateMany(filter interface{}, update interface{}, upsert bool) (*mongo.UpdateResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.update(filter, update, upsert, true)
+}
+
+// ReplaceOne replaces the first document matching the filter and keeps its id, or inserts the document if upsert is set.
+func (c *MemoryCollection) ReplaceOne(filter interface{}, replacement interface{}, upsert bool) (*mongo.UpdateResult, error) {
+	doc, err := memoryDocument(replacement)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	matched, err := c.find(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(matched) == 0 {
+		if !upsert {
+			return &mongo.UpdateResult{}, nil
+		}
+
+		if _, ok := memoryGet(doc, "_id"); !ok {
+			if id, ok := memoryGet(memoryUpsertDocument(filter), "_id"); ok {
+				doc = append(bson.D{{Key: "_id", Value: id}}, doc...)
+			}
+		}
+
+		id, err := c.insert(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		return &mongo.UpdateResult{UpsertedCount: 1, UpsertedID: id}, nil
+	}
+
+	i := matched[0]
+	id, _ := memoryGet(c.documents[i], "_id")
+	doc = append(bson.D{{Key: "_id", Value: id}}, memoryUnset(doc, []string{"_id"})...)
+
+	result := &mongo.UpdateResult{MatchedCount: 1}
+	if !reflect.DeepEqual(doc, c.documents[i]) {
+		if err = c.checkUnique(doc, i); err != nil {
+			return nil, err
+		}
+		c.documents[i] = doc
+		result.ModifiedCount = 1
+	}
+
+	return result, nil
+}
+
+// DeleteOne removes the first document matching the filter.
+func (c *MemoryCollection) DeleteOne(filter interface{}) (*mongo.DeleteResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.delete(filter, false)
+}
+
+// DeleteMany removes the documents matching the filter.
+func (c *MemoryCollection) DeleteMany(filter interface{}) (*mongo.DeleteResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.delete(filter, true)
+}
+
+// SoftDelete marks the first or all the documents matching the filter as deleted.
+func (c *MemoryCollection) SoftDelete(s *SoftDelete, filter interface{}, many bool) (*mongo.DeleteResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result, err := c.update(filter, s.deletion(), false, many)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mongo.DeleteResult{DeletedCount: result.ModifiedCount}, nil
+}
+
+// Restore clears the deletion mark of the documents matching the filter.
+func (c *MemoryCollection) Restore(s *SoftDelete, filter interface{}) (*mongo.UpdateResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.update(filter, s.restoration(), false, true)
+}
+
+// insert the document after the unique indexes are checked, an object id is generated if the document has no id
+func (c *MemoryCollection) insert(doc bson.D) (interface{}, error) {
+	id, ok := memoryGet(doc, "_id")
+	if !ok {
+		id = primitive.NewObjectID()
+		doc = append(bson.D{{Key: "_id", Value: id}}, doc...)
+	}
+
+	if err := c.checkUnique(doc, -1); err != nil {
+		return nil, err
+	}
+
+	c.documents = append(c.documents, doc)
+
+	return id, nil
+}
+
+// apply the update to the matched documents
+func (c *MemoryCollection) update(filter interface{}, update interface{}, upsert bool, many bool) (*mongo.UpdateResult, error) {
+	operators, err := memoryDocument(update)
+	if err != nil {
+		return nil, err
+	}
+
+	matched, err := c.find(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if !many && len(matched) > 1 {
+		matched = matched[:1]
+	}
+
+	result := &mongo.UpdateResult{MatchedCount: int64(len(matched))}
+
+	if len(matched) == 0 {
+		if !upsert {
+			return result, nil
+		}
+
+		doc, err := memoryApply(memoryUpsertDocument(filter), operators, true)
+		if err != nil {
+			return nil, err
+		}
+
+		if result.UpsertedID, err = c.insert(doc); err != nil {
+			return nil, err
+		}
+		result.UpsertedCount = 1
+
+		return result, nil
+	}
+
+	for _, i := range matched {
+		doc, err := memoryDocument(c.documents[i])
+		if err != nil {
+			return nil, err
+		}
+
+		if doc, err = memoryApply(doc, operators, false); err != nil {
+			return nil, err
+		}
+
+		if reflect.DeepEqual(doc, c.documents[i]) {
+			continue
+		}
+
+		if err = c.checkUnique(doc, i); err != nil {
+			return nil, err
+		}
+
+		c.documents[i] = doc
+		result.ModifiedCount++
+	}
+
+	return result, nil
+}
+
+// remove the matched documents
+func (c *MemoryCollection) delete(filter interface{}, many bool) (*mongo.DeleteResult, error) {
+	matched, err := c.find(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if !many && len(matched) > 1 {
+		matched = matched[:1]
+	}
+
+	removed := make(map[int]struct{}, len(matched))
+	for _, i := range matched {
+		removed[i] = struct{}{}
+	}
+
+	documents := make([]bson.D, 0, len(c.documents)-len(matched))
+	for i, doc := range c.documents {
+		if _, ok := removed[i]; !ok {
+			documents = append(documents, doc)
+		}
+	}
+	c.documents = documents
+
+	return &mongo.DeleteResult{DeletedCount: int64(len(matched))}, nil
+}
+
+// find the positions of the documents matching the filter in the order of insertion
+func (c *MemoryCollection) find(filter interface{}) ([]int, error) {
+	conditions, err := memoryDocument(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]int, 0)
+	for i, doc := range c.documents {
+		ok, err := memoryMatch(doc, conditions)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			matched = append(matched, i)
+		}
+	}
+
+	return matched, nil
+}
+
+// order the positions of the documents by the sort keys
+func (c *MemoryCollection) sort(matched []int, keys interface{}) error {
+	sorts, err := memoryDocument(keys)
+	if err != nil {
+		return err
+	}
+
+	orders := make([]int64, 0, len(sorts))
+	for _, key := range sorts {
+		order, ok := memoryInt(key.Value)
+		if !ok {
+			return fmt.Errorf("%w: sort %s", ErrMemoryUnsupported, key.Key)
+		}
+		orders = append(orders, order)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		for k, key := range sorts {
+			path := strings.Split(key.Key, ".")
+
+			result := memoryCompare(memoryFirst(memoryLookup(c.documents[matched[i]], path)), memoryFirst(memoryLookup(c.documents[matched[j]], path)))
+			if orders[k] < 0 {
+				result = -result
+			}
+
+			if result != 0 {
+				return result < 0
+			}
+		}
+
+		return false
+	})
+
+	return nil
+}
+
+// check the unique indexes and the id of the document against the other documents
+func (c *MemoryCollection) checkUnique(doc bson.D, skip int) error {
+	indexes := append([]IndexSpec{{Name: "_id_", Keys: bson.D{{Key: "_id", Value: 1}}, Unique: true}}, c.indexes...)
+
+	for _, index := range indexes {
+		if !index.Unique {
+			continue
+		}
+
+		key, ok := memoryIndexKey(doc, index)
+		if !ok {
+			continue
+		}
+
+		for i, other := range c.documents {
+			if i == skip {
+				continue
+			}
+
+			if otherKey, ok := memoryIndexKey(other, index); ok && memoryEqual(key, otherKey) {
+				return c.duplicateKey(index, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// build the duplicate key error of the driver and convert it into a DuplicateKeyError
+func (c *MemoryCollection) duplicateKey(index IndexSpec, key bson.A) error {
+	values := make([]string, 0, len(index.Keys))
+	for i, k := range index.Keys {
+		if s, ok := key[i].(string); ok {
+			values = append(values, k.Key+": "+strconv.Quote(s))
+		} else {
+			values = append(values, fmt.Sprintf("%s: %v", k.Key, key[i]))
+		}
+	}
+
+	message := fmt.Sprintf("E11000 duplicate key error collection: memory.%s index: %s dup key: { %s }", c.name, index.Name, strings.Join(values, ", "))
+
+	return MapError(mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: message}}}, c.indexes, c.fieldNames)
+}
+
+// convert the value into a document whose nested documents are bson.D and arrays are bson.A
+func memoryDocument(v interface{}) (bson.D, error) {
+	doc := bson.D{}
+
+	if v == nil {
+		return doc, nil
+	}
+
+	data, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = bson.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// the key of the document in the index, false if the document is not indexed by the sparse or partial index
+func memoryIndexKey(doc bson.D, index IndexSpec) (bson.A, bool) {
+	if index.PartialFilter != nil {
+		if ok, err := memoryMatch(doc, index.PartialFilter); err != nil || !ok {
+			return nil, false
+		}
+	}
+
+	var (
+		key    = make(bson.A, 0, len(index.Keys))
+		exists bool
+	)
+
+	for _, k := range index.Keys {
+		value, ok := memoryGet(doc, k.Key)
+		exists = exists || ok
+		key = append(key, value)
+	}
+
+	if index.Sparse && !exists {
+		return nil, false
+	}
+
+	return key, true
+}
+
+// the document inserted by an upsert, made of the equality conditions of the filter
+func memoryUpsertDocument(filter interface{}) bson.D {
+	doc := bson.D{}
+
+	conditions, err := memoryDocument(filter)
+	if err != nil {
+		return doc
+	}
+
+	var collect func(conditions bson.D)
+	collect = func(conditions bson.D) {
+		for _, e := range conditions {
+			if e.Key == "$and" {
+				items, _ := e.Value.(bson.A)
+				for _, item := range items {
+					if sub, ok := item.(bson.D); ok {
+						collect(sub)
+					}
+				}
+				continue
+			}
+
+			if strings.HasPrefix(e.Key, "$") {
+				continue
+			}
+
+			value := e.Value
+			if operators, ok := value.(bson.D); ok && len(operators) > 0 && strings.HasPrefix(operators[0].Key, "$") {
+				if operators[0].Key != "$eq" {
+					continue
+				}
+				value = operators[0].Value
+			}
+
+			doc = memorySet(doc, strings.Split(e.Key, "."), value)
+		}
+	}
+
+	collect(conditions)
+
+	return doc
+}
+
+func memoryMatch(doc bson.D, conditions bson.D) (bool, error) {
+	for _, e := range conditions {
+		ok, err := memoryMatchCondition(doc, e)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func memoryMatchCondition(doc bson.D, e bson.E) (bool, error) {
+	switch e.Key {
+	case "$and", "$or", "$nor":
+		items, ok := e.Value.(bson.A)
+		if !ok {
+			return false, fmt.Errorf("%s must be an array", e.Key)
+		}
+
+		for _, item := range items {
+			conditions, ok := item.(bson.D)
+			if !ok {
+				return false, fmt.Errorf("%s must be an array of documents", e.Key)
+			}
+
+			matched, err := memoryMatch(doc, conditions)
+			if err != nil {
+				return false, err
+			}
+
+			switch {
+			case e.Key == "$and" && !matched, e.Key == "$nor" && matched:
+				return false, nil
+			case e.Key == "$or" && matched:
+				return true, nil
+			}
+		}
+
+		return e.Key != "$or", nil
+	}
+
+	if strings.HasPrefix(e.Key, "$") {
+		return false, fmt.Errorf("%w: %s", ErrMemoryUnsupported, e.Key)
+	}
+
+	values := memoryLookup(doc, strings.Split(e.Key, "."))
+
+	operators, ok := e.Value.(bson.D)
+	if !ok || len(operators) == 0 || !strings.HasPrefix(operators[0].Key, "$") {
+		return memoryAny(values, e.Value), nil
+	}
+
+	for _, operator := range operators {
+		ok, err := memoryMatchOperator(values, operator, operators)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func memoryMatchOperator(values []interface{}, operator bson.E, operators bson.D) (bool, error) {
+	switch operator.Key {
+	case "$eq":
+		return memoryAny(values, operator.Value), nil
+	case "$ne":
+		return !memoryAny(values, operator.Value), nil
+	case "$gt", "$gte", "$lt", "$lte":
+		for _, value := range values {
+			if memoryClass(value) != memoryClass(operator.Value) {
+				continue
+			}
+
+			result := memoryCompare(value, operator.Value)
+			switch {
+			case operator.Key == "$gt" && result > 0, operator.Key == "$gte" && result >= 0,
+				operator.Key == "$lt" && result < 0, operator.Key == "$lte" && result <= 0:
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$in", "$nin":
+		items, ok := operator.Value.(bson.A)
+		if !ok {
+			return false, fmt.Errorf("%s must be an array", operator.Key)
+		}
+
+		in := false
+		for _, item := range items {
+			if memoryAny(values, item) {
+				in = true
+				break
+			}
+		}
+		return in == (operator.Key == "$in"), nil
+	case "$exists":
+		return (len(values) > 0) == memoryTruthy(operator.Value), nil
+	case "$regex":
+		var pattern, flags string
+
+		switch v := operator.Value.(type) {
+		case string:
+			pattern = v
+		case primitive.Regex:
+			pattern, flags = v.Pattern, v.Options
+		default:
+			return false, fmt.Errorf("$regex must be a string")
+		}
+
+		for _, e := range operators {
+			if s, ok := e.Value.(string); ok && e.Key == "$options" {
+				flags = s
+			}
+		}
+
+		if flags = strings.Map(func(r rune) rune {
+			if strings.ContainsRune("ims", r) {
+				return r
+			}
+			return -1
+		}, flags); flags != "" {
+			pattern = "(?" + flags + ")" + pattern
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, err
+		}
+
+		for _, value := range values {
+			if s, ok := value.(string); ok && re.MatchString(s) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "$options":
+		return true, nil
+	default:
+		return false, fmt.Errorf("%w: %s", ErrMemoryUnsupported, operator.Key)
+	}
+}
+
+// apply the update operators to the document, the $setOnInsert operator is only applied by the upserts
+func memoryApply(doc bson.D, operators bson.D, insert bool) (bson.D, error) {
+	if len(operators) == 0 || !strings.HasPrefix(operators[0].Key, "$") {
+		return nil, errors.New("update document must contain update operators")
+	}
+
+	for _, operator := range operators {
+		fields, ok := operator.Value.(bson.D)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a document", operator.Key)
+		}
+
+		for _, field := range fields {
+			var (
+				path       = strings.Split(field.Key, ".")
+				current, _ = memoryGet(doc, field.Key)
+			)
+
+			switch operator.Key {
+			case "$set":
+				doc = memorySet(doc, path, field.Value)
+			case "$setOnInsert":
+				if insert {
+					doc = memorySet(doc, path, field.Value)
+				}
+			case "$unset":
+				doc = memoryUnset(doc, path)
+			case "$inc", "$mul":
+				value, err := memoryArithmetic(operator.Key, current, field.Value)
+				if err != nil {
+					return nil, err
+				}
+				doc = memorySet(doc, path, value)
+			case "$min", "$max":
+				_, exists := memoryGet(doc, field.Key)
+				result := memoryCompare(field.Value, current)
+				if !exists || (operator.Key == "$min" && result < 0) || (operator.Key == "$max" && result > 0) {
+					doc = memorySet(doc, path, field.Value)
+				}
+			case "$push", "$addToSet", "$pop", "$pull", "$pullAll":
+				items, ok := current.(bson.A)
+				if !ok && current != nil {
+					return nil, fmt.Errorf("cannot apply %s to the non-array field %s", operator.Key, field.Key)
+				}
+
+				items, err := memoryArray(operator.Key, items, field.Value)
+				if err != nil {
+					return nil, err
+				}
+				doc = memorySet(doc, path, items)
+			default:
+				return nil, fmt.Errorf("%w: %s", ErrMemoryUnsupported, operator.Key)
+			}
+		}
+	}
+
+	return doc, nil
+}
+
+func memoryArray(operator string, items bson.A, value interface{}) (bson.A, error) {
+	switch operator {
+	case "$push", "$addToSet":
+		values := bson.A{value}
+		if modifiers, ok := value.(bson.D); ok && len(modifiers) > 0 && modifiers[0].Key == "$each" {
+			if len(modifiers) > 1 {
+				return nil, fmt.Errorf("%w: %s", ErrMemoryUnsupported, modifiers[1].Key)
+			}
+			values, _ = modifiers[0].Value.(bson.A)
+		}
+
+		for _, v := range values {
+			if operator == "$push" || !memoryContains(items, v) {
+				items = append(items, v)
+			}
+		}
+	case "$pop":
+		if len(items) > 0 {
+			if n, _ := memoryInt(value); n < 0 {
+				items = items[1:]
+			} else {
+				items = items[:len(items)-1]
+			}
+		}
+	case "$pull", "$pullAll":
+		values := bson.A{value}
+		if operator == "$pullAll" {
+			values, _ = value.(bson.A)
+		} else if conditions, ok := value.(bson.D); ok && len(conditions) > 0 && strings.HasPrefix(conditions[0].Key, "$") {
+			return nil, fmt.Errorf("%w: $pull with %s", ErrMemoryUnsupported, conditions[0].Key)
+		}
+
+		kept := make(bson.A, 0, len(items))
+		for _, item := range items {
+			if !memoryContains(values, item) {
+				kept = append(kept, item)
+			}
+		}
+		items = kept
+	}
+
+	return items, nil
+}
+
+func memoryArithmetic(operator string, current interface{}, operand interface{}) (interface{}, error) {
+	if current == nil {
+		current = int32(0)
+		if operator == "$mul" {
+			operand = memoryZero(operand)
+		}
+	}
+
+	if memoryClass(current) != memoryClass(int32(0)) || memoryClass(operand) != memoryClass(int32(0)) {
+		return nil, fmt.Errorf("cannot apply %s to the non-numeric value %v", operator, current)
+	}
+
+	a, aok := memoryInt(current)
+	b, bok := memoryInt(operand)
+	_, af := current.(float64)
+	_, bf := operand.(float64)
+
+	if aok && bok && !af && !bf {
+		result := a + b
+		if operator == "$mul" {
+			result = a * b
+		}
+
+		_, a32 := current.(int32)
+		_, b32 := operand.(int32)
+		if a32 && b32 && int64(int32(result)) == result {
+			return int32(result), nil
+		}
+
+		return result, nil
+	}
+
+	x, y := memoryFloat(current), memoryFloat(operand)
+	if operator == "$mul" {
+		return x * y, nil
+	}
+
+	return x + y, nil
+}
+
+func memoryZero(v interface{}) interface{} {
+	switch v.(type) {
+	case int64:
+		return int64(0)
+	case float64:
+		return float64(0)
+	default:
+		return int32(0)
+	}
+}
+
+// the values of the path in the document, the arrays on the path are expanded
+func memoryLookup(value interface{}, path []string) []interface{} {
+	if len(path) == 0 {
+		if items, ok := value.(bson.A); ok {
+			return append([]interface{}{items}, items...)
+		}
+		return []interface{}{value}
+	}
+
+	switch v := value.(type) {
+	case bson.D:
+		for _, e := range v {
+			if e.Key == path[0] {
+				return memoryLookup(e.Value, path[1:])
+			}
+		}
+	case bson.A:
+		if i, err := strconv.Atoi(path[0]); err == nil {
+			if i >= 0 && i < len(v) {
+				return memoryLookup(v[i], path[1:])
+			}
+			return nil
+		}
+
+		values := make([]interface{}, 0)
+		for _, item := range v {
+			if _, ok := item.(bson.D); ok {
+				values = append(values, memoryLookup(item, path)...)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
+
+func memoryGet(doc bson.D, path string) (interface{}, bool) {
+	var value interface{} = doc
+
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case bson.D:
+			found := false
+			for _, e := range v {
+				if e.Key == key {
+					value, found = e.Value, true
+					break
+				}
+			}
+			if !found {
+				return nil, false
+			}
+		case bson.A:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+func memorySet(doc bson.D, path []string, value interface{}) bson.D {
+	for i, e := range doc {
+		if e.Key != path[0] {
+			continue
+		}
+
+		if len(path) == 1 {
+			doc[i].Value = value
+			return doc
+		}
+
+		if items, ok := e.Value.(bson.A); ok {
+			if j, err := strconv.Atoi(path[1]); err == nil && j >= 0 && j < len(items) {
+				if len(path) == 2 {
+					items[j] = value
+				} else {
+					child, _ := items[j].(bson.D)
+					items[j] = memorySet(child, path[2:], value)
+				}
+				return doc
+			}
+		}
+
+		child, _ := e.Value.(bson.D)
+		doc[i].Value = memorySet(child, path[1:], value)
+		return doc
+	}
+
+	if len(path) == 1 {
+		return append(doc, bson.E{Key: path[0], Value: value})
+	}
+
+	return append(doc, bson.E{Key: path[0], Value: memorySet(bson.D{}, path[1:], value)})
+}
+
+func memoryUnset(doc bson.D, path []string) bson.D {
+	for i, e := range doc {
+		if e.Key != path[0] {
+			continue
+		}
+
+		if len(path) == 1 {
+			return append(doc[:i:i], doc[i+1:]...)
+		}
+
+		if child, ok := e.Value.(bson.D); ok {
+			doc[i].Value = memoryUnset(child, path[1:])
+		}
+		return doc
+	}
+
+	return doc
+}
+
+func memoryPage(matched []int, skip *int64, limit *int64) []int {
+	if skip != nil && *skip > 0 {
+		if *skip >= int64(len(matched)) {
+			return matched[:0]
+		}
+		matched = matched[*skip:]
+	}
+
+	if limit != nil && *limit != 0 {
+		n := *limit
+		if n < 0 {
+			n = -n
+		}
+		if n < int64(len(matched)) {
+			matched = matched[:n]
+		}
+	}
+
+	return matched
+}
+
+// report whether any value equals the target, a nil target also matches the missing values
+func memoryAny(values []interface{}, target interface{}) bool {
+	if target == nil && len(values) == 0 {
+		return true
+	}
+
+	for _, value := range values {
+		if memoryEqual(value, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func memoryContains(values []interface{}, target interface{}) bool {
+	for _, value := range values {
+		if memoryEqual(value, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func memoryEqual(a, b interface{}) bool {
+	return memoryClass(a) == memoryClass(b) && memoryCompare(a, b) == 0
+}
+
+func memoryFirst(values []interface{}) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+
+	return values[0]
+}
+
+// the order of the bson types in the comparisons
+func memoryClass(v interface{}) int {
+	switch v.(type) {
+	case nil, primitive.Null, primitive.Undefined:
+		return 1
+	case int32, int64, float64:
+		return 2
+	case string:
+		return 3
+	case bson.D:
+		return 4
+	case bson.A:
+		return 5
+	case primitive.Binary:
+		return 6
+	case primitive.ObjectID:
+		return 7
+	case bool:
+		return 8
+	case primitive.DateTime:
+		return 9
+	case primitive.Timestamp:
+		return 10
+	default:
+		return 11
+	}
+}
+
+// compare the values in the order of the bson types and then by their values
+func memoryCompare(a, b interface{}) int {
+	ca, cb := memoryClass(a), memoryClass(b)
+	if ca != cb {
+		return ca - cb
+	}
+
+	switch x := a.(type) {
+	case int32, int64, float64:
+		xi, xok := memoryInt(a)
+		yi, yok := memoryInt(b)
+		_, xf := a.(float64)
+		_, yf := b.(float64)
+		if xok && yok && !xf && !yf {
+			return memorySign(float64(xi - yi))
+		}
+		return memorySign(memoryFloat(a) - memoryFloat(b))
+	case string:
+		return strings.Compare(x, b.(string))
+	case primitive.ObjectID:
+		y := b.(primitive.ObjectID)
+		return bytes.Compare(x[:], y[:])
+	case bool:
+		y := b.(bool)
+		switch {
+		case x == y:
+			return 0
+		case y:
+			return -1
+		default:
+			return 1
+		}
+	case primitive.DateTime:
+		return memorySign(float64(x - b.(primitive.DateTime)))
+	case primitive.Timestamp:
+		return primitive.CompareTimestamp(x, b.(primitive.Timestamp))
+	case bson.A:
+		y := b.(bson.A)
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if result := memoryCompare(x[i], y[i]); result != 0 {
+				return result
+			}
+		}
+		return len(x) - len(y)
+	case bson.D:
+		y := b.(bson.D)
+		for i := 0; i < len(x) && i < len(y); i++ {
+			if result := strings.Compare(x[i].Key, y[i].Key); result != 0 {
+				return result
+			}
+			if result := memoryCompare(x[i].Value, y[i].Value); result != 0 {
+				return result
+			}
+		}
+		return len(x) - len(y)
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return 0
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func memorySign(f float64) int {
+	switch {
+	case f < 0:
+		return -1
+	case f > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func memoryInt(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), float64(int64(n)) == n
+	default:
+		return 0, false
+	}
+}
+
+func memoryFloat(v interface{}) float64 {
+	switch n := v.(type) {
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	default:
+		return 0
+	}
+}
+
+func memoryTruthy(v interface{}) bool {
+	switch b := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return b
+	case int32, int64, float64:
+		return memoryFloat(b) != 0
+	default:
+		return true
+	}
+}
+`
+
+const MemoryTemplate = `
+
+// ${VarDaoClassName}Memory is an in-memory implementation of ${VarDaoPrefixName}Repository for the unit tests without a database.
+// The filters, the updates and the options are evaluated by a MemoryCollection which supports a subset of the query language,
+// Aggregate, Watch and ResumeWatch fail with ErrMemoryUnsupported. The operations pass through the interceptors like the dao.
+type ${VarDaoClassName}Memory struct {
+	Columns    *${VarDaoPrefixName}Columns
+	Sort       *${VarDaoPrefixName}Sort
+	Collection *${VarCommonPrefix}MemoryCollection
+	dao        *${VarDaoClassName}
+	scope      ${VarCommonPrefix}Scope
+}
+
+var _ ${VarDaoPrefixName}Repository = (*${VarDaoClassName}Memory)(nil)
+
+// New${VarDaoClassName}Memory creates an in-memory dao with an empty collection.
+func New${VarDaoClassName}Memory() *${VarDaoClassName}Memory {
+	return &${VarDaoClassName}Memory{
+		Columns:    ${VarDaoVariableName}Columns,
+		Sort:       ${VarDaoVariableName}Sort,
+		Collection: ${VarCommonPrefix}NewMemoryCollection("${VarCollectionName}", ${VarDaoVariableName}Indexes, ${VarDaoVariableName}FieldNames),
+		dao:        &${VarDaoClassName}{Columns: ${VarDaoVariableName}Columns, Sort: ${VarDaoVariableName}Sort},
+	}
+}
+
+// Where returns a filter func that builds the filter with the typed filter conditions of the collection.
+func (dao *${VarDaoClassName}Memory) Where(filterFunc func(f *${VarDaoPrefixName}Filter) bson.D) ${VarDaoPrefixName}FilterFunc {
+	return dao.dao.Where(filterFunc)
+}
+
+// Update returns an update func that builds the update document with the typed update operators of the collection.
+func (dao *${VarDaoClassName}Memory) Update(updateFunc func(u *${VarDaoPrefixName}Update)) ${VarDaoPrefixName}UpdateFunc {
+	return dao.dao.Update(updateFunc)
+}
+
+// Validate checks the model against the validation rules declared by the gen tags of the model.
+func (dao *${VarDaoClassName}Memory) Validate(model *${VarModelPackageName}.${VarModelClassName}) error {
+	return dao.dao.Validate(model)
+}
+
+// Indexes returns the specs of the indexes declared by the gen tags of the model.
+func (dao *${VarDaoClassName}Memory) Indexes() []${VarCommonPrefix}IndexSpec {
+	return dao.dao.Indexes()
+}
+
+// EnsureIndexes returns the names of the declared indexes, the unique indexes are always enforced by the collection.
+func (dao *${VarDaoClassName}Memory) EnsureIndexes(ctx context.Context) ([]string, error) {
+	names := make([]string, 0, len(${VarDaoVariableName}Indexes))
+	for _, index := range ${VarDaoVariableName}Indexes {
+		names = append(names, index.Name)
+	}
+
+	return names, nil
+}
+
+// DiffIndexes returns an empty diff because the collection always has the declared indexes.
+func (dao *${VarDaoClassName}Memory) DiffIndexes(ctx context.Context) (*${VarCommonPrefix}IndexDiff, error) {
+	return &${VarCommonPrefix}IndexDiff{}, nil
+}
+
+// SyncIndexes returns an empty diff because the collection always has the declared indexes.
+func (dao *${VarDaoClassName}Memory) SyncIndexes(ctx context.Context, dropExtra bool) (*${VarCommonPrefix}IndexDiff, error) {
+	return &${VarCommonPrefix}IndexDiff{}, nil
+}
+
+// Schema returns the json schema derived from the fields and the gen tags of the model.
+func (dao *${VarDaoClassName}Memory) Schema() bson.D {
+	return dao.dao.Schema()
+}
+
+// ApplyValidator does nothing, the models are checked by Validate before they are written.
+func (dao *${VarDaoClassName}Memory) ApplyValidator(ctx context.Context, level ${VarCommonPrefix}ValidationLevel, action ${VarCommonPrefix}ValidationAction) error {
+	return nil
+}
+
+// Count returns the number of documents in the collection.
+func (dao *${VarDaoClassName}Memory) Count(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}CountOptionsFunc) (int64, error) {
+	var (
+		opts   *options.CountOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpCount, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (int64, error) {
+		return dao.Collection.Count(op.Filter, opts)
+	})
+}
+
+// EstimatedCount returns the number of documents in the collection.
+func (dao *${VarDaoClassName}Memory) EstimatedCount(ctx context.Context, optionsFunc ...${VarDaoPrefixName}EstimatedCountOptionsFunc) (int64, error) {
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpEstimatedCount}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (int64, error) {
+		return dao.Collection.EstimatedCount()
+	})
+}
+
+// Exists reports whether at least one document in the collection matches the filter.
+func (dao *${VarDaoClassName}Memory) Exists(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc) (bool, error) {
+	filter := dao.scoped(filterFunc(dao.Columns))
+
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpExists, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (bool, error) {
+		document, err := dao.Collection.FindOne(op.Filter, nil)
+		return document != nil, err
+	})
+}
+
+// Aggregate is not supported by the in-memory dao.
+func (dao *${VarDaoClassName}Memory) Aggregate(ctx context.Context, pipelineFunc ${VarDaoPrefixName}PipelineFunc, optionsFunc ...${VarDaoPrefixName}AggregateOptionsFunc) (*mongo.Cursor, error) {
+	return nil, ${VarCommonPrefix}ErrMemoryUnsupported
+}
+
+// Watch is not supported by the in-memory dao.
+func (dao *${VarDaoClassName}Memory) Watch(ctx context.Context, pipelineFunc ${VarDaoPrefixName}PipelineFunc, optionsFunc ...${VarDaoPrefixName}WatchOptionsFunc) (*${VarDaoPrefixName}ChangeStream, error) {
+	return nil, ${VarCommonPrefix}ErrMemoryUnsupported
+}
+
+// ResumeWatch is not supported by the in-memory dao.
+func (dao *${VarDaoClassName}Memory) ResumeWatch(ctx context.Context, store ${VarCommonPrefix}ResumeTokenStore, pipelineFunc ${VarDaoPrefixName}PipelineFunc, optionsFunc ...${VarDaoPrefixName}WatchOptionsFunc) (*${VarDaoPrefixName}ChangeStream, error) {
+	return nil, ${VarCommonPrefix}ErrMemoryUnsupported
+}
+
+// Distinct returns the unique values of the column in the documents matching the filter.
+func (dao *${VarDaoClassName}Memory) Distinct(ctx context.Context, columnFunc ${VarDaoPrefixName}ColumnFunc, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DistinctOptionsFunc) ([]interface{}, error) {
+	var (
+		column = columnFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpDistinct, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) ([]interface{}, error) {
+		return dao.Collection.Distinct(column, op.Filter)
+	})
+}
+
+// DistinctStrings returns the unique values of a string column.
+func (dao *${VarDaoClassName}Memory) DistinctStrings(ctx context.Context, columnFunc ${VarDaoPrefixName}ColumnFunc, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DistinctOptionsFunc) ([]string, error) {
+	values, err := dao.Distinct(ctx, columnFunc, filterFunc, optionsFunc...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ${VarCommonPrefix}StringValues(values)
+}
+
+// DistinctInt64s returns the unique values of an integer column.
+func (dao *${VarDaoClassName}Memory) DistinctInt64s(ctx context.Context, columnFunc ${VarDaoPrefixName}ColumnFunc, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DistinctOptionsFunc) ([]int64, error) {
+	values, err := dao.Distinct(ctx, columnFunc, filterFunc, optionsFunc...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ${VarCommonPrefix}Int64Values(values)
+}
+
+// InsertOne inserts a single document into the collection.
+func (dao *${VarDaoClassName}Memory) InsertOne(ctx context.Context, model *${VarModelPackageName}.${VarModelClassName}, optionsFunc ...${VarDaoPrefixName}InsertOneOptionsFunc) (*mongo.InsertOneResult, error) {
+	if model == nil {
+		return nil, errors.New("model is nil")
+	}
+
+	if err := dao.dao.beforeInsert(ctx, model); err != nil {
+		return nil, err
+	}
+
+	result, err := ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpInsertOne, Document: model}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.InsertOneResult, error) {
+		if err := dao.autofill(ctx, model); err != nil {
+			return nil, err
+		}
+
+		if err := dao.Validate(model); err != nil {
+			return nil, err
+		}
+
+		return dao.Collection.InsertOne(op.Document)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = dao.dao.afterInsert(ctx, model); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// InsertMany inserts multiple documents into the collection.
+func (dao *${VarDaoClassName}Memory) InsertMany(ctx context.Context, models []*${VarModelPackageName}.${VarModelClassName}, optionsFunc ...${VarDaoPrefixName}InsertManyOptionsFunc) (*mongo.InsertManyResult, error) {
+	if len(models) == 0 {
+		return nil, errors.New("models is empty")
+	}
+
+	documents := make([]interface{}, 0, len(models))
+	for i, model := range models {
+		if err := dao.dao.beforeInsert(ctx, model); err != nil {
+			return nil, fmt.Errorf("models[%d]: %w", i, err)
+		}
+
+		documents = append(documents, model)
+	}
+
+	result, err := ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpInsertMany, Documents: documents}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.InsertManyResult, error) {
+		for i, model := range models {
+			if err := dao.autofill(ctx, model); err != nil {
+				return nil, err
+			}
+
+			if err := dao.Validate(model); err != nil {
+				return nil, fmt.Errorf("models[%d]: %w", i, err)
+			}
+		}
+
+		return dao.Collection.InsertMany(op.Documents)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, model := range models {
+		if err = dao.dao.afterInsert(ctx, model); err != nil {
+			return result, fmt.Errorf("models[%d]: %w", i, err)
+		}
+	}
+
+	return result, nil
+}
+
+// UpdateOne updates at most one document in the collection.
+func (dao *${VarDaoClassName}Memory) UpdateOne(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc${VarVersionParam}, updateFunc ${VarDaoPrefixName}UpdateFunc, optionsFunc ...${VarDaoPrefixName}UpdateOptionsFunc) (*mongo.UpdateResult, error) {
+	return dao.update(ctx, ${VarCommonPrefix}OpUpdateOne, filterFunc${VarVersionArg}, updateFunc, optionsFunc...)
+}
+
+// UpdateOneByID updates at most one document in the collection.
+func (dao *${VarDaoClassName}Memory) UpdateOneByID(ctx context.Context, id string${VarVersionParam}, updateFunc ${VarDaoPrefixName}UpdateFunc, optionsFunc ...${VarDaoPrefixName}UpdateOptionsFunc) (*mongo.UpdateResult, error) {
+	objectID, err := ${VarCommonPrefix}ParseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.UpdateOne(ctx, func(cols *${VarDaoPrefixName}Columns) interface{} {
+		return bson.M{"_id": objectID}
+	}${VarVersionArg}, updateFunc, optionsFunc...)
+}
+
+// UpdateMany updates the documents in the collection.
+func (dao *${VarDaoClassName}Memory) UpdateMany(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc${VarVersionParam}, updateFunc ${VarDaoPrefixName}UpdateFunc, optionsFunc ...${VarDaoPrefixName}UpdateOptionsFunc) (*mongo.UpdateResult, error) {
+	return dao.update(ctx, ${VarCommonPrefix}OpUpdateMany, filterFunc${VarVersionArg}, updateFunc, optionsFunc...)
+}
+
+// ReplaceOne replaces at most one document in the collection.
+func (dao *${VarDaoClassName}Memory) ReplaceOne(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, model *${VarModelPackageName}.${VarModelClassName}, optionsFunc ...${VarDaoPrefixName}ReplaceOptionsFunc) (*mongo.UpdateResult, error) {
+	if model == nil {
+		return nil, errors.New("model is nil")
+	}
+
+	if err := dao.dao.beforeUpdate(ctx, model); err != nil {
+		return nil, err
+	}
+
+	if err := dao.Validate(model); err != nil {
+		return nil, err
+	}
+
+	var (
+		opts   *options.ReplaceOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	${VarReplaceCode}
+}
+
+// replaceOne replaces the document through the interceptors
+func (dao *${VarDaoClassName}Memory) replaceOne(ctx context.Context, filter interface{}, model *${VarModelPackageName}.${VarModelClassName}, opts *options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	upsert := opts != nil && opts.Upsert != nil && *opts.Upsert
+
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpReplaceOne, Filter: filter, Document: model}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.UpdateResult, error) {
+		return dao.Collection.ReplaceOne(op.Filter, op.Document, upsert)
+	})
+}
+
+// FindOne returns a model for one document in the collection.
+${VarFindOneNotFoundDoc}
+func (dao *${VarDaoClassName}Memory) FindOne(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}FindOneOptionsFunc) (*${VarModelPackageName}.${VarModelClassName}, error) {
+	var (
+		opts   *options.FindOneOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	model, err := ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpFindOne, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*${VarModelPackageName}.${VarModelClassName}, error) {
+		document, err := dao.Collection.FindOne(op.Filter, opts)
+		if err != nil || document == nil {
+			return nil, err
+		}
+
+		model := &${VarModelPackageName}.${VarModelClassName}{}
+		if err = bson.Unmarshal(document, model); err != nil {
+			return nil, err
+		}
+
+		return model, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if model == nil {
+		return nil, dao.dao.notFound()
+	}
+
+	if err = dao.dao.afterFind(ctx, model); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// FindOneByID returns a model for one document in the collection.
+func (dao *${VarDaoClassName}Memory) FindOneByID(ctx context.Context, id string, optionsFunc ...${VarDaoPrefixName}FindOneOptionsFunc) (*${VarModelPackageName}.${VarModelClassName}, error) {
+	objectID, err := ${VarCommonPrefix}ParseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.FindOne(ctx, func(cols *${VarDaoPrefixName}Columns) interface{} {
+		return bson.M{"_id": objectID}
+	}, optionsFunc...)
+}
+
+// FindMany returns the models of the matching documents in the collection.
+func (dao *${VarDaoClassName}Memory) FindMany(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}FindManyOptionsFunc) ([]*${VarModelPackageName}.${VarModelClassName}, error) {
+	var (
+		opts   *options.FindOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	models, err := ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpFindMany, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) ([]*${VarModelPackageName}.${VarModelClassName}, error) {
+		return dao.find(op.Filter, dao.dao.withDefaultSort(opts))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = dao.dao.afterFind(ctx, models...); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+// FindManyByIDs returns the models matching the ids in the same order as the ids.
+// Ids that do not match any document are skipped and repeated ids are returned only once.
+func (dao *${VarDaoClassName}Memory) FindManyByIDs(ctx context.Context, ids []string, optionsFunc ...${VarDaoPrefixName}FindManyOptionsFunc) ([]*${VarModelPackageName}.${VarModelClassName}, error) {
+	objectIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := ${VarCommonPrefix}ParseObjectID(id)
+		if err != nil {
+			return nil, err
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+
+	if len(objectIDs) == 0 {
+		return make([]*${VarModelPackageName}.${VarModelClassName}, 0), nil
+	}
+
+	var opts *options.FindOptions
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	found, err := ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpFindMany, Filter: dao.scoped(bson.M{"_id": bson.M{"$in": objectIDs}})}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (map[primitive.ObjectID]*${VarModelPackageName}.${VarModelClassName}, error) {
+		documents, err := dao.Collection.Find(op.Filter, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		found := make(map[primitive.ObjectID]*${VarModelPackageName}.${VarModelClassName}, len(objectIDs))
+		for _, document := range documents {
+			objectID, ok := document.Lookup("_id").ObjectIDOK()
+			if !ok {
+				continue
+			}
+
+			model := &${VarModelPackageName}.${VarModelClassName}{}
+			if err = bson.Unmarshal(document, model); err != nil {
+				return nil, err
+			}
+			found[objectID] = model
+		}
+
+		return found, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]*${VarModelPackageName}.${VarModelClassName}, 0, len(found))
+	for _, objectID := range objectIDs {
+		if model, ok := found[objectID]; ok {
+			models = append(models, model)
+			delete(found, objectID)
+		}
+	}
+
+	if err = dao.dao.afterFind(ctx, models...); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+// DeleteOne deletes at most one document from the collection.
+// The document is marked as deleted instead of being removed when the model is soft deleted.
+func (dao *${VarDaoClassName}Memory) DeleteOne(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DeleteOptionsFunc) (*mongo.DeleteResult, error) {
+	filter := dao.scoped(filterFunc(dao.Columns))
+
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpDeleteOne, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.DeleteResult, error) {
+		${VarMemoryDeleteOneCode}
+	})
+}
+
+// DeleteOneByID deletes at most one document from the collection.
+func (dao *${VarDaoClassName}Memory) DeleteOneByID(ctx context.Context, id string, optionsFunc ...${VarDaoPrefixName}DeleteOptionsFunc) (*mongo.DeleteResult, error) {
+	objectID, err := ${VarCommonPrefix}ParseObjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return dao.DeleteOne(ctx, func(cols *${VarDaoPrefixName}Columns) interface{} {
+		return bson.M{"_id": objectID}
+	}, optionsFunc...)
+}
+
+// DeleteMany deletes documents from the collection.
+// The documents are marked as deleted instead of being removed when the model is soft deleted.
+func (dao *${VarDaoClassName}Memory) DeleteMany(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DeleteOptionsFunc) (*mongo.DeleteResult, error) {
+	filter := dao.scoped(filterFunc(dao.Columns))
+
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpDeleteMany, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.DeleteResult, error) {
+		${VarMemoryDeleteManyCode}
+	})
+}
+
+${VarMemorySoftDeleteMethods}
+
+// update the documents through the interceptors
+func (dao *${VarDaoClassName}Memory) update(ctx context.Context, kind ${VarCommonPrefix}OpKind, filterFunc ${VarDaoPrefixName}FilterFunc${VarVersionParam}, updateFunc ${VarDaoPrefixName}UpdateFunc, optionsFunc ...${VarDaoPrefixName}UpdateOptionsFunc) (*mongo.UpdateResult, error) {
+	var (
+		opts   *options.UpdateOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	update, err := dao.dao.prepareUpdate(ctx, updateFunc(dao.Columns))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	${VarVersionFilterCode}upsert := opts != nil && opts.Upsert != nil && *opts.Upsert
+
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Collection: dao.Collection.Name(), Operation: kind, Filter: filter, Update: update}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.UpdateResult, error) {
+		apply := dao.Collection.UpdateMany
+		if kind == ${VarCommonPrefix}OpUpdateOne {
+			apply = dao.Collection.UpdateOne
+		}
+
+		result, err := apply(op.Filter, op.Update, upsert)
+
+		${VarVersionConflictCode}return result, err
+	})
+}
+
+// find the documents and decode them into the models
+func (dao *${VarDaoClassName}Memory) find(filter interface{}, opts *options.FindOptions) ([]*${VarModelPackageName}.${VarModelClassName}, error) {
+	documents, err := dao.Collection.Find(filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]*${VarModelPackageName}.${VarModelClassName}, 0, len(documents))
+	for _, document := range documents {
+		model := &${VarModelPackageName}.${VarModelClassName}{}
+		if err = bson.Unmarshal(document, model); err != nil {
+			return nil, err
+		}
+		models = append(models, model)
+	}
+
+	return models, nil
+}
+
+// apply the soft delete scope of the dao to the filter
+func (dao *${VarDaoClassName}Memory) scoped(filter interface{}) interface{} {
+	return ${VarDaoVariableName}SoftDelete.Apply(filter, dao.scope)
+}
+
+// autofill when inserting data, the autoIncr fields are filled by the local counters of the collection
+func (dao *${VarDaoClassName}Memory) autofill(ctx context.Context, model *${VarModelPackageName}.${VarModelClassName}) error {
+	${VarMemoryAutofillCode}
+}
+`
+
+const MemorySoftDeleteTemplate = `
+// WithTrashed returns a copy of the dao whose operations include the soft deleted documents.
+func (dao *${VarDaoClassName}Memory) WithTrashed() *${VarDaoClassName}Memory {
+	d := *dao
+	d.scope = ${VarCommonPrefix}ScopeWithTrashed
+	return &d
+}
+
+// OnlyTrashed returns a copy of the dao whose operations only include the soft deleted documents.
+func (dao *${VarDaoClassName}Memory) OnlyTrashed() *${VarDaoClassName}Memory {
+	d := *dao
+	d.scope = ${VarCommonPrefix}ScopeOnlyTrashed
+	return &d
+}
+
+// Restore clears the deletion mark of the soft deleted documents matching the filter.
+func (dao *${VarDaoClassName}Memory) Restore(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc) (*mongo.UpdateResult, error) {
+	filter := ${VarDaoVariableName}SoftDelete.Apply(filterFunc(dao.Columns), ${VarCommonPrefix}ScopeOnlyTrashed)
+
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpRestore, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.UpdateResult, error) {
+		return dao.Collection.Restore(${VarDaoVariableName}SoftDelete, op.Filter)
+	})
+}
+
+// ForceDelete removes the documents matching the filter from the collection whether they are soft deleted or not.
+// Only the soft deleted documents are removed when the dao is scoped by OnlyTrashed.
+func (dao *${VarDaoClassName}Memory) ForceDelete(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DeleteOptionsFunc) (*mongo.DeleteResult, error) {
+	filter := filterFunc(dao.Columns)
+
+	if dao.scope == ${VarCommonPrefix}ScopeOnlyTrashed {
+		filter = dao.scoped(filter)
+	}
+
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpDeleteMany, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.DeleteResult, error) {
+		return dao.Collection.DeleteMany(op.Filter)
+	})
+}`
+
+const MemoryExternalTemplate = `
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+package ${VarDaoPackageName}
+
+import (
+	"${VarDaoPackagePath}/internal"
+)
+
+type ${VarDaoClassName}Memory = internal.${VarDaoClassName}Memory
+
+// New${VarDaoClassName}Memory creates an in-memory implementation of ${VarDaoPrefixName}Repository for the unit tests without a database.
+func New${VarDaoClassName}Memory() *${VarDaoClassName}Memory {
+	return internal.New${VarDaoClassName}Memory()
+}
+`
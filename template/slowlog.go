@@ -0,0 +1,83 @@
+package template
+
+const SlowLogInternalTemplate = `
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+//go:build go1.21
+
+package internal
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// SlowQueryLogger returns an interceptor which logs the operations taking at least the threshold of the options.
+// The collection, the operation, the duration, the caller and the shape of the filter or the pipeline are logged,
+// the values of the filters are replaced with ? unless ShowValues is set, and the values of the sensitive columns
+// are always redacted. The errors are not logged because the messages of the server may contain the values.
+func SlowQueryLogger(logger *slog.Logger, opts SlowQueryOptions) Interceptor {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(ctx context.Context, op OpInfo, next Handler) error {
+		begin := time.Now()
+
+		err := next(ctx, op)
+
+		elapsed := time.Since(begin)
+		if elapsed < opts.Threshold {
+			return err
+		}
+
+		attrs := []slog.Attr{
+			slog.String("collection", op.Collection),
+			slog.String("operation", string(op.Operation)),
+			slog.Duration("duration", elapsed),
+			slog.String("caller", slowQueryCaller()),
+			slog.Bool("failed", err != nil),
+		}
+
+		if op.Filter != nil {
+			attrs = append(attrs, slog.String("filter", RedactFilter(op.Collection, op.Filter, opts.ShowValues)))
+		}
+
+		if op.Pipeline != nil {
+			attrs = append(attrs, slog.String("pipeline", RedactFilter(op.Collection, op.Pipeline, opts.ShowValues)))
+		}
+
+		logger.LogAttrs(ctx, slog.LevelWarn, "slow query", attrs...)
+
+		return err
+	}
+}
+`
+
+const SlowLogExternalTemplate = `
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+//go:build go1.21
+
+package ${VarDaoPackageName}
+
+import (
+	"${VarDaoPackagePath}/internal"
+	"log/slog"
+)
+
+// SlowQueryLogger returns an interceptor which logs the operations taking at least the threshold of the options,
+// register it with Use. The values of the sensitive columns never appear in the logs.
+func SlowQueryLogger(logger *slog.Logger, opts SlowQueryOptions) Interceptor {
+	return internal.SlowQueryLogger(logger, opts)
+}
+`
@@ -0,0 +1,132 @@
+package template
+
+const TelemetryTemplate = `
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
+	"time"
+)
+
+const instrumentationName = daoPackagePath
+
+type telemetryInstruments struct {
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+}
+
+var telemetryCache sync.Map
+
+func init() {
+	Use(TelemetryInterceptor)
+}
+
+// TelemetryInterceptor starts a span named mongo.<collection>.<operation> for every operation of the daos,
+// and records the duration and the errors of the operations. The tracer and the meter are resolved from
+// the global providers of OpenTelemetry on every operation, so the providers can be replaced at any time.
+// The failed operations are recorded with the kind of the error only, because the messages of the driver errors
+// contain the values of the documents, such as the keys of a duplicate key error.
+func TelemetryInterceptor(ctx context.Context, op OpInfo, next Handler) error {
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "mongodb"),
+		attribute.String("db.name", op.Database),
+		attribute.String("db.mongodb.collection", op.Collection),
+		attribute.String("db.operation", string(op.Operation)),
+	}
+
+	ctx, span := otel.GetTracerProvider().Tracer(instrumentationName).Start(ctx, "mongo."+op.Collection+"."+string(op.Operation),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End()
+
+	begin := time.Now()
+
+	err := next(ctx, op)
+
+	instruments := telemetryInstrumentsOf(otel.GetMeterProvider())
+	instruments.duration.Record(ctx, time.Since(begin).Seconds(), metric.WithAttributes(attrs...))
+
+	if err != nil {
+		kind := attribute.String("error.type", errorKind(err))
+		span.SetAttributes(kind)
+		span.SetStatus(codes.Error, kind.Value.AsString())
+		instruments.errors.Add(ctx, 1, metric.WithAttributes(append(attrs, kind)...))
+	}
+
+	return err
+}
+
+// the kind of the error recorded by the telemetry instead of its message
+func errorKind(err error) string {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrDuplicateKey), mongo.IsDuplicateKeyError(err):
+		return "duplicate_key"
+	case errors.Is(err, ErrValidation):
+		return "validation"
+	case errors.Is(err, ErrInvalidID):
+		return "invalid_id"
+	case errors.Is(err, ErrEmptyUpdate):
+		return "empty_update"
+	case errors.Is(err, ErrVersionConflict):
+		return "version_conflict"
+	case errors.Is(err, ErrVersionUpsert):
+		return "version_upsert"
+	case errors.Is(err, ErrMemoryUnsupported):
+		return "unsupported"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded), mongo.IsTimeout(err):
+		return "timeout"
+	case mongo.IsNetworkError(err):
+		return "network"
+	default:
+		return "error"
+	}
+}
+
+// resolve the instruments created by the meter provider
+func telemetryInstrumentsOf(provider metric.MeterProvider) *telemetryInstruments {
+	if instruments, ok := telemetryCache.Load(provider); ok {
+		return instruments.(*telemetryInstruments)
+	}
+
+	meter := provider.Meter(instrumentationName)
+	instruments := &telemetryInstruments{}
+
+	var err error
+	if instruments.duration, err = meter.Float64Histogram("db.client.operation.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of the operations of the daos."),
+	); err != nil {
+		otel.Handle(err)
+	}
+
+	if instruments.errors, err = meter.Int64Counter("db.client.operation.errors",
+		metric.WithUnit("{error}"),
+		metric.WithDescription("Number of the operations of the daos which failed."),
+	); err != nil {
+		otel.Handle(err)
+	}
+
+	actual, _ := telemetryCache.LoadOrStore(provider, instruments)
+
+	return actual.(*telemetryInstruments)
+}
+`
@@ -4,21 +4,95 @@ const ExternalTemplate = `
 package ${VarDaoPackageName}
 
 import (
+	"context"
 	"${VarDaoPackagePath}/internal"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type ${VarDaoPrefixName}Columns = internal.${VarDaoPrefixName}Columns
 
+type ${VarDaoPrefixName}Filter = internal.${VarDaoPrefixName}Filter
+
+type ${VarDaoPrefixName}Update = internal.${VarDaoPrefixName}Update
+
+type ${VarDaoPrefixName}Sort = internal.${VarDaoPrefixName}Sort
+
+type ${VarDaoPrefixName}ChangeEvent = internal.${VarDaoPrefixName}ChangeEvent
+
+type ${VarDaoPrefixName}ChangeStream = internal.${VarDaoPrefixName}ChangeStream
+
+type ${VarDaoPrefixName}Repository = internal.${VarDaoPrefixName}Repository
+
 type ${VarDaoClassName} struct {
 	*internal.${VarDaoClassName}
 }
 
+var _ ${VarDaoPrefixName}Repository = (*${VarDaoClassName})(nil)
+
 func New${VarDaoClassName}(db *mongo.Database) *${VarDaoClassName} {
 	return &${VarDaoClassName}{${VarDaoClassName}: internal.New${VarDaoClassName}(db)}
 }
+
+// ${VarDaoPrefixName}FindOneAs executes a find command and decodes one document into the partial struct P.
+func ${VarDaoPrefixName}FindOneAs[P any](ctx context.Context, dao *${VarDaoClassName}, filterFunc internal.${VarDaoPrefixName}FilterFunc, optionsFunc ...internal.${VarDaoPrefixName}FindOneOptionsFunc) (*P, error) {
+	return internal.${VarDaoPrefixName}FindOneAs[P](ctx, dao.${VarDaoClassName}, filterFunc, optionsFunc...)
+}
+
+// ${VarDaoPrefixName}FindManyAs executes a find command and decodes the matching documents into the partial struct P.
+func ${VarDaoPrefixName}FindManyAs[P any](ctx context.Context, dao *${VarDaoClassName}, filterFunc internal.${VarDaoPrefixName}FilterFunc, optionsFunc ...internal.${VarDaoPrefixName}FindManyOptionsFunc) ([]*P, error) {
+	return internal.${VarDaoPrefixName}FindManyAs[P](ctx, dao.${VarDaoClassName}, filterFunc, optionsFunc...)
+}
 `
 
+const SoftDeleteTemplate = `
+// WithTrashed returns a copy of the dao whose operations include the soft deleted documents.
+func (dao *${VarDaoClassName}) WithTrashed() *${VarDaoClassName} {
+	d := *dao
+	d.scope = ${VarCommonPrefix}ScopeWithTrashed
+	return &d
+}
+
+// OnlyTrashed returns a copy of the dao whose operations only include the soft deleted documents.
+func (dao *${VarDaoClassName}) OnlyTrashed() *${VarDaoClassName} {
+	d := *dao
+	d.scope = ${VarCommonPrefix}ScopeOnlyTrashed
+	return &d
+}
+
+// Restore clears the deletion mark of the soft deleted documents matching the filter.
+func (dao *${VarDaoClassName}) Restore(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc) (*mongo.UpdateResult, error) {
+	filter := ${VarDaoVariableName}SoftDelete.Apply(filterFunc(dao.Columns), ${VarCommonPrefix}ScopeOnlyTrashed)
+
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpRestore, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.UpdateResult, error) {
+		return ${VarDaoVariableName}SoftDelete.Restore(ctx, dao.Collection, op.Filter)
+	})
+}
+
+// ForceDelete removes the documents matching the filter from the collection whether they are soft deleted or not.
+// Only the soft deleted documents are removed when the dao is scoped by OnlyTrashed.
+func (dao *${VarDaoClassName}) ForceDelete(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DeleteOptionsFunc) (*mongo.DeleteResult, error) {
+	var (
+		opts   *options.DeleteOptions
+		filter = filterFunc(dao.Columns)
+	)
+
+	if dao.scope == ${VarCommonPrefix}ScopeOnlyTrashed {
+		filter = dao.scoped(filter)
+	}
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpDeleteMany, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.DeleteResult, error) {
+		return dao.Collection.DeleteMany(ctx, op.Filter, opts)
+	})
+}`
+
+const SoftDeleteRepositoryTemplate = `
+Restore(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc) (*mongo.UpdateResult, error)
+	ForceDelete(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DeleteOptionsFunc) (*mongo.DeleteResult, error)`
+
 const InternalTemplate = `
 // --------------------------------------------------------------------------------------------
 // The following code is automatically generated by the mongo-dao-generator tool.
@@ -35,21 +109,74 @@ import (
 type ${VarDaoPrefixName}FilterFunc func(cols *${VarDaoPrefixName}Columns) interface{}
 type ${VarDaoPrefixName}UpdateFunc func(cols *${VarDaoPrefixName}Columns) interface{}
 type ${VarDaoPrefixName}PipelineFunc func(cols *${VarDaoPrefixName}Columns) interface{}
+type ${VarDaoPrefixName}ColumnFunc func(cols *${VarDaoPrefixName}Columns) string
 type ${VarDaoPrefixName}CountOptionsFunc func(cols *${VarDaoPrefixName}Columns) *options.CountOptions
+type ${VarDaoPrefixName}EstimatedCountOptionsFunc func(cols *${VarDaoPrefixName}Columns) *options.EstimatedDocumentCountOptions
+type ${VarDaoPrefixName}DistinctOptionsFunc func(cols *${VarDaoPrefixName}Columns) *options.DistinctOptions
 type ${VarDaoPrefixName}AggregateOptionsFunc func(cols *${VarDaoPrefixName}Columns) *options.AggregateOptions
 type ${VarDaoPrefixName}FindOneOptionsFunc func(cols *${VarDaoPrefixName}Columns) *options.FindOneOptions
 type ${VarDaoPrefixName}FindManyOptionsFunc func(cols *${VarDaoPrefixName}Columns) *options.FindOptions
 type ${VarDaoPrefixName}UpdateOptionsFunc func(cols *${VarDaoPrefixName}Columns) *options.UpdateOptions
+type ${VarDaoPrefixName}ReplaceOptionsFunc func(cols *${VarDaoPrefixName}Columns) *options.ReplaceOptions
 type ${VarDaoPrefixName}DeleteOptionsFunc func(cols *${VarDaoPrefixName}Columns) *options.DeleteOptions
 type ${VarDaoPrefixName}InsertOneOptionsFunc func(cols *${VarDaoPrefixName}Columns) *options.InsertOneOptions
 type ${VarDaoPrefixName}InsertManyOptionsFunc func(cols *${VarDaoPrefixName}Columns) *options.InsertManyOptions
+type ${VarDaoPrefixName}WatchOptionsFunc func(cols *${VarDaoPrefixName}Columns) *options.ChangeStreamOptions
+
+// ${VarDaoPrefixName}ChangeEvent is a change event of the collection with the full document decoded into the model.
+type ${VarDaoPrefixName}ChangeEvent = ${VarCommonPrefix}ChangeEvent[${VarModelPackageName}.${VarModelClassName}]
+
+// ${VarDaoPrefixName}ChangeStream iterates over the change events of the collection.
+type ${VarDaoPrefixName}ChangeStream = ${VarCommonPrefix}ChangeStream[${VarModelPackageName}.${VarModelClassName}]
 
 type ${VarDaoClassName} struct {
 	Columns    *${VarDaoPrefixName}Columns
+	Sort       *${VarDaoPrefixName}Sort
 	Database   *mongo.Database
 	Collection *mongo.Collection
+	IndexView  ${VarCommonPrefix}IndexView // nil means the index view of the collection
+	scope      ${VarCommonPrefix}Scope
+}
+
+// ${VarDaoPrefixName}Repository lists the methods of the dao, the services depending on it can be tested without a database.
+// The methods returning a scoped copy of the dao and the generic find functions are not listed.
+type ${VarDaoPrefixName}Repository interface {
+	Where(filterFunc func(f *${VarDaoPrefixName}Filter) bson.D) ${VarDaoPrefixName}FilterFunc
+	Update(updateFunc func(u *${VarDaoPrefixName}Update)) ${VarDaoPrefixName}UpdateFunc
+	Validate(model *${VarModelPackageName}.${VarModelClassName}) error
+	Indexes() []${VarCommonPrefix}IndexSpec
+	EnsureIndexes(ctx context.Context) ([]string, error)
+	DiffIndexes(ctx context.Context) (*${VarCommonPrefix}IndexDiff, error)
+	SyncIndexes(ctx context.Context, dropExtra bool) (*${VarCommonPrefix}IndexDiff, error)
+	Schema() bson.D
+	ApplyValidator(ctx context.Context, level ${VarCommonPrefix}ValidationLevel, action ${VarCommonPrefix}ValidationAction) error
+	Count(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}CountOptionsFunc) (int64, error)
+	EstimatedCount(ctx context.Context, optionsFunc ...${VarDaoPrefixName}EstimatedCountOptionsFunc) (int64, error)
+	Exists(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc) (bool, error)
+	Aggregate(ctx context.Context, pipelineFunc ${VarDaoPrefixName}PipelineFunc, optionsFunc ...${VarDaoPrefixName}AggregateOptionsFunc) (*mongo.Cursor, error)
+	Watch(ctx context.Context, pipelineFunc ${VarDaoPrefixName}PipelineFunc, optionsFunc ...${VarDaoPrefixName}WatchOptionsFunc) (*${VarDaoPrefixName}ChangeStream, error)
+	ResumeWatch(ctx context.Context, store ${VarCommonPrefix}ResumeTokenStore, pipelineFunc ${VarDaoPrefixName}PipelineFunc, optionsFunc ...${VarDaoPrefixName}WatchOptionsFunc) (*${VarDaoPrefixName}ChangeStream, error)
+	Distinct(ctx context.Context, columnFunc ${VarDaoPrefixName}ColumnFunc, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DistinctOptionsFunc) ([]interface{}, error)
+	DistinctStrings(ctx context.Context, columnFunc ${VarDaoPrefixName}ColumnFunc, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DistinctOptionsFunc) ([]string, error)
+	DistinctInt64s(ctx context.Context, columnFunc ${VarDaoPrefixName}ColumnFunc, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DistinctOptionsFunc) ([]int64, error)
+	InsertOne(ctx context.Context, model *${VarModelPackageName}.${VarModelClassName}, optionsFunc ...${VarDaoPrefixName}InsertOneOptionsFunc) (*mongo.InsertOneResult, error)
+	InsertMany(ctx context.Context, models []*${VarModelPackageName}.${VarModelClassName}, optionsFunc ...${VarDaoPrefixName}InsertManyOptionsFunc) (*mongo.InsertManyResult, error)
+	UpdateOne(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc${VarVersionParam}, updateFunc ${VarDaoPrefixName}UpdateFunc, optionsFunc ...${VarDaoPrefixName}UpdateOptionsFunc) (*mongo.UpdateResult, error)
+	UpdateOneByID(ctx context.Context, id string${VarVersionParam}, updateFunc ${VarDaoPrefixName}UpdateFunc, optionsFunc ...${VarDaoPrefixName}UpdateOptionsFunc) (*mongo.UpdateResult, error)
+	UpdateMany(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc${VarVersionParam}, updateFunc ${VarDaoPrefixName}UpdateFunc, optionsFunc ...${VarDaoPrefixName}UpdateOptionsFunc) (*mongo.UpdateResult, error)
+	ReplaceOne(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, model *${VarModelPackageName}.${VarModelClassName}, optionsFunc ...${VarDaoPrefixName}ReplaceOptionsFunc) (*mongo.UpdateResult, error)
+	FindOne(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}FindOneOptionsFunc) (*${VarModelPackageName}.${VarModelClassName}, error)
+	FindOneByID(ctx context.Context, id string, optionsFunc ...${VarDaoPrefixName}FindOneOptionsFunc) (*${VarModelPackageName}.${VarModelClassName}, error)
+	FindMany(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}FindManyOptionsFunc) ([]*${VarModelPackageName}.${VarModelClassName}, error)
+	FindManyByIDs(ctx context.Context, ids []string, optionsFunc ...${VarDaoPrefixName}FindManyOptionsFunc) ([]*${VarModelPackageName}.${VarModelClassName}, error)
+	DeleteOne(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DeleteOptionsFunc) (*mongo.DeleteResult, error)
+	DeleteOneByID(ctx context.Context, id string, optionsFunc ...${VarDaoPrefixName}DeleteOptionsFunc) (*mongo.DeleteResult, error)
+	DeleteMany(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DeleteOptionsFunc) (*mongo.DeleteResult, error)
+	${VarRepositoryMethods}
 }
 
+var _ ${VarDaoPrefixName}Repository = (*${VarDaoClassName})(nil)
+
 type ${VarDaoPrefixName}Columns struct {
 	${VarModelColumnsDefine}
 }
@@ -58,40 +185,271 @@ var ${VarDaoVariableName}Columns = &${VarDaoPrefixName}Columns{
 	${VarModelColumnsInstance}
 }
 
+// ${VarDaoPrefixName}Filter provides the typed filter conditions for each column of the collection.
+type ${VarDaoPrefixName}Filter struct {
+	${VarCommonPrefix}FilterBuilder
+	${VarModelFilterDefine}
+}
+
+var ${VarDaoVariableName}Filter = &${VarDaoPrefixName}Filter{
+	${VarModelFilterInstance}
+}
+
+// ${VarDaoPrefixName}Sort provides the typed sort keys for each column of the collection.
+type ${VarDaoPrefixName}Sort struct {
+	${VarModelSortDefine}
+}
+
+var ${VarDaoVariableName}Sort = &${VarDaoPrefixName}Sort{
+	${VarModelSortInstance}
+}
+
+// ${VarDaoVariableName}DefaultSort is applied by FindMany when the options do not specify a sort.
+var ${VarDaoVariableName}DefaultSort ${VarModelDefaultSort}
+
+// ${VarDaoVariableName}Indexes are declared by the gen tags of the model.
+var ${VarDaoVariableName}Indexes = []${VarCommonPrefix}IndexSpec{
+	${VarModelIndexes}
+}
+
+// ${VarDaoVariableName}FieldNames maps the columns to the field names of the model to report the keys of the duplicate key errors.
+var ${VarDaoVariableName}FieldNames = map[string]string{
+	${VarModelFieldNames}
+}
+
+// ${VarDaoVariableName}SoftDelete is the column marking the soft deleted documents, nil means the documents are removed on delete.
+var ${VarDaoVariableName}SoftDelete ${VarModelSoftDelete}
+
+// ${VarDaoVariableName}Schema is the json schema derived from the fields of the model.
+var ${VarDaoVariableName}Schema = ${VarModelSchema}
+
+// ${VarDaoPrefixName}Update provides the typed update operators for each column of the collection.
+type ${VarDaoPrefixName}Update struct {
+	updater *${VarCommonPrefix}Updater
+	${VarModelUpdateDefine}
+}
+
+func new${VarDaoPrefixName}Update() *${VarDaoPrefixName}Update {
+	updater := ${VarCommonPrefix}NewUpdater()
+
+	return &${VarDaoPrefixName}Update{
+		updater: updater,
+		${VarModelUpdateInstance}
+	}
+}
+
+${VarModelValidators}
+
 func New${VarDaoClassName}(db *mongo.Database) *${VarDaoClassName} {
 	return &${VarDaoClassName}{
 		Columns:    ${VarDaoVariableName}Columns,
+		Sort:       ${VarDaoVariableName}Sort,
 		Database:   db,
 		Collection: db.Collection("${VarCollectionName}"),
 	}
 }
 
+// Where returns a filter func that builds the filter with the typed filter conditions of the collection.
+func (dao *${VarDaoClassName}) Where(filterFunc func(f *${VarDaoPrefixName}Filter) bson.D) ${VarDaoPrefixName}FilterFunc {
+	return func(cols *${VarDaoPrefixName}Columns) interface{} {
+		return filterFunc(${VarDaoVariableName}Filter)
+	}
+}
+
+// Update returns an update func that builds the update document with the typed update operators of the collection.
+// The update func returns the validation error instead of the document when a value violates the validation rules.
+func (dao *${VarDaoClassName}) Update(updateFunc func(u *${VarDaoPrefixName}Update)) ${VarDaoPrefixName}UpdateFunc {
+	return func(cols *${VarDaoPrefixName}Columns) interface{} {
+		u := new${VarDaoPrefixName}Update()
+		updateFunc(u)
+
+		if err := u.updater.Err(); err != nil {
+			return err
+		}
+
+		return u.updater.Document()
+	}
+}
+
+// Validate checks the model against the validation rules declared by the gen tags of the model.
+func (dao *${VarDaoClassName}) Validate(model *${VarModelPackageName}.${VarModelClassName}) error {
+	if model == nil {
+		return errors.New("model is nil")
+	}
+
+	${VarModelValidateCode}
+}
+
+// Indexes returns the specs of the indexes declared by the gen tags of the model.
+func (dao *${VarDaoClassName}) Indexes() []${VarCommonPrefix}IndexSpec {
+	return ${VarDaoVariableName}Indexes
+}
+
+// EnsureIndexes creates the indexes declared by the gen tags of the model and returns the names of the indexes.
+// Creating an index that already exists with the same specification has no effect.
+func (dao *${VarDaoClassName}) EnsureIndexes(ctx context.Context) ([]string, error) {
+	indexes := dao.Indexes()
+	if len(indexes) == 0 {
+		return nil, nil
+	}
+
+	models := make([]mongo.IndexModel, 0, len(indexes))
+	for _, index := range indexes {
+		models = append(models, index.Model())
+	}
+
+	return dao.Collection.Indexes().CreateMany(ctx, models)
+}
+
+// DiffIndexes compares the indexes declared by the gen tags of the model with the indexes of the collection.
+func (dao *${VarDaoClassName}) DiffIndexes(ctx context.Context) (*${VarCommonPrefix}IndexDiff, error) {
+	return ${VarCommonPrefix}DiffIndexes(ctx, dao.indexView(), dao.Indexes())
+}
+
+// SyncIndexes makes the indexes of the collection match the indexes declared by the gen tags of the model.
+// The indexes that are not declared are only dropped when dropExtra is true.
+func (dao *${VarDaoClassName}) SyncIndexes(ctx context.Context, dropExtra bool) (*${VarCommonPrefix}IndexDiff, error) {
+	return ${VarCommonPrefix}SyncIndexes(ctx, dao.indexView(), dao.Indexes(), dropExtra)
+}
+
+// Schema returns the json schema derived from the fields and the gen tags of the model.
+func (dao *${VarDaoClassName}) Schema() bson.D {
+	return ${VarDaoVariableName}Schema
+}
+
+// ApplyValidator sets the json schema of the model as the validator of the collection.
+// The collection is created with the validator if it does not exist.
+func (dao *${VarDaoClassName}) ApplyValidator(ctx context.Context, level ${VarCommonPrefix}ValidationLevel, action ${VarCommonPrefix}ValidationAction) error {
+	return ${VarCommonPrefix}ApplyValidator(ctx, dao.Database, dao.Collection.Name(), dao.Schema(), level, action)
+}
+
 // Count returns the number of documents in the collection.
 func (dao *${VarDaoClassName}) Count(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}CountOptionsFunc) (int64, error) {
     var (
         opts   *options.CountOptions
-        filter = filterFunc(dao.Columns)
+        filter = dao.scoped(filterFunc(dao.Columns))
     )
 
     if len(optionsFunc) > 0 {
         opts = optionsFunc[0](dao.Columns)
     }
 
-    return dao.Collection.CountDocuments(ctx, filter, opts)
+    return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpCount, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (int64, error) {
+        return dao.Collection.CountDocuments(ctx, op.Filter, opts)
+    })
+}
+
+// EstimatedCount returns an estimate of the number of documents in the collection using collection metadata.
+func (dao *${VarDaoClassName}) EstimatedCount(ctx context.Context, optionsFunc ...${VarDaoPrefixName}EstimatedCountOptionsFunc) (int64, error) {
+	var opts *options.EstimatedDocumentCountOptions
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpEstimatedCount}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (int64, error) {
+		return dao.Collection.EstimatedDocumentCount(ctx, opts)
+	})
+}
+
+// Exists reports whether at least one document in the collection matches the filter.
+func (dao *${VarDaoClassName}) Exists(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc) (bool, error) {
+	var (
+		opts   = options.FindOne().SetProjection(bson.M{"_id": 1})
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpExists, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (bool, error) {
+		err := dao.Collection.FindOne(ctx, op.Filter, opts).Err()
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return false, nil
+			}
+			return false, err
+		}
+
+		return true, nil
+	})
 }
 
 // Aggregate executes an aggregate command against the collection and returns a cursor over the resulting documents.
 func (dao *${VarDaoClassName}) Aggregate(ctx context.Context, pipelineFunc ${VarDaoPrefixName}PipelineFunc, optionsFunc ...${VarDaoPrefixName}AggregateOptionsFunc) (*mongo.Cursor, error) {
     var (
         opts     *options.AggregateOptions
-        pipeline = pipelineFunc(dao.Columns)
+        pipeline = dao.scopedPipeline(pipelineFunc(dao.Columns))
     )
 
     if len(optionsFunc) > 0 {
         opts = optionsFunc[0](dao.Columns)
     }
 
-    return dao.Collection.Aggregate(ctx, pipeline, opts)
+    return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpAggregate, Pipeline: pipeline}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.Cursor, error) {
+        return dao.Collection.Aggregate(ctx, op.Pipeline, opts)
+    })
+}
+
+// Watch opens a change stream on the collection and returns a stream of the events decoded into the model.
+// A nil pipelineFunc watches all the changes of the collection.
+func (dao *${VarDaoClassName}) Watch(ctx context.Context, pipelineFunc ${VarDaoPrefixName}PipelineFunc, optionsFunc ...${VarDaoPrefixName}WatchOptionsFunc) (*${VarDaoPrefixName}ChangeStream, error) {
+	return dao.ResumeWatch(ctx, nil, pipelineFunc, optionsFunc...)
+}
+
+// ResumeWatch opens a change stream on the collection which resumes after the resume token saved in the store,
+// and saves the resume token of each event into the store once the next event is requested.
+func (dao *${VarDaoClassName}) ResumeWatch(ctx context.Context, store ${VarCommonPrefix}ResumeTokenStore, pipelineFunc ${VarDaoPrefixName}PipelineFunc, optionsFunc ...${VarDaoPrefixName}WatchOptionsFunc) (*${VarDaoPrefixName}ChangeStream, error) {
+	var (
+		opts     *options.ChangeStreamOptions
+		pipeline interface{}
+	)
+
+	if pipelineFunc != nil {
+		pipeline = pipelineFunc(dao.Columns)
+	}
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpWatch, Pipeline: pipeline}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*${VarDaoPrefixName}ChangeStream, error) {
+		return ${VarCommonPrefix}Watch[${VarModelPackageName}.${VarModelClassName}](ctx, dao.Collection, op.Pipeline, opts, store, dao.Columns)
+	})
+}
+
+// Distinct executes a distinct command to find the unique values for a specified column in the collection.
+func (dao *${VarDaoClassName}) Distinct(ctx context.Context, columnFunc ${VarDaoPrefixName}ColumnFunc, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DistinctOptionsFunc) ([]interface{}, error) {
+	var (
+		opts   *options.DistinctOptions
+		column = columnFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpDistinct, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) ([]interface{}, error) {
+		return dao.Collection.Distinct(ctx, column, op.Filter, opts)
+	})
+}
+
+// DistinctStrings executes a distinct command and returns the unique values of a string column.
+func (dao *${VarDaoClassName}) DistinctStrings(ctx context.Context, columnFunc ${VarDaoPrefixName}ColumnFunc, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DistinctOptionsFunc) ([]string, error) {
+	values, err := dao.Distinct(ctx, columnFunc, filterFunc, optionsFunc...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ${VarCommonPrefix}StringValues(values)
+}
+
+// DistinctInt64s executes a distinct command and returns the unique values of an integer column.
+func (dao *${VarDaoClassName}) DistinctInt64s(ctx context.Context, columnFunc ${VarDaoPrefixName}ColumnFunc, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DistinctOptionsFunc) ([]int64, error) {
+	values, err := dao.Distinct(ctx, columnFunc, filterFunc, optionsFunc...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ${VarCommonPrefix}Int64Values(values)
 }
 
 // InsertOne executes an insert command to insert a single document into the collection.
@@ -100,7 +458,7 @@ func (dao *${VarDaoClassName}) InsertOne(ctx context.Context, model *${VarModelP
 		return nil, errors.New("model is nil")
 	}
 
-	if err := dao.autofill(ctx, model); err != nil {
+	if err := dao.beforeInsert(ctx, model); err != nil {
 		return nil, err
 	}
 
@@ -110,7 +468,28 @@ func (dao *${VarDaoClassName}) InsertOne(ctx context.Context, model *${VarModelP
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.InsertOne(ctx, model, opts)
+	result, err := ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpInsertOne, Document: model}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.InsertOneResult, error) {
+		if err := dao.autofill(ctx, model); err != nil {
+			return nil, err
+		}
+
+		if err := dao.Validate(model); err != nil {
+			return nil, err
+		}
+
+		result, err := dao.Collection.InsertOne(ctx, op.Document, opts)
+
+		return result, dao.mapError(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = dao.afterInsert(ctx, model); err != nil {
+		return result, err
+	}
+
+	return result, nil
 }
 
 // InsertMany executes an insert command to insert multiple documents into the collection.
@@ -120,11 +499,11 @@ func (dao *${VarDaoClassName}) InsertMany(ctx context.Context, models []*${VarMo
 	}
 
 	documents := make([]interface{}, 0, len(models))
-	for i := range models {
-		model := models[i]
-		if err := dao.autofill(ctx, model); err != nil {
-			return nil, err
+	for i, model := range models {
+		if err := dao.beforeInsert(ctx, model); err != nil {
+			return nil, fmt.Errorf("models[%d]: %w", i, err)
 		}
+
 		documents = append(documents, model)
 	}
 
@@ -134,68 +513,163 @@ func (dao *${VarDaoClassName}) InsertMany(ctx context.Context, models []*${VarMo
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.InsertMany(ctx, documents, opts)
+	result, err := ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpInsertMany, Documents: documents}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.InsertManyResult, error) {
+		for i, model := range models {
+			if err := dao.autofill(ctx, model); err != nil {
+				return nil, err
+			}
+
+			if err := dao.Validate(model); err != nil {
+				return nil, fmt.Errorf("models[%d]: %w", i, err)
+			}
+		}
+
+		result, err := dao.Collection.InsertMany(ctx, op.Documents, opts)
+
+		return result, dao.mapError(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, model := range models {
+		if err = dao.afterInsert(ctx, model); err != nil {
+			return result, fmt.Errorf("models[%d]: %w", i, err)
+		}
+	}
+
+	return result, nil
 }
 
 // UpdateOne executes an update command to update at most one document in the collection.
-func (dao *${VarDaoClassName}) UpdateOne(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, updateFunc ${VarDaoPrefixName}UpdateFunc, optionsFunc ...${VarDaoPrefixName}UpdateOptionsFunc) (*mongo.UpdateResult, error) {
+func (dao *${VarDaoClassName}) UpdateOne(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc${VarVersionParam}, updateFunc ${VarDaoPrefixName}UpdateFunc, optionsFunc ...${VarDaoPrefixName}UpdateOptionsFunc) (*mongo.UpdateResult, error) {
 	var (
 		opts   *options.UpdateOptions
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 		update = updateFunc(dao.Columns)
 	)
 
+	update, err := dao.prepareUpdate(ctx, update)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.UpdateOne(ctx, filter, update, opts)
+	${VarVersionFilterCode}return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpUpdateOne, Filter: filter, Update: update}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.UpdateResult, error) {
+		result, err := dao.Collection.UpdateOne(ctx, op.Filter, op.Update, opts)
+
+		${VarVersionConflictCode}return result, dao.mapError(err)
+	})
 }
 
 // UpdateOneByID executes an update command to update at most one document in the collection.
-func (dao *${VarDaoClassName}) UpdateOneByID(ctx context.Context, id string, updateFunc ${VarDaoPrefixName}UpdateFunc, optionsFunc ...${VarDaoPrefixName}UpdateOptionsFunc) (*mongo.UpdateResult, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+func (dao *${VarDaoClassName}) UpdateOneByID(ctx context.Context, id string${VarVersionParam}, updateFunc ${VarDaoPrefixName}UpdateFunc, optionsFunc ...${VarDaoPrefixName}UpdateOptionsFunc) (*mongo.UpdateResult, error) {
+	objectID, err := ${VarCommonPrefix}ParseObjectID(id)
 	if err != nil {
 		return nil, err
 	}
 
     return dao.UpdateOne(ctx, func(cols *${VarDaoPrefixName}Columns) interface{} {
 		return bson.M{"_id": objectID}
-	}, updateFunc, optionsFunc...)
+	}${VarVersionArg}, updateFunc, optionsFunc...)
 }
 
 // UpdateMany executes an update command to update documents in the collection.
-func (dao *${VarDaoClassName}) UpdateMany(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, updateFunc ${VarDaoPrefixName}UpdateFunc, optionsFunc ...${VarDaoPrefixName}UpdateOptionsFunc) (*mongo.UpdateResult, error) {
+func (dao *${VarDaoClassName}) UpdateMany(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc${VarVersionParam}, updateFunc ${VarDaoPrefixName}UpdateFunc, optionsFunc ...${VarDaoPrefixName}UpdateOptionsFunc) (*mongo.UpdateResult, error) {
 	var (
 		opts   *options.UpdateOptions
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 		update = updateFunc(dao.Columns)
 	)
 
+	update, err := dao.prepareUpdate(ctx, update)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	${VarVersionFilterCode}return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpUpdateMany, Filter: filter, Update: update}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.UpdateResult, error) {
+		result, err := dao.Collection.UpdateMany(ctx, op.Filter, op.Update, opts)
+
+		${VarVersionConflictCode}return result, dao.mapError(err)
+	})
+}
+
+// ReplaceOne executes an update command to replace at most one document in the collection.
+func (dao *${VarDaoClassName}) ReplaceOne(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, model *${VarModelPackageName}.${VarModelClassName}, optionsFunc ...${VarDaoPrefixName}ReplaceOptionsFunc) (*mongo.UpdateResult, error) {
+	if model == nil {
+		return nil, errors.New("model is nil")
+	}
+
+	if err := dao.beforeUpdate(ctx, model); err != nil {
+		return nil, err
+	}
+
+	if err := dao.Validate(model); err != nil {
+		return nil, err
+	}
+
+	var (
+		opts   *options.ReplaceOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.UpdateMany(ctx, filter, update, opts)
+	${VarReplaceCode}
+}
+
+// replaceOne replaces the document through the interceptors
+func (dao *${VarDaoClassName}) replaceOne(ctx context.Context, filter interface{}, model *${VarModelPackageName}.${VarModelClassName}, opts *options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpReplaceOne, Filter: filter, Document: model}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.UpdateResult, error) {
+		result, err := dao.Collection.ReplaceOne(ctx, op.Filter, op.Document, opts)
+
+		return result, dao.mapError(err)
+	})
 }
 
 // FindOne executes a find command and returns a model for one document in the collection.
+${VarFindOneNotFoundDoc}
 func (dao *${VarDaoClassName}) FindOne(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}FindOneOptionsFunc) (*${VarModelPackageName}.${VarModelClassName}, error) {
 	var (
 		opts   *options.FindOneOptions
-		model  = &${VarModelPackageName}.${VarModelClassName}{}
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 	)
 
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	err := dao.Collection.FindOne(ctx, filter, opts).Decode(model)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, nil
+	model, err := ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpFindOne, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*${VarModelPackageName}.${VarModelClassName}, error) {
+		model := &${VarModelPackageName}.${VarModelClassName}{}
+
+		err := dao.Collection.FindOne(ctx, op.Filter, opts).Decode(model)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return nil, nil
+			}
+			return nil, err
 		}
+
+		return model, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if model == nil {
+		return nil, dao.notFound()
+	}
+
+	if err = dao.afterFind(ctx, model); err != nil {
 		return nil, err
 	}
 
@@ -204,7 +678,7 @@ func (dao *${VarDaoClassName}) FindOne(ctx context.Context, filterFunc ${VarDaoP
 
 // FindOneByID executes a find command and returns a model for one document in the collection.
 func (dao *${VarDaoClassName}) FindOneByID(ctx context.Context, id string, optionsFunc ...${VarDaoPrefixName}FindOneOptionsFunc) (*${VarModelPackageName}.${VarModelClassName}, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+	objectID, err := ${VarCommonPrefix}ParseObjectID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -218,21 +692,139 @@ func (dao *${VarDaoClassName}) FindOneByID(ctx context.Context, id string, optio
 func (dao *${VarDaoClassName}) FindMany(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}FindManyOptionsFunc) ([]*${VarModelPackageName}.${VarModelClassName}, error) {
 	var (
 		opts   *options.FindOptions
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	models, err := ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpFindMany, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) ([]*${VarModelPackageName}.${VarModelClassName}, error) {
+		cur, err := dao.Collection.Find(ctx, op.Filter, dao.withDefaultSort(opts))
+		if err != nil {
+			return nil, err
+		}
+
+		models := make([]*${VarModelPackageName}.${VarModelClassName}, 0)
+
+		if err = cur.All(ctx, &models); err != nil {
+			return nil, err
+		}
+
+		return models, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = dao.afterFind(ctx, models...); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+// ${VarDaoPrefixName}FindOneAs executes a find command and decodes one document into the partial struct P.
+// Only the columns declared by the bson tags of P are fetched unless the options specify a projection.
+func ${VarDaoPrefixName}FindOneAs[P any](ctx context.Context, dao *${VarDaoClassName}, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}FindOneOptionsFunc) (*P, error) {
+	var (
+		opts   *options.FindOneOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
+	)
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	model, err := ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpFindOne, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*P, error) {
+		return ${VarCommonPrefix}FindOneAs[P](ctx, dao.Collection, op.Filter, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if model == nil {
+		return nil, dao.notFound()
+	}
+
+	return model, nil
+}
+
+// ${VarDaoPrefixName}FindManyAs executes a find command and decodes the matching documents into the partial struct P.
+// Only the columns declared by the bson tags of P are fetched unless the options specify a projection.
+func ${VarDaoPrefixName}FindManyAs[P any](ctx context.Context, dao *${VarDaoClassName}, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}FindManyOptionsFunc) ([]*P, error) {
+	var (
+		opts   *options.FindOptions
+		filter = dao.scoped(filterFunc(dao.Columns))
 	)
 
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	cur, err := dao.Collection.Find(ctx, filter, opts)
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpFindMany, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) ([]*P, error) {
+		return ${VarCommonPrefix}FindManyAs[P](ctx, dao.Collection, op.Filter, dao.withDefaultSort(opts))
+	})
+}
+
+// FindManyByIDs executes a find command and returns the models matching the ids in the same order as the ids.
+// Ids that do not match any document are skipped and repeated ids are returned only once.
+func (dao *${VarDaoClassName}) FindManyByIDs(ctx context.Context, ids []string, optionsFunc ...${VarDaoPrefixName}FindManyOptionsFunc) ([]*${VarModelPackageName}.${VarModelClassName}, error) {
+	objectIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objectID, err := ${VarCommonPrefix}ParseObjectID(id)
+		if err != nil {
+			return nil, err
+		}
+		objectIDs = append(objectIDs, objectID)
+	}
+
+	if len(objectIDs) == 0 {
+		return make([]*${VarModelPackageName}.${VarModelClassName}, 0), nil
+	}
+
+	var opts *options.FindOptions
+
+	if len(optionsFunc) > 0 {
+		opts = optionsFunc[0](dao.Columns)
+	}
+
+	found, err := ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpFindMany, Filter: dao.scoped(bson.M{"_id": bson.M{"$in": objectIDs}})}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (map[primitive.ObjectID]*${VarModelPackageName}.${VarModelClassName}, error) {
+		cur, err := dao.Collection.Find(ctx, op.Filter, opts)
+		if err != nil {
+			return nil, err
+		}
+		defer cur.Close(ctx)
+
+		found := make(map[primitive.ObjectID]*${VarModelPackageName}.${VarModelClassName}, len(objectIDs))
+		for cur.Next(ctx) {
+			objectID, ok := cur.Current.Lookup("_id").ObjectIDOK()
+			if !ok {
+				continue
+			}
+
+			model := &${VarModelPackageName}.${VarModelClassName}{}
+			if err = cur.Decode(model); err != nil {
+				return nil, err
+			}
+			found[objectID] = model
+		}
+
+		return found, cur.Err()
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	models := make([]*${VarModelPackageName}.${VarModelClassName}, 0)
-	
-	if err = cur.All(ctx, &models); err != nil {
+	models := make([]*${VarModelPackageName}.${VarModelClassName}, 0, len(found))
+	for _, objectID := range objectIDs {
+		if model, ok := found[objectID]; ok {
+			models = append(models, model)
+			delete(found, objectID)
+		}
+	}
+
+	if err = dao.afterFind(ctx, models...); err != nil {
 		return nil, err
 	}
 
@@ -240,22 +832,25 @@ func (dao *${VarDaoClassName}) FindMany(ctx context.Context, filterFunc ${VarDao
 }
 
 // DeleteOne executes a delete command to delete at most one document from the collection.
+// The document is marked as deleted instead of being removed when the model is soft deleted.
 func (dao *${VarDaoClassName}) DeleteOne(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DeleteOptionsFunc) (*mongo.DeleteResult, error) {
 	var (
 		opts   *options.DeleteOptions
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 	)
 
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.DeleteOne(ctx, filter, opts)
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpDeleteOne, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.DeleteResult, error) {
+		${VarDeleteOneCode}
+	})
 }
 
 // DeleteOneByID executes a delete command to delete at most one document from the collection.
 func (dao *${VarDaoClassName}) DeleteOneByID(ctx context.Context, id string, optionsFunc ...${VarDaoPrefixName}DeleteOptionsFunc) (*mongo.DeleteResult, error) {
-	objectID, err := primitive.ObjectIDFromHex(id)
+	objectID, err := ${VarCommonPrefix}ParseObjectID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -266,21 +861,111 @@ func (dao *${VarDaoClassName}) DeleteOneByID(ctx context.Context, id string, opt
 }
 
 // DeleteMany executes a delete command to delete documents from the collection.
+// The documents are marked as deleted instead of being removed when the model is soft deleted.
 func (dao *${VarDaoClassName}) DeleteMany(ctx context.Context, filterFunc ${VarDaoPrefixName}FilterFunc, optionsFunc ...${VarDaoPrefixName}DeleteOptionsFunc) (*mongo.DeleteResult, error) {
 	var (
 		opts   *options.DeleteOptions
-		filter = filterFunc(dao.Columns)
+		filter = dao.scoped(filterFunc(dao.Columns))
 	)
 
 	if len(optionsFunc) > 0 {
 		opts = optionsFunc[0](dao.Columns)
 	}
 
-	return dao.Collection.DeleteMany(ctx, filter, opts)
+	return ${VarCommonPrefix}Invoke(ctx, ${VarCommonPrefix}OpInfo{Database: dao.Database.Name(), Collection: dao.Collection.Name(), Operation: ${VarCommonPrefix}OpDeleteMany, Filter: filter}, func(ctx context.Context, op ${VarCommonPrefix}OpInfo) (*mongo.DeleteResult, error) {
+		${VarDeleteManyCode}
+	})
+}
+
+${VarSoftDeleteMethods}
+
+
+${VarModelSensitive}
+
+// convert the duplicate key error of the driver into a DuplicateKeyError with the field names of the model
+func (dao *${VarDaoClassName}) mapError(err error) error {
+	return ${VarCommonPrefix}MapError(err, ${VarDaoVariableName}Indexes, ${VarDaoVariableName}FieldNames)
+}
+
+// the error returned by the find one methods when no document matches
+func (dao *${VarDaoClassName}) notFound() error {
+	${VarNotFoundCode}
+}
+
+// apply the soft delete scope of the dao to the filter
+func (dao *${VarDaoClassName}) scoped(filter interface{}) interface{} {
+	return ${VarDaoVariableName}SoftDelete.Apply(filter, dao.scope)
+}
+
+// prepend the soft delete scope of the dao to the pipeline as a match stage
+func (dao *${VarDaoClassName}) scopedPipeline(pipeline interface{}) interface{} {
+	return ${VarDaoVariableName}SoftDelete.ApplyPipeline(pipeline, dao.scope)
+}
+
+// resolve the index view used to inspect and change the indexes of the collection
+func (dao *${VarDaoClassName}) indexView() ${VarCommonPrefix}IndexView {
+	if dao.IndexView != nil {
+		return dao.IndexView
+	}
+
+	return ${VarCommonPrefix}NewIndexView(dao.Collection.Indexes())
+}
+
+// prepare the update document, which fails with the validation error of the update func, is passed to the BeforeUpdateDocument hook,
+// must not be empty and increments the version of the versioned model
+func (dao *${VarDaoClassName}) prepareUpdate(ctx context.Context, update interface{}) (interface{}, error) {
+	if err, ok := update.(error); ok {
+		return nil, err
+	}
+
+	update, err := dao.beforeUpdateDocument(ctx, update)
+	if err != nil {
+		return nil, err
+	}
+
+	if ${VarCommonPrefix}IsEmptyUpdate(update) {
+		return nil, ${VarCommonPrefix}ErrEmptyUpdate
+	}
+
+	${VarIncVersionCode}
+}
+
+// apply the default sort when the options do not specify a sort
+func (dao *${VarDaoClassName}) withDefaultSort(opts *options.FindOptions) *options.FindOptions {
+	if ${VarDaoVariableName}DefaultSort == nil || (opts != nil && opts.Sort != nil) {
+		return opts
+	}
+
+	return options.MergeFindOptions(opts).SetSort(${VarDaoVariableName}DefaultSort)
 }
 
 // autofill when inserting data
 func (dao *${VarDaoClassName}) autofill(ctx context.Context, model *${VarModelPackageName}.${VarModelClassName}) error {
 	${VarAutofillCode}
 }
+
+// beforeInsert calls the BeforeInsert hook of the model
+func (dao *${VarDaoClassName}) beforeInsert(ctx context.Context, model *${VarModelPackageName}.${VarModelClassName}) error {
+	${VarBeforeInsertCode}
+}
+
+// afterInsert calls the AfterInsert hook of the model
+func (dao *${VarDaoClassName}) afterInsert(ctx context.Context, model *${VarModelPackageName}.${VarModelClassName}) error {
+	${VarAfterInsertCode}
+}
+
+// beforeUpdate calls the BeforeUpdate hook of the model
+func (dao *${VarDaoClassName}) beforeUpdate(ctx context.Context, model *${VarModelPackageName}.${VarModelClassName}) error {
+	${VarBeforeUpdateCode}
+}
+
+// beforeUpdateDocument calls the BeforeUpdateDocument hook of the model with the update document
+func (dao *${VarDaoClassName}) beforeUpdateDocument(ctx context.Context, update interface{}) (interface{}, error) {
+	${VarBeforeUpdateDocumentCode}
+}
+
+// afterFind calls the AfterFind hook of the found models
+func (dao *${VarDaoClassName}) afterFind(ctx context.Context, models ...*${VarModelPackageName}.${VarModelClassName}) error {
+	${VarAfterFindCode}
+}
 `
@@ -0,0 +1,124 @@
+package template
+
+const TestTemplate = `
+// --------------------------------------------------------------------------------------------
+// The following code is automatically generated by the mongo-dao-generator tool.
+// Please do not modify this code manually to avoid being overwritten in the next generation.
+// For more tool details, please click the link to view https://github.com/dobyte/mongo-dao-generator
+// --------------------------------------------------------------------------------------------
+
+package ${VarDaoPackageName}_test
+
+import (
+	${VarPackages}
+)
+
+// Test${VarModelClassName}Dao round-trips a model built by the factory through the dao against the database of MONGO_URI.
+// The test is skipped when MONGO_URI is not set, the documents are written to a new database which is dropped afterwards.
+func Test${VarModelClassName}Dao(t *testing.T) {
+	uri := os.Getenv("MONGO_URI")
+	if uri == "" {
+		t.Skip("MONGO_URI is not set")
+	}
+
+	ctx := context.Background()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = client.Disconnect(ctx)
+	})
+
+	db := client.Database(fmt.Sprintf("test_%d", time.Now().UnixNano()))
+	t.Cleanup(func() {
+		_ = db.Drop(ctx)
+	})
+
+	repo := ${VarDaoPackageName}.New${VarDaoClassName}(db)
+
+	if _, err = repo.EnsureIndexes(ctx); err != nil {
+		t.Fatalf("ensure indexes: %v", err)
+	}
+
+	model := ${VarDaoPackageName}.New${VarDaoPrefixName}Factory().Build()
+
+	result, err := repo.InsertOne(ctx, model)
+	if err != nil {
+		t.Fatalf("insert one: %v", err)
+	}
+
+	${VarTestAutofillChecks}byID := func(cols *${VarDaoPackageName}.${VarDaoPrefixName}Columns) interface{} {
+		return bson.D{{Key: "_id", Value: result.InsertedID}}
+	}
+
+	found, err := repo.FindOne(ctx, byID)
+	if err != nil {
+		t.Fatalf("find one: %v", err)
+	}
+	check${VarModelClassName}RoundTrip(t, "find one", found, model)
+
+	replaced, err := repo.ReplaceOne(ctx, byID, model)
+	if err != nil {
+		t.Fatalf("replace one: %v", err)
+	}
+	if replaced.MatchedCount != 1 {
+		t.Fatalf("replace one: %d documents matched, want 1", replaced.MatchedCount)
+	}
+
+	found, err = repo.FindOne(ctx, byID)
+	if err != nil {
+		t.Fatalf("find one after replace one: %v", err)
+	}
+	check${VarModelClassName}RoundTrip(t, "find one after replace one", found, model)
+
+	deleted, err := repo.DeleteOne(ctx, byID)
+	if err != nil {
+		t.Fatalf("delete one: %v", err)
+	}
+	if deleted.DeletedCount != 1 {
+		t.Fatalf("delete one: %d documents deleted, want 1", deleted.DeletedCount)
+	}
+
+	count, err := repo.Count(ctx, byID)
+	if err != nil {
+		t.Fatalf("count after delete one: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("count after delete one: %d documents found, want 0", count)
+	}
+}
+
+// check${VarModelClassName}RoundTrip compares the found model with the written model after encoding both to bson and back,
+// which truncates the times to milliseconds like the database does.
+func check${VarModelClassName}RoundTrip(t *testing.T, op string, found, model *${VarModelPackageName}.${VarModelClassName}) {
+	t.Helper()
+
+	if found == nil {
+		t.Fatalf("%s: the document is not found", op)
+	}
+
+	got, want := roundTrip${VarModelClassName}(t, found), roundTrip${VarModelClassName}(t, model)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s:\n\tgot:  %+v\n\twant: %+v", op, got, want)
+	}
+}
+
+// roundTrip${VarModelClassName} encodes the model to bson and decodes it into a new model.
+func roundTrip${VarModelClassName}(t *testing.T, model *${VarModelPackageName}.${VarModelClassName}) *${VarModelPackageName}.${VarModelClassName} {
+	t.Helper()
+
+	data, err := bson.Marshal(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := &${VarModelPackageName}.${VarModelClassName}{}
+	if err = bson.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	return decoded
+}
+`
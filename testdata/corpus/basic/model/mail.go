@@ -0,0 +1,15 @@
+package model
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Mail is soft deleted and sorted by the send time.
+//
+//gen:sort -SendTime
+type Mail struct {
+	ID        primitive.ObjectID `bson:"_id" gen:"autoFill"`
+	Title     string             `bson:"title" gen:"required;len=1..64"`
+	Receiver  int64              `bson:"receiver" gen:"index:group=recv"`
+	Status    int8               `bson:"status" gen:"index:group=recv;oneof=0 1 2"`
+	SendTime  primitive.DateTime `bson:"send_time" gen:"autoFill"`
+	DeletedAt primitive.DateTime `bson:"deleted_at" gen:"softDelete"`
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+type Gender int
+
+const (
+	GenderUnknown Gender = iota // unknown
+	GenderMale                  // male
+	GenderFemale                // female
+)
+
+// Level is not an enum, the constant is only the bound of the levels.
+//gen:enum -
+type Level int
+
+const LevelMax Level = 100
+
+//gen:sort -CreatedAt,Account
+type User struct {
+	ID        primitive.ObjectID `bson:"_id" gen:"autoFill"`
+	UID       int32              `bson:"uid" gen:"autoIncr:uid;unique"`
+	Seq       uint16             `bson:"seq" gen:"autoIncr:seq"`
+	Account   string             `bson:"account" gen:"unique;required;len=4..32"`
+	Password  string             `bson:"password" gen:"sensitive"`
+	Email     string             `bson:"email" gen:"email"`
+	Mobile    string             `bson:"mobile,omitempty" gen:"unique:partial;pattern=^1[0-9]{10}$"`
+	Gender    Gender             `bson:"gender"`
+	Level     Level              `bson:"level" gen:"min=0;max=100"`
+	Role      string             `bson:"role" gen:"oneof=admin member"`
+	Tags      []string           `bson:"tags" gen:"index;len=..8"`
+	Score     float64            `bson:"score" gen:"index:name=idx_score_level,order=-1,group=score"`
+	CreatedAt primitive.DateTime `bson:"created_at" gen:"autoFill"`
+	ExpireAt  time.Time          `bson:"expire_at" gen:"ttl:3600"`
+	Version   int64              `bson:"version" gen:"version"`
+}
+
+func (u *User) BeforeInsert(ctx context.Context) error {
+	return nil
+}
+
+func (u *User) AfterFind(ctx context.Context) error {
+	return nil
+}
+
+func (u *User) BeforeUpdate(ctx context.Context) error {
+	return nil
+}
+
+func (u *User) BeforeUpdateDocument(ctx context.Context, update interface{}) (interface{}, error) {
+	return update, nil
+}
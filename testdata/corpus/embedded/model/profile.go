@@ -0,0 +1,45 @@
+package model
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Score is a named numeric type, which supports the numeric rules.
+type Score int64
+
+// Timestamps is embedded as a sub document named after the type.
+type Timestamps struct {
+	CreatedAt primitive.DateTime `bson:"created_at"`
+	UpdatedAt primitive.DateTime `bson:"updated_at"`
+}
+
+// Audit is inlined, its columns are not parsed.
+type Audit struct {
+	Operator string `bson:"operator"`
+}
+
+type Labels map[string]string
+
+type Profile struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" gen:"autoFill"`
+	Counter    uint               `bson:"counter" gen:"autoIncr:profile"`
+	Timestamps `bson:"timestamps"`
+	*Audit     `bson:",inline"`
+	Friend
+	Nickname  string      `bson:"nickname,omitempty" gen:"required"`
+	Score     Score       `bson:"score" gen:"min=1"`
+	Labels    Labels      `bson:"labels"`
+	Friends   []Friend    `bson:"friends"`
+	Manager   *Friend     `bson:"manager"`
+	Ignored   string      `bson:"-"`
+	Raw       []byte      `bson:"raw"`
+	Any       interface{} `bson:"any"`
+	Active    bool        `bson:"active" gen:"required"`
+	DeletedAt time.Time   `bson:"deleted_at" gen:"softDelete"`
+	private   string
+}
+
+type Friend struct {
+	Name string `bson:"name"`
+}
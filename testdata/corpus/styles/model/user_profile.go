@@ -0,0 +1,15 @@
+package model
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type UserProfile struct {
+	ID       primitive.ObjectID `bson:"_id" gen:"autoFill"`
+	PlayerID int64              `bson:"player_id" gen:"autoIncr:player;unique"`
+	Nickname string             `bson:"nickname"`
+}
+
+type LoginRecord struct {
+	ID        primitive.ObjectID `bson:"_id" gen:"autoFill"`
+	PlayerID  int64              `bson:"player_id" gen:"index"`
+	LoginTime primitive.DateTime `bson:"login_time" gen:"autoFill;ttl:86400"`
+}
@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"context"
+	modelpkg "example.com/corpus/basic/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+)
+
+// TestEmptyFilters checks that an empty Or matches no document and an empty Nor matches all the documents.
+func TestEmptyFilters(t *testing.T) {
+	ctx := context.Background()
+	dao := NewMailMemory()
+
+	models := []*modelpkg.Mail{{Title: "a"}, {Title: "b"}, {Title: "c"}}
+	if _, err := dao.InsertMany(ctx, models); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter func(f *MailFilter) bson.D
+		count  int64
+	}{
+		{
+			name:   "or",
+			filter: func(f *MailFilter) bson.D { return f.Or() },
+			count:  0,
+		},
+		{
+			name:   "nor",
+			filter: func(f *MailFilter) bson.D { return f.Nor() },
+			count:  3,
+		},
+		{
+			name:   "and",
+			filter: func(f *MailFilter) bson.D { return f.And() },
+			count:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := dao.Count(ctx, dao.Where(tt.filter))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if count != tt.count {
+				t.Errorf("%d documents counted, want %d", count, tt.count)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"testing"
+)
+
+// TestSoftDeleteOptions checks that the options of the delete are passed to the update marking the documents.
+func TestSoftDeleteOptions(t *testing.T) {
+	s := &SoftDelete{Column: "deleted_at"}
+
+	if opts := s.updateOptions(nil); opts != nil {
+		t.Errorf("options = %v, want nil", opts)
+	}
+
+	collation := &options.Collation{Locale: "en", Strength: 2}
+	opts := s.updateOptions(options.Delete().SetCollation(collation).SetHint("idx_email").SetComment("cleanup"))
+
+	if opts.Collation != collation {
+		t.Errorf("collation = %v, want %v", opts.Collation, collation)
+	}
+
+	if opts.Hint != "idx_email" {
+		t.Errorf("hint = %v, want idx_email", opts.Hint)
+	}
+
+	if opts.Comment != "cleanup" {
+		t.Errorf("comment = %v, want cleanup", opts.Comment)
+	}
+}
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	modelpkg "example.com/corpus/basic/model"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"testing"
+)
+
+// TestVersionedUpdates checks that the updates of the versioned model only apply to the documents holding the version.
+func TestVersionedUpdates(t *testing.T) {
+	ctx := context.Background()
+	dao := NewUserMemory()
+
+	model := &modelpkg.User{Account: "tester", Email: "tester@example.com", Mobile: "13800000000", Role: "admin"}
+	if _, err := dao.InsertOne(ctx, model); err != nil {
+		t.Fatal(err)
+	}
+
+	id := model.ID.Hex()
+	update := func(cols *UserColumns) interface{} {
+		return bson.M{"$set": bson.M{cols.Score: 1}}
+	}
+
+	if _, err := dao.UpdateOneByID(ctx, id, 0, update); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := dao.UpdateOneByID(ctx, id, 0, update); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("update with the stale version returned %v, want %v", err, ErrVersionConflict)
+	}
+
+	all := func(cols *UserColumns) interface{} {
+		return bson.D{}
+	}
+
+	if _, err := dao.UpdateMany(ctx, all, 0, update); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("update many with the stale version returned %v, want %v", err, ErrVersionConflict)
+	}
+
+	upsert := func(cols *UserColumns) *options.UpdateOptions {
+		return options.Update().SetUpsert(true)
+	}
+
+	if _, err := dao.UpdateOne(ctx, all, 1, update, upsert); !errors.Is(err, ErrVersionUpsert) {
+		t.Errorf("upsert returned %v, want %v", err, ErrVersionUpsert)
+	}
+
+	if _, err := dao.UpdateMany(ctx, all, 1, update); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := dao.FindOneByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Version != 2 {
+		t.Errorf("version = %d, want 2", user.Version)
+	}
+}
@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"strings"
+	"testing"
+)
+
+// TestTelemetryInterceptor checks that the span of a failed operation records the kind of the error but not its message,
+// which contains the duplicate key.
+func TestTelemetryInterceptor(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+
+	const key = "13800000000"
+
+	dup := mongo.WriteException{WriteErrors: mongo.WriteErrors{{
+		Code:    11000,
+		Message: `E11000 duplicate key error collection: test.mail index: mobile_1 dup key: { mobile: "` + key + `" }`,
+	}}}
+
+	op := OpInfo{Database: "test", Collection: "mail", Operation: OpInsertOne}
+
+	err := TelemetryInterceptor(context.Background(), op, func(ctx context.Context, op OpInfo) error {
+		return dup
+	})
+	if !mongo.IsDuplicateKeyError(err) {
+		t.Fatalf("err = %v, want the duplicate key error", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("%d spans, want 1", len(spans))
+	}
+
+	span := spans[0]
+
+	if span.Name != "mongo.mail.InsertOne" {
+		t.Errorf("span name = %s, want mongo.mail.InsertOne", span.Name)
+	}
+
+	if span.Status.Code != codes.Error || span.Status.Description != "duplicate_key" {
+		t.Errorf("span status = %v %q, want Error \"duplicate_key\"", span.Status.Code, span.Status.Description)
+	}
+
+	for _, attr := range span.Attributes {
+		if strings.Contains(attr.Value.Emit(), key) {
+			t.Errorf("span attribute %s contains the duplicate key", attr.Key)
+		}
+	}
+
+	for _, event := range span.Events {
+		for _, attr := range event.Attributes {
+			if strings.Contains(attr.Value.Emit(), key) {
+				t.Errorf("span event %s contains the duplicate key", event.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// the imports of the generated test, which is an external test package of the dao
+func (m *model) testPackages() string {
+	imports := map[string]string{
+		pkg1:           "",
+		pkg2:           "",
+		pkg4:           "",
+		pkg5:           "",
+		pkg7:           "",
+		pkg8:           "",
+		"os":           "",
+		"reflect":      "",
+		"testing":      "",
+		m.daoPkgPath:   "",
+		m.modelPkgPath: m.imports[m.modelPkgPath],
+	}
+
+	if filepath.Base(m.daoPkgPath) != m.daoPkgName {
+		imports[m.daoPkgPath] = m.daoPkgName
+	}
+
+	return formatPackages(imports)
+}
+
+// the checks of the generated test that the autofill fields are filled by the insertion
+func (m *model) testAutofillChecks() (str string) {
+	for _, f := range m.fields {
+		var cond string
+
+		switch f.autoFill {
+		case objectID:
+			cond = fmt.Sprintf("model.%s.IsZero()", f.name)
+		case dateTime, autoIncr:
+			cond = fmt.Sprintf("model.%s == 0", f.name)
+		default:
+			continue
+		}
+
+		str += fmt.Sprintf("if %s {\n\t\tt.Error(\"insert one: %s is not filled\")\n\t}\n\n\t", cond, f.name)
+	}
+
+	return
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"fmt"
+	"go/ast"
+	"go/types"
 	"os"
-	"path/filepath"
+	"sort"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -134,8 +137,9 @@ func toFileName(s string, style style) string {
 	}
 }
 
-func doWrite(file string, tpl string, replaces map[string]string) error {
-	s := os.Expand(tpl, func(s string) string {
+// replace the variables and symbols of the template, other dollar signs are kept as is
+func doExpand(tpl string, replaces map[string]string) string {
+	return os.Expand(tpl, func(s string) string {
 		switch {
 		case len(s) >= 3 && s[:3] == "Var":
 			return replaces[s]
@@ -145,15 +149,105 @@ func doWrite(file string, tpl string, replaces map[string]string) error {
 			return "$" + s
 		}
 	})
+}
 
-	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
-		return err
+// the name of the embedded field declared by the type, empty if the type cannot be embedded
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.IndexExpr:
+		return embeddedName(t.X)
+	case *ast.IndexListExpr:
+		return embeddedName(t.X)
+	default:
+		return ""
 	}
+}
 
-	return os.WriteFile(file, []byte(strings.TrimPrefix(s, "\n")), os.ModePerm)
+// check whether the items contain the string
+func contains(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
 }
 
 func isExportable(s string) bool {
 	r, _ := utf8.DecodeRuneInString(s)
 	return unicode.IsUpper(r)
 }
+
+// check whether the type can be referenced outside the package in which it is declared
+func isReferable(typ types.Type, pkg *types.Package) bool {
+	switch t := typ.(type) {
+	case *types.Basic:
+		return true
+	case *types.Named:
+		if !isReferableObj(t.Obj(), pkg) {
+			return false
+		}
+		if args := t.TypeArgs(); args != nil {
+			for i := 0; i < args.Len(); i++ {
+				if !isReferable(args.At(i), pkg) {
+					return false
+				}
+			}
+		}
+		return true
+	case *types.Pointer:
+		return isReferable(t.Elem(), pkg)
+	case *types.Slice:
+		return isReferable(t.Elem(), pkg)
+	case *types.Array:
+		return isReferable(t.Elem(), pkg)
+	case *types.Map:
+		return isReferable(t.Key(), pkg) && isReferable(t.Elem(), pkg)
+	default:
+		// the aliases are materialized as types by the type checker of go1.22 and later, they are referred by name
+		if alias, ok := typ.(interface{ Obj() *types.TypeName }); ok && alias.Obj().IsAlias() {
+			return isReferableObj(alias.Obj(), pkg)
+		}
+		return false
+	}
+}
+
+// check whether the named type or alias can be referred from the generated code
+func isReferableObj(obj *types.TypeName, pkg *types.Package) bool {
+	if obj.Pkg() != nil && !obj.Exported() {
+		return false
+	}
+	if obj.Pkg() != nil && obj.Pkg() != pkg && strings.Contains(obj.Pkg().Path(), "/internal") {
+		return false
+	}
+	return true
+}
+
+// format the import specs of the packages sorted by path, the packages are mapped to their aliases
+func formatPackages(imports map[string]string) (str string) {
+	packages := make([]string, 0, len(imports))
+	for pkg := range imports {
+		packages = append(packages, pkg)
+	}
+
+	sort.Strings(packages)
+
+	for _, pkg := range packages {
+		if alias := imports[pkg]; alias != "" {
+			str += fmt.Sprintf("\t%s \"%s\"\n", alias, pkg)
+		} else {
+			str += fmt.Sprintf("\t\"%s\"\n", pkg)
+		}
+	}
+
+	str = strings.TrimPrefix(str, "\t")
+	str = strings.TrimSuffix(str, "\n")
+	return
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNamingStyles(t *testing.T) {
+	tests := []struct {
+		name       string
+		underscore string
+		kebab      string
+		camel      string
+		pascal     string
+		lower      string
+	}{
+		{name: "User", underscore: "user", kebab: "user", camel: "user", pascal: "User", lower: "user"},
+		{name: "UserProfile", underscore: "user_profile", kebab: "user-profile", camel: "userProfile", pascal: "UserProfile", lower: "userprofile"},
+		{name: "LoginRecordMemory", underscore: "login_record_memory", kebab: "login-record-memory", camel: "loginRecordMemory", pascal: "LoginRecordMemory", lower: "loginrecordmemory"},
+		{name: "UserID", underscore: "user_i_d", kebab: "user-i-d", camel: "userID", pascal: "UserID", lower: "userid"},
+	}
+
+	for _, tt := range tests {
+		styles := map[style]string{
+			underscoreCase: tt.underscore,
+			kebabCase:      tt.kebab,
+			camelCase:      tt.camel,
+			pascalCase:     tt.pascal,
+			lowerCase:      tt.lower,
+		}
+
+		for s, want := range styles {
+			if got := toFileName(tt.name, s); got != want {
+				t.Errorf("toFileName(%q, %s) = %q, want %q", tt.name, s, got, want)
+			}
+
+			if got := toPackagePath(tt.name, s); got != want {
+				t.Errorf("toPackagePath(%q, %s) = %q, want %q", tt.name, s, got, want)
+			}
+		}
+	}
+
+	if got := toFileName("UserProfile", ""); got != "user_profile" {
+		t.Errorf("toFileName with the default style = %q, want user_profile", got)
+	}
+
+	if got := toPackagePath("UserProfile", ""); got != "user-profile" {
+		t.Errorf("toPackagePath with the default style = %q, want user-profile", got)
+	}
+}
+
+func TestCaseConversions(t *testing.T) {
+	tests := []struct {
+		in     string
+		camel  string
+		pascal string
+		pkg    string
+	}{
+		{in: "counter", camel: "counter", pascal: "Counter", pkg: "counter"},
+		{in: "id-counter", camel: "idCounter", pascal: "IdCounter", pkg: "idcounter"},
+		{in: "login_record", camel: "loginRecord", pascal: "LoginRecord", pkg: "loginrecord"},
+		{in: "UserProfile", camel: "userProfile", pascal: "UserProfile", pkg: "userprofile"},
+	}
+
+	for _, tt := range tests {
+		if got := toCamelCase(tt.in); got != tt.camel {
+			t.Errorf("toCamelCase(%q) = %q, want %q", tt.in, got, tt.camel)
+		}
+
+		if got := toPascalCase(tt.in); got != tt.pascal {
+			t.Errorf("toPascalCase(%q) = %q, want %q", tt.in, got, tt.pascal)
+		}
+
+		if got := toPackageName(tt.in); got != tt.pkg {
+			t.Errorf("toPackageName(%q) = %q, want %q", tt.in, got, tt.pkg)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const pkgRegexp = "regexp"
+const pkgUTF8 = "unicode/utf8"
+
+// the validation rules declared by the gen tag of a field
+type fieldRules struct {
+	enum     bool
+	required bool
+	min      string
+	max      string
+	lenMin   string
+	lenMax   string
+	email    bool
+	oneof    []string
+	pattern  string
+}
+
+// a condition which fails the validation of a field
+type fieldCheck struct {
+	cond    string
+	rule    string
+	message string
+}
+
+// set a validation rule from the gen tag
+func (r *fieldRules) set(f *field, key, value string) error {
+	switch key {
+	case "required":
+		if f.typeName != "interface{}" && !types.Comparable(f.typ) && !hasLen(f.typ) && !isNilable(f.typ) {
+			return fmt.Errorf("error: %s rule is not supported by field type %s", key, f.typeName)
+		}
+
+		r.required = true
+	case "min", "max":
+		if err := checkNumber(f, key, value); err != nil {
+			return err
+		}
+
+		if key == "min" {
+			r.min = value
+		} else {
+			r.max = value
+		}
+	case "len":
+		if !hasLen(f.typ) {
+			return fmt.Errorf("error: %s rule is only supported by string, slice, array and map field", key)
+		}
+
+		bounds := strings.SplitN(value, "..", 2)
+		if len(bounds) == 1 {
+			bounds = append(bounds, bounds[0])
+		}
+
+		for _, bound := range bounds {
+			if bound == "" {
+				continue
+			}
+
+			if n, err := strconv.Atoi(bound); err != nil || n < 0 {
+				return fmt.Errorf("error: invalid %s rule value %s", key, value)
+			}
+		}
+
+		if bounds[0] == "" && bounds[1] == "" {
+			return fmt.Errorf("error: invalid %s rule value %s", key, value)
+		}
+
+		r.lenMin, r.lenMax = bounds[0], bounds[1]
+	case "email":
+		if f.kind != stringField {
+			return fmt.Errorf("error: %s rule is only supported by string field", key)
+		}
+
+		r.email = true
+	case "oneof":
+		values := strings.Fields(value)
+		if len(values) == 0 {
+			return fmt.Errorf("error: empty %s rule value", key)
+		}
+
+		switch f.kind {
+		case stringField:
+		case numberField:
+			for _, v := range values {
+				if err := checkNumber(f, key, v); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("error: %s rule is only supported by string and numeric field", key)
+		}
+
+		r.oneof = values
+	case "pattern":
+		if f.kind != stringField {
+			return fmt.Errorf("error: %s rule is only supported by string field", key)
+		}
+
+		if value == "" {
+			return fmt.Errorf("error: empty %s rule value", key)
+		}
+
+		if _, err := regexp.Compile(value); err != nil {
+			return fmt.Errorf("error: invalid %s rule value %s", key, value)
+		}
+
+		r.pattern = value
+	}
+
+	return nil
+}
+
+// the go literals of the oneof values
+func (r *fieldRules) oneofLiterals(f *field) []string {
+	if f.kind != stringField {
+		return r.oneof
+	}
+
+	literals := make([]string, 0, len(r.oneof))
+	for _, v := range r.oneof {
+		literals = append(literals, strconv.Quote(v))
+	}
+
+	return literals
+}
+
+// reports whether the field is checked by the generated validator
+func (r *fieldRules) isEmpty() bool {
+	return !r.enum && !r.required && r.min == "" && r.max == "" && r.lenMin == "" && r.lenMax == "" && !r.email && len(r.oneof) == 0 && r.pattern == ""
+}
+
+// the json schema keywords of the validation rules
+func (r *fieldRules) schema(f *field) schemaDoc {
+	doc := make(schemaDoc, 0)
+
+	if r.min != "" {
+		doc = append(doc, schemaEntry{key: "minimum", value: schemaLiteral(r.min)})
+	}
+
+	if r.max != "" {
+		doc = append(doc, schemaEntry{key: "maximum", value: schemaLiteral(r.max)})
+	}
+
+	if r.lenMin != "" || r.lenMax != "" {
+		minKey, maxKey := "minItems", "maxItems"
+		switch t := f.typ.Underlying().(type) {
+		case *types.Basic:
+			minKey, maxKey = "minLength", "maxLength"
+		case *types.Map:
+			minKey, maxKey = "minProperties", "maxProperties"
+		case *types.Slice:
+			if b, ok := t.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+				minKey, maxKey = "", ""
+			}
+		}
+
+		if minKey != "" && r.lenMin != "" {
+			doc = append(doc, schemaEntry{key: minKey, value: schemaLiteral(r.lenMin)})
+		}
+
+		if maxKey != "" && r.lenMax != "" {
+			doc = append(doc, schemaEntry{key: maxKey, value: schemaLiteral(r.lenMax)})
+		}
+	}
+
+	if len(r.oneof) > 0 {
+		doc = append(doc, schemaEntry{key: "enum", value: schemaLiteral(fmt.Sprintf("bson.A{%s}", strings.Join(r.oneofLiterals(f), ", ")))})
+	}
+
+	if r.pattern != "" {
+		doc = append(doc, schemaEntry{key: "pattern", value: r.pattern})
+	}
+
+	return doc
+}
+
+// the conditions of the generated validator in the order of the rules
+func (r *fieldRules) checks(m *model, f *field) []fieldCheck {
+	checks := make([]fieldCheck, 0)
+
+	str := "value"
+	if f.typeName != "string" {
+		str = "string(value)"
+	}
+
+	if r.required {
+		checks = append(checks, fieldCheck{cond: zeroCond(f), rule: "required", message: "is required"})
+	}
+
+	if r.min != "" {
+		checks = append(checks, fieldCheck{cond: "value < " + r.min, rule: "min", message: "must be at least " + r.min})
+	}
+
+	if r.max != "" {
+		checks = append(checks, fieldCheck{cond: "value > " + r.max, rule: "max", message: "must be at most " + r.max})
+	}
+
+	if r.lenMin != "" || r.lenMax != "" {
+		length := "len(value)"
+		if isString(f.typ) {
+			length = "utf8.RuneCountInString(" + str + ")"
+		}
+
+		var cond, message string
+
+		switch {
+		case r.lenMin == r.lenMax:
+			cond = fmt.Sprintf("%s != %s", length, r.lenMin)
+			message = fmt.Sprintf("length must be %s", r.lenMin)
+		case r.lenMin == "":
+			cond = fmt.Sprintf("%s > %s", length, r.lenMax)
+			message = fmt.Sprintf("length must be at most %s", r.lenMax)
+		case r.lenMax == "":
+			cond = fmt.Sprintf("%s < %s", length, r.lenMin)
+			message = fmt.Sprintf("length must be at least %s", r.lenMin)
+		default:
+			cond = fmt.Sprintf("n := %s; n < %s || n > %s", length, r.lenMin, r.lenMax)
+			message = fmt.Sprintf("length must be between %s and %s", r.lenMin, r.lenMax)
+		}
+
+		checks = append(checks, fieldCheck{cond: cond, rule: "len", message: message})
+	}
+
+	if r.email {
+		checks = append(checks, fieldCheck{cond: fmt.Sprintf("value != \"\" && !%sIsEmail(%s)", m.commonPrefix, str), rule: "email", message: "must be a valid email address"})
+	}
+
+	if r.enum {
+		checks = append(checks, fieldCheck{cond: "!value.IsValid()", rule: "enum", message: "must be one of the values declared for " + f.enum.obj.Name()})
+	}
+
+	if len(r.oneof) > 0 {
+		conds := make([]string, 0, len(r.oneof))
+		for _, v := range r.oneofLiterals(f) {
+			conds = append(conds, "value != "+v)
+		}
+		checks = append(checks, fieldCheck{cond: strings.Join(conds, " && "), rule: "oneof", message: "must be one of " + strings.Join(r.oneof, ", ")})
+	}
+
+	if r.pattern != "" {
+		checks = append(checks, fieldCheck{cond: fmt.Sprintf("!%s.MatchString(%s)", m.patternName(f), str), rule: "pattern", message: "must match the pattern " + r.pattern})
+	}
+
+	return checks
+}
+
+// add the packages used by the generated validators
+func (m *model) setValidators() {
+	for _, f := range m.fields {
+		if (f.rules.lenMin != "" || f.rules.lenMax != "") && isString(f.typ) {
+			m.addImport(pkgUTF8)
+		}
+
+		if f.rules.pattern != "" {
+			m.addImport(pkgRegexp)
+		}
+	}
+}
+
+// the name of the generated validator of the field
+func (m *model) validatorName(f *field) string {
+	return fmt.Sprintf("validate%s%s", m.daoPrefixName, f.name)
+}
+
+// the name of the compiled pattern of the field
+func (m *model) patternName(f *field) string {
+	return fmt.Sprintf("%s%sPattern", m.daoVariableName, f.name)
+}
+
+// the parameter type of the generated validator, slices match the update operators of the element type
+func validatorParamType(f *field) string {
+	if f.kind == arrayField {
+		return "[]" + f.elemTypeName
+	}
+
+	return f.typeName
+}
+
+// render the validator functions of the fields
+func (m *model) modelValidators() (str string) {
+	for _, f := range m.fields {
+		if f.rules.isEmpty() {
+			continue
+		}
+
+		if f.rules.pattern != "" {
+			str += fmt.Sprintf("var %s = regexp.MustCompile(%s)\n\n", m.patternName(f), strconv.Quote(f.rules.pattern))
+		}
+
+		str += fmt.Sprintf("// %s checks the value of the %s column against the validation rules of the gen tag.\n", m.validatorName(f), f.column)
+		str += fmt.Sprintf("func %s(value %s) *%sFieldError {\n", m.validatorName(f), validatorParamType(f), m.commonPrefix)
+
+		for _, check := range f.rules.checks(m, f) {
+			str += fmt.Sprintf("\tif %s {\n", check.cond)
+			str += fmt.Sprintf("\t\treturn &%sFieldError{Field: %q, Rule: %q, Message: %q}\n", m.commonPrefix, f.column, check.rule, check.message)
+			str += "\t}\n\n"
+		}
+
+		str += "\treturn nil\n}\n\n"
+	}
+
+	str = strings.TrimSuffix(str, "\n\n")
+	return
+}
+
+// render the body of the Validate method of the dao
+func (m *model) modelValidateCode() string {
+	calls := make([]string, 0, len(m.fields))
+	for _, f := range m.fields {
+		if !f.rules.isEmpty() {
+			calls = append(calls, fmt.Sprintf("\t\t%s(model.%s),\n", m.validatorName(f), f.name))
+		}
+	}
+
+	if len(calls) == 0 {
+		return "return nil"
+	}
+
+	return fmt.Sprintf("return %sValidate(\n%s\t)", m.commonPrefix, strings.Join(calls, ""))
+}
+
+// the condition which reports whether the value of the field is the zero value
+func zeroCond(f *field) string {
+	if f.typeName == "interface{}" {
+		return "value == nil"
+	}
+
+	switch t := f.typ.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case t.Info()&types.IsString != 0:
+			return "value == \"\""
+		case t.Info()&types.IsBoolean != 0:
+			return "!value"
+		default:
+			return "value == 0"
+		}
+	case *types.Slice, *types.Map:
+		return "len(value) == 0"
+	case *types.Pointer, *types.Interface, *types.Chan, *types.Signature:
+		return "value == nil"
+	default:
+		return fmt.Sprintf("value == (%s{})", f.typeName)
+	}
+}
+
+// check that the value is a number literal assignable to the field
+func checkNumber(f *field, key, value string) error {
+	if f.kind != numberField {
+		return fmt.Errorf("error: %s rule is only supported by numeric field", key)
+	}
+
+	if b, ok := f.typ.Underlying().(*types.Basic); ok && b.Info()&types.IsInteger != 0 {
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("error: invalid %s rule value %s for integer field", key, value)
+		}
+
+		return nil
+	}
+
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		return fmt.Errorf("error: invalid %s rule value %s", key, value)
+	}
+
+	return nil
+}
+
+func isString(typ types.Type) bool {
+	b, ok := typ.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsString != 0
+}
+
+func hasLen(typ types.Type) bool {
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		return t.Info()&types.IsString != 0
+	case *types.Slice, *types.Array, *types.Map:
+		return true
+	default:
+		return false
+	}
+}
+
+func isNilable(typ types.Type) bool {
+	switch typ.Underlying().(type) {
+	case *types.Pointer, *types.Interface, *types.Chan, *types.Signature:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// mark the integer field as the version column for optimistic locking
+func (m *model) setVersionField(f *field) error {
+	if m.version != nil {
+		return fmt.Errorf("error: model %s has more than one version field", m.modelName)
+	}
+
+	if b, ok := f.typ.Underlying().(*types.Basic); !ok || b.Info()&types.IsInteger == 0 {
+		return fmt.Errorf("error: version field %s of model %s must be an integer", f.name, m.modelName)
+	}
+
+	m.version = f
+
+	return nil
+}
+
+// the code returning the prepared update document, which increments the version of the versioned model
+func (m *model) incVersionCode() string {
+	if m.version == nil {
+		return "return update, nil"
+	}
+
+	return fmt.Sprintf("return %sIncVersion(update, %q)", m.commonPrefix, m.version.column)
+}
+
+// the code replacing the document, the versioned model checks the version and increments it, which an upsert would bypass
+func (m *model) replaceCode() (str string) {
+	if m.version == nil {
+		return "return dao.replaceOne(ctx, filter, model, opts)"
+	}
+
+	name := m.version.name
+
+	str += "if opts != nil && opts.Upsert != nil && *opts.Upsert {\n"
+	str += fmt.Sprintf("\t\treturn nil, %sErrVersionUpsert\n", m.commonPrefix)
+	str += "\t}\n\n"
+	str += fmt.Sprintf("\tversion := model.%s\n", name)
+	str += fmt.Sprintf("\tfilter = %sAndFilter(filter, bson.D{{Key: %q, Value: version}})\n", m.commonPrefix, m.version.column)
+	str += fmt.Sprintf("\tmodel.%s++\n\n", name)
+	str += "\tresult, err := dao.replaceOne(ctx, filter, model, opts)\n"
+	str += "\tif err == nil && result.MatchedCount == 0 && result.UpsertedCount == 0 {\n"
+	str += fmt.Sprintf("\t\terr = %sErrVersionConflict\n", m.commonPrefix)
+	str += "\t}\n\n"
+	str += "\tif err != nil {\n"
+	str += fmt.Sprintf("\t\tmodel.%s = version\n", name)
+	str += "\t\treturn nil, err\n"
+	str += "\t}\n\n"
+	str += "\treturn result, nil"
+
+	return
+}
+
+// the version parameter of the updates, the versioned model updates only the documents holding the current version
+func (m *model) versionParam() string {
+	if m.version == nil {
+		return ""
+	}
+
+	return ", version " + m.version.typeName
+}
+
+// the version argument passed to the updates of the versioned model
+func (m *model) versionArg() string {
+	if m.version == nil {
+		return ""
+	}
+
+	return ", version"
+}
+
+// the code adding the version to the filter of the updates, which an upsert would bypass
+func (m *model) versionFilterCode() (str string) {
+	if m.version == nil {
+		return
+	}
+
+	str += "if opts != nil && opts.Upsert != nil && *opts.Upsert {\n"
+	str += fmt.Sprintf("\t\treturn nil, %sErrVersionUpsert\n", m.commonPrefix)
+	str += "\t}\n\n"
+	str += fmt.Sprintf("\tfilter = %sAndFilter(filter, bson.D{{Key: %q, Value: version}})\n\n\t", m.commonPrefix, m.version.column)
+
+	return
+}
+
+// the code reporting the conflict when no document holds the version
+func (m *model) versionConflictCode() (str string) {
+	if m.version == nil {
+		return
+	}
+
+	str += "if err == nil && result.MatchedCount == 0 {\n"
+	str += fmt.Sprintf("\t\t\treturn nil, %sErrVersionConflict\n", m.commonPrefix)
+	str += "\t\t}\n\n\t\t"
+
+	return
+}